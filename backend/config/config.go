@@ -53,10 +53,21 @@ type Config struct {
 	CookieDomain        string
 	Env                 string
 	BackendURL          string
+	AdminEmails         []string // Users allowed to call /api/admin routes, from ADMIN_EMAILS
 	Provider            *oidc.Provider
 	OAuth2Config        *oauth2.Config
 	Verifier            *oidc.IDTokenVerifier
-	DB                  *sql.DB // Database connection for mock mode
+	DB                  *sql.DB // Database connection; nil when PostgreSQL is unreachable
+}
+
+// IsAdmin reports whether email is one of ADMIN_EMAILS
+func (c *Config) IsAdmin(email string) bool {
+	for _, admin := range c.AdminEmails {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetAccessToken gets a client credentials access token for application permissions
@@ -144,26 +155,33 @@ func LoadConfig() *Config {
 		Scopes:       []string{"openid", "profile", "email", "offline_access", "User.Read", "User.ReadBasic.All", "Calendars.Read", "Calendars.ReadWrite"},
 	}
 
-	// Initialize database connection for mock mode
-	var db *sql.DB
-	graphMode := os.Getenv("GRAPH_MODE")
-	if graphMode == "mock" {
-		log.Println("Initializing database connection for mock mode...")
-		dbConfig := NewDBConfig()
-		var err error
-		db, err = dbConfig.ConnectDB()
-		if err != nil {
-			log.Printf("WARNING: Failed to connect to database for mock mode: %v", err)
+	// Initialize database connection
+	// Mock mode keeps calendars in it; both modes record meeting history there
+	log.Println("Initializing database connection...")
+	dbConfig := NewDBConfig()
+	db, err := dbConfig.ConnectDB()
+	if err != nil {
+		log.Printf("WARNING: Failed to connect to database: %v", err)
+		if os.Getenv("GRAPH_MODE") == "mock" {
 			log.Println("Mock mode will not work without database. Please start PostgreSQL.")
 		} else {
-			log.Println("Database connection established successfully for mock mode")
+			log.Println("Meeting history, RSVP tracking and other stored features are disabled.")
 		}
+	} else {
+		log.Println("Database connection established successfully")
 	}
 
 	cookieDomain := os.Getenv("COOKIE_DOMAIN")
 	env := os.Getenv("ENV")
 	backendURL := os.Getenv("BACKEND_URL")
 
+	var adminEmails []string
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			adminEmails = append(adminEmails, email)
+		}
+	}
+
 	return &Config{
 		ClientID:            clientID,
 		ClientSecret:        clientSecret,
@@ -179,6 +197,7 @@ func LoadConfig() *Config {
 		CookieDomain:        cookieDomain,
 		Env:                 env,
 		BackendURL:          backendURL,
+		AdminEmails:         adminEmails,
 		Provider:            provider,
 		OAuth2Config:        oauth2Config,
 		Verifier:            verifier,
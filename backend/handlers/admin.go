@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"Smart-Meeting-Scheduler/utils"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DirectorySyncStatus reports when the /users/delta directory sync last ran and what it did
+func DirectorySyncStatus(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c, cfg) {
+			return
+		}
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Directory sync is not available"})
+			return
+		}
+
+		store := models.NewUserStore(cfg.DB)
+		state, err := store.GetDirectorySyncState(c.Request.Context())
+		if err != nil {
+			log.Printf("Warning: Failed to read directory sync state: %v", err)
+		}
+		active, deleted, err := store.CountUsers(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to count users",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		response := gin.H{
+			"enabled":      os.Getenv("DIRECTORY_SYNC_INTERVAL") != "",
+			"interval":     os.Getenv("DIRECTORY_SYNC_INTERVAL"),
+			"activeUsers":  active,
+			"deletedUsers": deleted,
+			"lastChanged":  state.LastChanged,
+			"lastRemoved":  state.LastRemoved,
+			"hasDeltaLink": state.DeltaLink != "",
+		}
+		if !state.LastSynced.IsZero() {
+			response["lastSynced"] = state.LastSynced
+		}
+		if !state.LastFull.IsZero() {
+			response["lastFullSync"] = state.LastFull
+		}
+		if state.LastError != "" {
+			response["lastError"] = state.LastError
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// GraphRetryStatus reports how often Graph requests were retried since the server started
+// Retries of the Google Calendar and CalDAV providers are listed apart under "providers"
+func GraphRetryStatus(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c, cfg) {
+			return
+		}
+		policy := utils.GraphRetryPolicyFromEnv()
+		c.JSON(http.StatusOK, gin.H{
+			"maxRetries": policy.MaxRetries,
+			"baseDelay":  policy.BaseDelay.String(),
+			"maxWait":    policy.MaxWait.String(),
+			"metrics":    utils.GraphRetryMetrics(),
+			"providers": gin.H{
+				utils.RetryServiceGoogle: utils.RetryMetrics(utils.RetryServiceGoogle),
+				utils.RetryServiceCalDAV: utils.RetryMetrics(utils.RetryServiceCalDAV),
+			},
+		})
+	}
+}
+
+// requireAdmin checks that the signed-in user is listed in ADMIN_EMAILS
+// The user is identified through Graph /me, never from request parameters
+func requireAdmin(c *gin.Context, cfg *config.Config) bool {
+	email, err := fetchUserEmail(c.Request.Context(), c.GetString("access_token"), cfg)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Failed to identify user",
+			"details": err.Error(),
+		})
+		return false
+	}
+	if !cfg.IsAdmin(email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return false
+	}
+	return true
+}
+
+// ListCalendarProviders returns the deployment's calendar provider and the users bound to another one
+func ListCalendarProviders(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c, cfg) {
+			return
+		}
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar provider bindings are not available"})
+			return
+		}
+
+		bindings, err := models.NewCalendarProviderStore(cfg.DB).ListCalendarProviders(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to list calendar providers",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"default":  services.CalendarProviderFromEnv(),
+			"bindings": bindings,
+		})
+	}
+}
+
+// SetCalendarProvider binds a user's calendar to a provider (microsoft, google, caldav or local)
+func SetCalendarProvider(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c, cfg) {
+			return
+		}
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar provider bindings are not available"})
+			return
+		}
+
+		var req struct {
+			Provider string `json:"provider"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !services.IsCalendarProvider(req.Provider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "provider must be microsoft, google, caldav or local"})
+			return
+		}
+
+		userEmail := strings.ToLower(c.Param("email"))
+		if !strings.Contains(userEmail, "@") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "email must be an email address"})
+			return
+		}
+
+		if err := models.NewCalendarProviderStore(cfg.DB).SetCalendarProvider(c.Request.Context(), userEmail, req.Provider); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to set calendar provider",
+				"details": err.Error(),
+			})
+			return
+		}
+		services.InvalidateCalendarProviderBindings()
+		c.JSON(http.StatusOK, gin.H{
+			"userEmail": userEmail,
+			"provider":  req.Provider,
+		})
+	}
+}
+
+// DeleteCalendarProvider returns a user's calendar to the deployment's provider
+func DeleteCalendarProvider(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireAdmin(c, cfg) {
+			return
+		}
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar provider bindings are not available"})
+			return
+		}
+
+		deleted, err := models.NewCalendarProviderStore(cfg.DB).DeleteCalendarProvider(c.Request.Context(), c.Param("email"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to remove calendar provider",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User has no calendar provider binding"})
+			return
+		}
+		services.InvalidateCalendarProviderBindings()
+		c.JSON(http.StatusOK, gin.H{"message": "Calendar provider binding removed"})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/services"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits on a single meeting analytics request
+const (
+	defaultAnalyticsWeeks   = 4
+	maxAnalyticsRange       = 366 * 24 * time.Hour
+	maxAnalyticsUsers       = 50
+	defaultTopCollaborators = 10
+)
+
+// MeetingAnalytics reports meeting load for the current user or a comma-separated list of users
+// Other users must share more than free/busy times with the caller; free/busy is not enough to
+// analyze their meetings
+// Query: users, startTime and endTime (RFC3339, default the last four weeks), timezone and top
+func MeetingAnalytics(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+
+		endTime := time.Now()
+		if value := c.Query("endTime"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endTime format. Use RFC3339"})
+				return
+			}
+			endTime = parsed
+		}
+		startTime := endTime.AddDate(0, 0, -7*defaultAnalyticsWeeks)
+		if value := c.Query("startTime"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startTime format. Use RFC3339"})
+				return
+			}
+			startTime = parsed
+		}
+		if !endTime.After(startTime) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "endTime must be after startTime"})
+			return
+		}
+		if endTime.Sub(startTime) > maxAnalyticsRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Date range must not exceed 366 days"})
+			return
+		}
+
+		top := defaultTopCollaborators
+		if value := c.Query("top"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "top must be a non-negative number"})
+				return
+			}
+			top = parsed
+		}
+
+		timeZone := c.Query("timezone")
+		if !normalizeTimeZone(c, "timezone", &timeZone) {
+			return
+		}
+
+		viewer, ok := resolveUserEmail(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		users := analyticsUsers(c.Query("users"))
+		if len(users) == 0 {
+			users = []string{viewer}
+		}
+		if len(users) > maxAnalyticsUsers {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d users can be analyzed at once", maxAnalyticsUsers)})
+			return
+		}
+		if refused := unsharedCalendars(users, func(user string) bool {
+			return services.SharesCalendarWith(c.Request.Context(), cfg.DB, user, viewer)
+		}); len(refused) > 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "These users only share their free/busy times with you",
+				"details": strings.Join(refused, ", "),
+			})
+			return
+		}
+
+		client := services.WithPrivacy(getGraphClient(c.Request.Context(), accessToken, cfg), cfg.DB, viewer)
+		inputs := make([]services.MeetingAnalyticsInput, 0, len(users))
+		anyTruncated := false
+		for _, user := range users {
+			events, truncated, err := client.GetUserEvents(c.Request.Context(), user, startTime, endTime)
+			if err != nil {
+				log.Printf("Failed to fetch events of %s for analytics: %v", user, err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Failed to fetch calendar events",
+					"details": fmt.Sprintf("%s: %v", user, err),
+				})
+				return
+			}
+			if truncated {
+				log.Printf("Warning: Analytics for %s only cover the first %d events", user, len(events))
+				anyTruncated = true
+			}
+
+			loc := services.UserLocation(c.Request.Context(), cfg.DB, user)
+			if timeZone != "" {
+				loc, _ = services.LoadTimeZone(timeZone)
+			}
+
+			inputs = append(inputs, services.MeetingAnalyticsInput{
+				UserEmail: user,
+				Events:    events,
+				Location:  loc,
+			})
+		}
+
+		setTruncatedHeader(c, anyTruncated)
+		c.JSON(http.StatusOK, services.AnalyzeMeetings(inputs, startTime, endTime, top))
+	}
+}
+
+// unsharedCalendars returns the users whose calendars are not shared with the caller
+func unsharedCalendars(users []string, shared func(user string) bool) []string {
+	var refused []string
+	for _, user := range users {
+		if !shared(user) {
+			refused = append(refused, user)
+		}
+	}
+	return refused
+}
+
+// analyticsUsers splits a comma-separated list of emails, dropping blanks and duplicates
+func analyticsUsers(value string) []string {
+	var users []string
+	seen := map[string]bool{}
+	for _, user := range strings.Split(value, ",") {
+		user = strings.TrimSpace(user)
+		if user == "" || seen[strings.ToLower(user)] {
+			continue
+		}
+		seen[strings.ToLower(user)] = true
+		users = append(users, user)
+	}
+	return users
+}
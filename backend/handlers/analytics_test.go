@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUnsharedCalendars(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaultLevel string
+		users        string
+		want         string
+	}{
+		{"own calendar", "freeBusy", "alice@gruve.ai", ""},
+		{"own calendar in other case", "freeBusy", "ALICE@gruve.ai", ""},
+		{"colleagues sharing free/busy only", "freeBusy", "alice@gruve.ai, bob@gruve.ai,carol@gruve.ai", "bob@gruve.ai,carol@gruve.ai"},
+		{"colleagues sharing subjects", "limited", "bob@gruve.ai,carol@gruve.ai", ""},
+		{"colleagues sharing everything", "full", "bob@gruve.ai,bob@gruve.ai", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEFAULT_SHARING_LEVEL", tt.defaultLevel)
+
+			got := unsharedCalendars(analyticsUsers(tt.users), func(user string) bool {
+				return services.SharesCalendarWith(context.Background(), nil, user, "alice@gruve.ai")
+			})
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("unsharedCalendars(%q) = %v, want %q", tt.users, got, tt.want)
+			}
+		})
+	}
+}
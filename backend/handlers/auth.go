@@ -305,7 +305,7 @@ func AuthMe(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Fetch user info from Microsoft Graph
-		req, err := http.NewRequest("GET", cfg.GraphAPIBase+"/me?$select=id,displayName,mail,userPrincipalName", nil)
+		req, err := http.NewRequestWithContext(c.Request.Context(), "GET", cfg.GraphAPIBase+"/me?$select=id,displayName,mail,userPrincipalName", nil)
 		if err != nil {
 			log.Printf("AuthMe: Failed to create Graph request: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user info"})
@@ -315,7 +315,7 @@ func AuthMe(cfg *config.Config) gin.HandlerFunc {
 		req.Header.Add("Authorization", "Bearer "+session.AccessToken)
 		req.Header.Add("Accept", "application/json")
 
-		client := &http.Client{}
+		client := utils.NewGraphHTTPClient()
 		resp, err := client.Do(req)
 		if err != nil {
 			log.Printf("AuthMe: Failed to call Graph API: %v", err)
@@ -2,26 +2,19 @@ package handlers
 
 import (
 	"Smart-Meeting-Scheduler/config"
-	"Smart-Meeting-Scheduler/models"
 	"Smart-Meeting-Scheduler/services"
 	"Smart-Meeting-Scheduler/utils"
 	"context"
-	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
-	abstractions "github.com/microsoft/kiota-abstractions-go"
-	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
-	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
-	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
 	"github.com/gin-gonic/gin"
 )
 
-// CalendarEvents retrieves calendar events using Microsoft Graph SDK
-// Uses calendarView endpoint with startDateTime and endDateTime parameters
+// CalendarEvents retrieves a user's calendar events between startTime and endTime
+// Real mode reads Graph calendarView; other users' events are masked by their sharing level
 func CalendarEvents(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		accessToken := utils.GetAccessTokenFromContext(c)
@@ -47,39 +40,20 @@ func CalendarEvents(cfg *config.Config) gin.HandlerFunc {
 			endTime = startTime.Add(7 * 24 * time.Hour)
 		}
 
-		// Format times as RFC3339 strings for Graph API
-		startDateTime := startTime.Format(time.RFC3339)
-		endDateTime := endTime.Format(time.RFC3339)
-
-		// Check if we're in real mode or mock mode
-		graphMode := strings.ToLower(os.Getenv("GRAPH_MODE"))
-		if graphMode == "real" {
-			// Use Microsoft Graph SDK
-			events, err := fetchCalendarViewWithGraphSDK(accessToken, userEmail, startDateTime, endDateTime)
-			if err != nil {
-				log.Printf("Failed to fetch calendar events: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "Failed to fetch calendar events",
-					"details": err.Error(),
-				})
-				return
-			}
-
-			c.JSON(http.StatusOK, gin.H{
-				"events": events,
-				"count":  len(events),
-			})
-			return
+		// Every provider is read through the same client as the rest of the app, so the read is
+		// cached, retried when throttled, served from the synced event cache and masked for the caller
+		viewer := calendarViewer(c.Request.Context(), accessToken, cfg)
+		if userEmail == "" {
+			// Without ?email= the caller reads their own calendar
+			userEmail = viewer
 		}
-
-		// Mock mode - use existing GraphClient interface
-		client := getGraphClient(accessToken, cfg)
 		if userEmail == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required in mock mode"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "email parameter is required"})
 			return
 		}
+		client := services.WithPrivacy(getGraphClient(c.Request.Context(), accessToken, cfg), cfg.DB, viewer)
 
-		events, err := client.GetUserEvents(userEmail, startTime, endTime)
+		events, truncated, err := client.GetUserEvents(c.Request.Context(), userEmail, startTime, endTime)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Failed to fetch calendar events",
@@ -88,6 +62,7 @@ func CalendarEvents(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		setTruncatedHeader(c, truncated)
 		c.JSON(http.StatusOK, gin.H{
 			"events": events,
 			"count":  len(events),
@@ -95,128 +70,15 @@ func CalendarEvents(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
-// fetchCalendarViewWithGraphSDK fetches calendar events using Microsoft Graph SDK
-func fetchCalendarViewWithGraphSDK(accessToken, userEmail, startDateTime, endDateTime string) ([]models.Event, error) {
-	// Initialize Graph SDK client
-	authProvider := &services.TokenAuthProvider{AccessToken: accessToken}
-	adapter, err := msgraphsdk.NewGraphRequestAdapter(authProvider)
+// calendarViewer identifies the caller reading someone's calendar through Graph /me
+// If that fails they are treated as a stranger, so events stay masked
+func calendarViewer(ctx context.Context, accessToken string, cfg *config.Config) string {
+	viewer, err := fetchUserEmail(ctx, accessToken, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("error creating graph adapter: %v", err)
-	}
-	graphClient := msgraphsdk.NewGraphServiceClient(adapter)
-
-	// Set up headers with timezone preference
-	headers := abstractions.NewRequestHeaders()
-	headers.Add("Prefer", "outlook.timezone=\"UTC\"")
-
-	// Set up query parameters
-	requestParameters := &graphusers.ItemCalendarViewRequestBuilderGetQueryParameters{
-		StartDateTime: &startDateTime,
-		EndDateTime:   &endDateTime,
-		Select:        []string{"subject", "body", "bodyPreview", "organizer", "attendees", "start", "end", "location", "onlineMeeting"},
-	}
-
-	configuration := &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
-		Headers:       headers,
-		QueryParameters: requestParameters,
-	}
-
-	// Fetch calendar view
-	var calendarView []graphmodels.Eventable
-	if userEmail == "" {
-		// Use /me/calendarView for authenticated user
-		result, err := graphClient.Me().CalendarView().Get(context.Background(), configuration)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get calendar view: %v", err)
-		}
-		calendarView = result.GetValue()
-	} else {
-		// Use /users/{email}/calendarView for specific user
-		result, err := graphClient.Users().ByUserId(userEmail).CalendarView().Get(context.Background(), configuration)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get calendar view: %v", err)
-		}
-		calendarView = result.GetValue()
-	}
-
-	// Convert Graph API events to models.Event
-	var events []models.Event
-	for _, item := range calendarView {
-		event := convertGraphEventToModel(item)
-		events = append(events, event)
-	}
-
-	return events, nil
-}
-
-// convertGraphEventToModel converts a Graph API event to models.Event
-func convertGraphEventToModel(item graphmodels.Eventable) models.Event {
-	event := models.Event{}
-
-	if id := item.GetId(); id != nil {
-		event.ID = *id
-	}
-	if subject := item.GetSubject(); subject != nil {
-		event.Subject = *subject
-	}
-	if bodyPreview := item.GetBodyPreview(); bodyPreview != nil {
-		event.BodyPreview = *bodyPreview
-	}
-
-	// Parse start time
-	if start := item.GetStart(); start != nil {
-		if dateTime := start.GetDateTime(); dateTime != nil {
-			if t, err := time.Parse(time.RFC3339, *dateTime); err == nil {
-				event.Start = t
-			}
-		}
-	}
-
-	// Parse end time
-	if end := item.GetEnd(); end != nil {
-		if dateTime := end.GetDateTime(); dateTime != nil {
-			if t, err := time.Parse(time.RFC3339, *dateTime); err == nil {
-				event.End = t
-			}
-		}
-	}
-
-	// Get organizer
-	if organizer := item.GetOrganizer(); organizer != nil {
-		if emailAddr := organizer.GetEmailAddress(); emailAddr != nil {
-			if address := emailAddr.GetAddress(); address != nil {
-				event.Organizer = *address
-			}
-		}
-	}
-
-	// Get attendees
-	attendees := []string{}
-	for _, att := range item.GetAttendees() {
-		if emailAddr := att.GetEmailAddress(); emailAddr != nil {
-			if address := emailAddr.GetAddress(); address != nil {
-				attendees = append(attendees, *address)
-			}
-		}
-	}
-	event.Attendees = attendees
-
-	// Get location
-	if location := item.GetLocation(); location != nil {
-		if displayName := location.GetDisplayName(); displayName != nil {
-			event.Location = *displayName
-		}
-	}
-
-	// Get online meeting URL
-	if onlineMeeting := item.GetOnlineMeeting(); onlineMeeting != nil {
-		if joinUrl := onlineMeeting.GetJoinUrl(); joinUrl != nil {
-			event.OnlineURL = *joinUrl
-			event.IsOnline = true
-		}
+		log.Printf("Warning: Failed to identify calendar viewer, masking events: %v", err)
+		return ""
 	}
-
-	return event
+	return viewer
 }
 
 // CalendarAvailability checks availability for a user
@@ -228,7 +90,7 @@ func CalendarAvailability(cfg *config.Config) gin.HandlerFunc {
 			Email     string    `json:"email" binding:"required"`
 			StartTime time.Time `json:"startTime" binding:"required"`
 			EndTime   time.Time `json:"endTime" binding:"required"`
-			TimeZone  string    `json:"timeZone"` // Optional: IANA timezone (e.g., "Asia/Kolkata")
+			TimeZone  string    `json:"timeZone"` // Optional: IANA timezone (e.g., "Asia/Kolkata") or Windows ID
 		}
 
 		if err := c.BindJSON(&req); err != nil {
@@ -245,11 +107,15 @@ func CalendarAvailability(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		if !normalizeTimeZone(c, "timeZone", &req.TimeZone) {
+			return
+		}
+
 		// Get the appropriate client
-		client := getGraphClient(accessToken, cfg)
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
 
 		// Check availability with timezone
-		availability, err := client.GetAvailabilityWithTimezone(req.Email, req.StartTime, req.EndTime, req.TimeZone)
+		availability, err := client.GetAvailabilityWithTimezone(c.Request.Context(), req.Email, req.StartTime, req.EndTime, req.TimeZone)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Failed to check availability",
@@ -258,6 +124,7 @@ func CalendarAvailability(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		setTruncatedHeader(c, availability.Truncated)
 		c.JSON(http.StatusOK, availability)
 	}
 }
@@ -272,10 +139,10 @@ func GraphMe(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Make request to Microsoft Graph
-		req, _ := http.NewRequest("GET", cfg.GraphAPIBase+"/me", nil)
+		req, _ := http.NewRequestWithContext(c.Request.Context(), "GET", cfg.GraphAPIBase+"/me", nil)
 		req.Header.Add("Authorization", "Bearer "+accessToken)
 
-		client := &http.Client{}
+		client := utils.NewGraphHTTPClient()
 		resp, err := client.Do(req)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user profile"})
@@ -308,10 +175,10 @@ func GraphCalendar(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Make request to Microsoft Graph
-		req, _ := http.NewRequest("GET", cfg.GraphAPIBase+"/me/calendar", nil)
+		req, _ := http.NewRequestWithContext(c.Request.Context(), "GET", cfg.GraphAPIBase+"/me/calendar", nil)
 		req.Header.Add("Authorization", "Bearer "+accessToken)
 
-		client := &http.Client{}
+		client := utils.NewGraphHTTPClient()
 		resp, err := client.Do(req)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar"})
@@ -333,3 +200,88 @@ func GraphCalendar(cfg *config.Config) gin.HandlerFunc {
 		c.JSON(http.StatusOK, calendar)
 	}
 }
+
+// maxICSUploadSize limits the size of .ics files accepted by ImportCalendar
+const maxICSUploadSize = 10 << 20
+
+// ImportCalendar imports an .ics file into the local calendar store for the calling user (local calendars only)
+// The file is sent as the "file" field of a multipart form or as a text/calendar request body.
+// Recurring events are expanded between the startTime and endTime query parameters
+// (default: 30 days ago to one year ahead)
+func ImportCalendar(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+
+		userEmail, ok := resolveUserEmail(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		mockClient, ok := services.CalendarBackend(getGraphClient(c.Request.Context(), accessToken, cfg), userEmail).(*services.MockGraphClient)
+		if !ok || mockClient.DB == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Calendar import is only available for calendars kept locally"})
+			return
+		}
+
+		startTime, err := time.Parse(time.RFC3339, c.Query("startTime"))
+		if err != nil {
+			startTime = time.Now().AddDate(0, 0, -30)
+		}
+		endTime, err := time.Parse(time.RFC3339, c.Query("endTime"))
+		if err != nil {
+			endTime = time.Now().AddDate(1, 0, 0)
+		}
+		if !endTime.After(startTime) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "endTime must be after startTime"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxICSUploadSize)
+		body := c.Request.Body
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Missing .ics file",
+					"details": err.Error(),
+				})
+				return
+			}
+			file, err := fileHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Failed to read uploaded file",
+					"details": err.Error(),
+				})
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		events, warnings, err := services.ParseICSEvents(body, startTime, endTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid iCalendar file",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		result, err := mockClient.ImportEvents(c.Request.Context(), userEmail, events, startTime, endTime)
+		if err != nil {
+			log.Printf("Failed to import calendar for %s: %v", userEmail, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to import calendar",
+				"details": err.Error(),
+			})
+			return
+		}
+		result.Warnings = warnings
+
+		// Imported events show up only in the owner's calendar
+		services.InvalidateFreeBusy(c.Request.Context(), cfg, userEmail)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCalendarEventsReadsThroughGraphClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("GRAPH_MODE", "mock")
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "FROM mock_events e") {
+			return []string{"value"}, nil, nil
+		}
+		// Everyone has one private appointment they organize
+		owner := args[0].(string)
+		columns := []string{"id", "subject", "start_time", "end_time", "organizer", "location", "is_online",
+			"online_url", "body_preview", "sensitivity", "is_all_day", "show_as"}
+		return columns, [][]driver.Value{
+			{"evt-" + owner, "Dentist", start, start.Add(time.Hour), owner, nil, false, nil, nil, "private", false, "busy"},
+		}, nil
+	})
+	cfg := &config.Config{DB: db, GraphAPIBase: newMeServer(t, map[string]string{"alice-token": "alice@gruve.ai"}).URL}
+
+	router := gin.New()
+	router.Use(withAccessToken)
+	router.GET("/api/calendar/events", CalendarEvents(cfg))
+
+	tests := []struct {
+		name        string
+		target      string
+		token       string
+		status      int
+		wantSubject string
+	}{
+		{"own calendar without email", "/api/calendar/events?startTime=2026-03-02T00:00:00Z", "alice-token", http.StatusOK, "Dentist"},
+		{"own calendar by email", "/api/calendar/events?startTime=2026-03-02T00:00:00Z&email=alice@gruve.ai", "alice-token", http.StatusOK, "Dentist"},
+		{"someone else's calendar is masked", "/api/calendar/events?startTime=2026-03-02T00:00:00Z&email=bob@gruve.ai", "alice-token", http.StatusOK, "Private appointment"},
+		{"unknown caller is masked", "/api/calendar/events?startTime=2026-03-02T00:00:00Z&email=alice@gruve.ai", "other", http.StatusOK, "Private appointment"},
+		{"unknown caller without email", "/api/calendar/events?startTime=2026-03-02T00:00:00Z", "other", http.StatusBadRequest, ""},
+		{"no token", "/api/calendar/events?startTime=2026-03-02T00:00:00Z&email=alice@gruve.ai", "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var body struct {
+				Events []models.Event `json:"events"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Events) != 1 || body.Events[0].Subject != tt.wantSubject {
+				t.Errorf("events = %+v, want one %q", body.Events, tt.wantSubject)
+			}
+		})
+	}
+}
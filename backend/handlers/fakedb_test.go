@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeQuery answers one statement: the columns and rows of a query, or for an exec one row
+// per affected row
+type fakeQuery func(query string, args []driver.Value) (columns []string, rows [][]driver.Value, err error)
+
+// newFakeDB returns a *sql.DB whose statements are answered by answer, so handlers backed by
+// the models stores can be tested without PostgreSQL
+func newFakeDB(t *testing.T, answer fakeQuery) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{answer: answer})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeConnector struct {
+	answer fakeQuery
+}
+
+func (f fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{answer: f.answer}, nil
+}
+
+func (f fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver: open with sql.OpenDB")
+}
+
+type fakeConn struct {
+	mu     sync.Mutex
+	answer fakeQuery
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	columns, rows, err := c.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	_, rows, err := c.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+
+func (c *fakeConn) run(query string, named []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answer(query, args)
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
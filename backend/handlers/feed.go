@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Calendar feeds cover a rolling window around the time they are fetched
+const (
+	feedWindowPast   = 30 * 24 * time.Hour
+	feedWindowFuture = 180 * 24 * time.Hour
+)
+
+// CreateCalendarFeed issues a subscribable .ics feed URL for the calling user's calendar
+func CreateCalendarFeed(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar feeds are not available"})
+			return
+		}
+
+		var req models.CreateFeedRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid request body",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		feed, err := models.NewFeedStore(cfg.DB).CreateFeed(c.Request.Context(), userEmail, req.FreeBusyOnly)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to create calendar feed",
+				"details": err.Error(),
+			})
+			return
+		}
+		feed.URL = feedURL(cfg, feed.Token)
+
+		c.JSON(http.StatusCreated, feed)
+	}
+}
+
+// ListCalendarFeeds returns the calling user's calendar feeds
+func ListCalendarFeeds(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar feeds are not available"})
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		feeds, err := models.NewFeedStore(cfg.DB).ListFeeds(c.Request.Context(), userEmail)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch calendar feeds",
+				"details": err.Error(),
+			})
+			return
+		}
+		for i := range feeds {
+			feeds[i].URL = feedURL(cfg, feeds[i].Token)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"feeds": feeds})
+	}
+}
+
+// RevokeCalendarFeed deletes one of the calling user's calendar feeds
+func RevokeCalendarFeed(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar feeds are not available"})
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		err := models.NewFeedStore(cfg.DB).RevokeFeed(c.Request.Context(), userEmail, c.Param("token"))
+		if errors.Is(err, models.ErrFeedNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to revoke calendar feed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked"})
+	}
+}
+
+// CalendarFeed serves a user's events as an .ics feed (token-authenticated)
+// Calendar apps poll this URL without a user session, so real mode reads events with the application token.
+// The feed token is the owner's credential, so events are not masked; a free/busy feed reduces them to busy blocks
+func CalendarFeed(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.String(http.StatusServiceUnavailable, "Calendar feeds are not available")
+			return
+		}
+
+		feed, err := models.NewFeedStore(cfg.DB).AccessFeed(c.Request.Context(), c.Param("token"))
+		if errors.Is(err, models.ErrFeedNotFound) {
+			c.String(http.StatusNotFound, "Calendar feed not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to load calendar feed: %v", err)
+			c.String(http.StatusInternalServerError, "Failed to load calendar feed")
+			return
+		}
+
+		client, err := feedGraphClient(c.Request.Context(), cfg)
+		if err != nil {
+			log.Printf("Failed to create Graph client for calendar feed: %v", err)
+			c.String(http.StatusInternalServerError, "Failed to load calendar feed")
+			return
+		}
+
+		now := time.Now()
+		events, truncated, err := client.GetUserEvents(c.Request.Context(), feed.UserEmail, now.Add(-feedWindowPast), now.Add(feedWindowFuture))
+		if err != nil {
+			log.Printf("Failed to fetch events for calendar feed of %s: %v", feed.UserEmail, err)
+			c.String(http.StatusBadGateway, "Failed to fetch calendar events")
+			return
+		}
+		if truncated {
+			log.Printf("Warning: Calendar feed of %s truncated at %d events", feed.UserEmail, len(events))
+		}
+		setTruncatedHeader(c, truncated)
+
+		name := fmt.Sprintf("Meetings (%s)", feed.UserEmail)
+		if feed.FreeBusyOnly {
+			name = fmt.Sprintf("Free/busy (%s)", feed.UserEmail)
+		}
+
+		c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+		c.Header("Cache-Control", "private, max-age=300")
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(services.GenerateCalendarFeed(name, events, feed.FreeBusyOnly, services.UserLocation(c.Request.Context(), cfg.DB, feed.UserEmail))))
+	}
+}
+
+// feedGraphClient returns a GraphClient that works without a user session
+// Users on Microsoft Graph are read with the application token
+func feedGraphClient(ctx context.Context, cfg *config.Config) (services.GraphClient, error) {
+	if os.Getenv("GRAPH_MODE") != "real" {
+		return getGraphClient(ctx, "", cfg), nil
+	}
+
+	appToken, err := cfg.GetAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	return getGraphClient(ctx, appToken, cfg), nil
+}
+
+// feedURL builds the public URL of a calendar feed
+func feedURL(cfg *config.Config, token string) string {
+	return strings.TrimRight(cfg.BackendURL, "/") + "/feeds/" + token + "/calendar.ics"
+}
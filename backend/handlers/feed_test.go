@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedConfig returns a mock mode config whose database holds one feed of alice's
+// and two of her events, a private one and one she attends
+func feedConfig(t *testing.T, freeBusyOnly bool) *config.Config {
+	t.Helper()
+	start := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day()+1, 9, 0, 0, 0, time.UTC)
+	db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "calendar_feeds"):
+			return []string{"token", "user_email", "free_busy_only", "created_at", "last_accessed_at"},
+				[][]driver.Value{{"feed-token", "alice@gruve.ai", freeBusyOnly, start, start}}, nil
+		case strings.Contains(query, "FROM mock_events e"):
+			columns := []string{"id", "subject", "start_time", "end_time", "organizer", "location", "is_online",
+				"online_url", "body_preview", "sensitivity", "is_all_day", "show_as"}
+			return columns, [][]driver.Value{
+				{"evt-1", "Dentist", start, start.Add(time.Hour), "alice@gruve.ai", nil, false, nil, nil, "private", false, "busy"},
+				{"evt-2", "Design review", start.Add(2 * time.Hour), start.Add(3 * time.Hour), "bob@gruve.ai", "Room 1", false, nil, nil, "normal", false, "busy"},
+			}, nil
+		}
+		return []string{"value"}, nil, nil
+	})
+	return &config.Config{DB: db}
+}
+
+func TestCalendarFeedShowsOwnerEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("GRAPH_MODE", "mock")
+
+	tests := []struct {
+		name         string
+		freeBusyOnly bool
+		want         []string
+		notWant      []string
+	}{
+		{
+			name:    "full feed keeps private events in full",
+			want:    []string{"SUMMARY:Dentist", "CLASS:PRIVATE", "SUMMARY:Design review", "LOCATION:Room 1"},
+			notWant: []string{"Private appointment", "SUMMARY:Busy"},
+		},
+		{
+			name:         "free/busy feed hides every detail",
+			freeBusyOnly: true,
+			want:         []string{"SUMMARY:Busy"},
+			notWant:      []string{"Dentist", "Design review", "Room 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/feeds/:token/calendar.ics", CalendarFeed(feedConfig(t, tt.freeBusyOnly)))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/feed-token/calendar.ics", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d %s, want 200", w.Code, w.Body.String())
+			}
+
+			body := w.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("feed is missing %q:\n%s", want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("feed contains %q:\n%s", notWant, body)
+				}
+			}
+
+			// Every event keeps its own UID so calendar clients do not merge them
+			uids := map[string]bool{}
+			for _, line := range strings.Split(body, "\r\n") {
+				if strings.HasPrefix(line, "UID:") {
+					uids[line] = true
+				}
+			}
+			if len(uids) != 2 || uids["UID:@gruve.ai"] {
+				t.Errorf("UIDs = %v, want two distinct event UIDs", uids)
+			}
+		})
+	}
+}
@@ -4,13 +4,18 @@ import (
 	"Smart-Meeting-Scheduler/config"
 	"Smart-Meeting-Scheduler/models"
 	"Smart-Meeting-Scheduler/services"
+	"Smart-Meeting-Scheduler/utils"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,10 +23,26 @@ import (
 )
 
 // getGraphClient returns the appropriate GraphClient based on environment
-func getGraphClient(accessToken string, cfg *config.Config) services.GraphClient {
-	mode := os.Getenv("GRAPH_MODE")
-	if mode == "real" && accessToken != "" {
-		return services.NewGraphAPIClient(accessToken, cfg)
+// Users bound to another calendar provider are routed to it by a FederatedGraphClient
+func getGraphClient(ctx context.Context, accessToken string, cfg *config.Config) services.GraphClient {
+	return services.NewFederatedGraphClient(ctx, cfg.DB, services.CalendarProviderFromEnv(), func(provider string) services.GraphClient {
+		return calendarBackend(provider, accessToken, cfg)
+	})
+}
+
+// calendarBackend returns the GraphClient of one calendar provider
+func calendarBackend(provider, accessToken string, cfg *config.Config) services.GraphClient {
+	switch provider {
+	// Google and CalDAV read with the deployment's own credentials; Graph with the caller's token
+	case services.CalendarProviderGoogle:
+		return services.WithFreeBusyCache(services.NewGoogleCalendarClientFromEnv(cfg), cfg, "")
+	case services.CalendarProviderCalDAV:
+		return services.WithFreeBusyCache(services.NewCalDAVClientFromEnv(cfg), cfg, "")
+	case services.CalendarProviderMicrosoft:
+		mode := os.Getenv("GRAPH_MODE")
+		if mode == "real" && accessToken != "" {
+			return services.WithFreeBusyCache(services.NewGraphAPIClient(accessToken, cfg), cfg, services.TokenScope(accessToken))
+		}
 	}
 
 	// Use mock client with database from config
@@ -35,15 +56,15 @@ func getGraphClient(accessToken string, cfg *config.Config) services.GraphClient
 }
 
 // fetchUserEmail fetches the authenticated user's email from Graph API
-func fetchUserEmail(accessToken string, cfg *config.Config) (string, error) {
+func fetchUserEmail(ctx context.Context, accessToken string, cfg *config.Config) (string, error) {
 	// Make request to Microsoft Graph /me endpoint
-	req, err := http.NewRequest("GET", cfg.GraphAPIBase+"/me", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", cfg.GraphAPIBase+"/me", nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Add("Authorization", "Bearer "+accessToken)
 
-	client := &http.Client{}
+	client := utils.NewGraphHTTPClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch user profile: %w", err)
@@ -77,7 +98,7 @@ func fetchUserEmail(accessToken string, cfg *config.Config) (string, error) {
 // resolveToEmailAddress resolves a display name or email to an actual email address
 // If the input is already an email (contains @), returns it as-is
 // Otherwise, searches for users matching the display name and returns the first match's email
-func resolveToEmailAddress(input string, userService services.UserService) string {
+func resolveToEmailAddress(ctx context.Context, input string, userService services.UserService) string {
 	// If it's already an email address, return it
 	if strings.Contains(input, "@") {
 		return input
@@ -89,7 +110,7 @@ func resolveToEmailAddress(input string, userService services.UserService) strin
 	}
 
 	// Search for users matching the display name
-	users, err := userService.SearchUsers(input)
+	users, _, err := userService.SearchUsers(ctx, input)
 	if err != nil || len(users) == 0 {
 		return ""
 	}
@@ -121,25 +142,39 @@ func CreateMeeting(cfg *config.Config) gin.HandlerFunc {
 			})
 			return
 		}
+		if req.Sensitivity != "" && !req.Sensitivity.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sensitivity must be normal, private or confidential"})
+			return
+		}
 
 		// Get organizer email from context or request
-		organizer := c.Query("organizer")
-		if organizer == "" {
-			// Fetch from Graph API /me endpoint
-			var err error
-			organizer, err = fetchUserEmail(accessToken, cfg)
-			if err != nil {
-				log.Printf("Failed to fetch user email: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "Failed to get organizer email",
-					"details": err.Error(),
-				})
+		organizer, ok := resolveOrganizer(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		if req.TemplateID != "" {
+			template, ok := loadMeetingTemplate(c, cfg, organizer, req.TemplateID)
+			if !ok {
 				return
 			}
+			template.ApplyToCreate(&req)
+		}
+		if req.Subject == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "subject is required"})
+			return
+		}
+		if len(req.Attendees) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "attendees is required"})
+			return
+		}
+		if !req.End.After(req.Start) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+			return
 		}
 
 		// Get the appropriate client
-		client := getGraphClient(accessToken, cfg)
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
 
 		var event models.Event
 		var err error
@@ -147,6 +182,7 @@ func CreateMeeting(cfg *config.Config) gin.HandlerFunc {
 		if req.IsOnline {
 			// Create online meeting (Teams)
 			event, err = client.CreateOnlineMeeting(
+				c.Request.Context(),
 				organizer,
 				req.Start,
 				req.End,
@@ -155,7 +191,7 @@ func CreateMeeting(cfg *config.Config) gin.HandlerFunc {
 			)
 		} else {
 			// Create regular calendar event
-			event, err = client.CreateCalendarEvent(organizer, req)
+			event, err = client.CreateCalendarEvent(c.Request.Context(), organizer, req)
 		}
 
 		if err != nil {
@@ -166,52 +202,292 @@ func CreateMeeting(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Record the meeting in the history so attendee responses can be tracked
+		rsvpLinks := recordMeeting(c.Request.Context(), cfg, event, req.Description, calendarProvider(client, organizer), req.Suggestion)
+
 		// Send meeting invitations to attendees asynchronously
-		// In real mode, Graph API automatically sends invites when creating events
-		// In mock mode or when using Outlook sender, send custom invites
-		mode := os.Getenv("GRAPH_MODE")
-		mailMode := os.Getenv("MAIL_MODE")
-
-		if mode != "real" || mailMode == "outlook" {
-			// Using mock mode or Outlook sender, send custom invites
-			go func() {
-				var sender services.Sender
-
-				// Use token-aware factory for Outlook mode
-				if mailMode == "outlook" && accessToken != "" {
-					sender = services.GetInviteSenderWithToken(accessToken, organizer)
-				} else {
-					sender = services.GetInviteSender()
-				}
+		sendInviteAsync(c.Request.Context(), client, accessToken, organizer, &services.MeetingInvite{
+			Subject:     req.Subject,
+			Description: req.Description,
+			StartTime:   req.Start.Format(time.RFC3339),
+			EndTime:     req.End.Format(time.RFC3339),
+			Attendees:   req.Attendees,
+			Organizer:   organizer,
+			Location:    req.Location,
+			UID:         services.InviteUID(event.ID),
+			RSVPLinks:   rsvpLinks,
+		}, deliverInvite)
 
-				invite := &services.MeetingInvite{
-					Subject:     req.Subject,
-					Description: req.Description,
-					StartTime:   req.Start.Format(time.RFC3339),
-					EndTime:     req.End.Format(time.RFC3339),
-					Attendees:   req.Attendees,
-					Organizer:   organizer,
-					Location:    req.Location,
-				}
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Meeting created successfully",
+			"event":   event,
+		})
+	}
+}
 
-				if err := sender.SendInvite(invite); err != nil {
-					log.Printf("Failed to send meeting invite: %v", err)
-					// Don't fail the request - meeting was created successfully
-				} else {
-					log.Printf("Successfully sent meeting invites to %d attendees", len(req.Attendees))
-				}
-			}()
-		} else {
-			log.Printf("Using real Graph API - invites are sent automatically by Microsoft Graph")
+// UpdateMeeting applies a partial update to a meeting and notifies attendees
+func UpdateMeeting(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+		eventID := c.Param("id")
+
+		var req models.UpdateMeetingRequest
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
 		}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"message": "Meeting created successfully",
+		if req.Start != nil && req.End != nil && !req.End.After(*req.Start) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+			return
+		}
+
+		// Only the signed-in organizer may change their meeting
+		organizer, ok := resolveUserEmail(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
+
+		// Attendees the update drops are sent a cancellation, so note who was invited before
+		var previous []string
+		if req.Attendees != nil {
+			previous = meetingAttendees(c.Request.Context(), client, organizer, eventID)
+		}
+
+		event, err := client.UpdateCalendarEvent(c.Request.Context(), organizer, eventID, req)
+		if err != nil {
+			respondMeetingError(c, "Failed to update meeting", err)
+			return
+		}
+
+		sendInviteAsync(c.Request.Context(), client, accessToken, organizer, &services.MeetingInvite{
+			Subject:     event.Subject,
+			Description: event.BodyPreview,
+			StartTime:   event.Start.Format(time.RFC3339),
+			EndTime:     event.End.Format(time.RFC3339),
+			Attendees:   event.Attendees,
+			Organizer:   organizer,
+			Location:    event.Location,
+			UID:         services.InviteUID(event.ID),
+			Sequence:    event.Sequence,
+			RSVPLinks:   syncMeetingRecord(c.Request.Context(), cfg, event),
+		}, deliverUpdate)
+
+		if removed := removedAttendees(previous, event.Attendees); len(removed) > 0 {
+			sendInviteAsync(c.Request.Context(), client, accessToken, organizer, &services.MeetingInvite{
+				Subject:   event.Subject,
+				StartTime: event.Start.Format(time.RFC3339),
+				EndTime:   event.End.Format(time.RFC3339),
+				Attendees: removed,
+				Organizer: organizer,
+				Location:  event.Location,
+				UID:       services.InviteUID(event.ID),
+				Sequence:  event.Sequence,
+			}, deliverCancellation)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Meeting updated successfully",
 			"event":   event,
 		})
 	}
 }
 
+// CancelMeeting cancels a meeting and sends cancellation notices to attendees
+func CancelMeeting(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+		eventID := c.Param("id")
+
+		// The body is optional; it only carries a cancellation comment
+		var req models.CancelMeetingRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid request body",
+					"details": err.Error(),
+				})
+				return
+			}
+		}
+
+		// Only the signed-in organizer may cancel their meeting
+		organizer, ok := resolveUserEmail(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
+		event, err := client.CancelCalendarEvent(c.Request.Context(), organizer, eventID, req.Comment)
+		if err != nil {
+			respondMeetingError(c, "Failed to cancel meeting", err)
+			return
+		}
+		cancelMeetingRecord(c.Request.Context(), cfg, eventID)
+
+		sendInviteAsync(c.Request.Context(), client, accessToken, organizer, &services.MeetingInvite{
+			Subject:     event.Subject,
+			Description: req.Comment,
+			StartTime:   event.Start.Format(time.RFC3339),
+			EndTime:     event.End.Format(time.RFC3339),
+			Attendees:   event.Attendees,
+			Organizer:   organizer,
+			Location:    event.Location,
+			UID:         services.InviteUID(event.ID),
+			Sequence:    event.Sequence,
+		}, deliverCancellation)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Meeting cancelled successfully",
+			"eventId": eventID,
+		})
+	}
+}
+
+// meetingAttendees returns the attendees of the organizer's meeting eventID, or nil when they
+// cannot be read
+func meetingAttendees(ctx context.Context, client services.GraphClient, organizer, eventID string) []string {
+	responses, err := client.GetEventResponses(ctx, organizer, eventID)
+	if err != nil {
+		log.Printf("Warning: Failed to read attendees of meeting %s: %v", eventID, err)
+		return nil
+	}
+	attendees := make([]string, 0, len(responses))
+	for _, response := range responses {
+		attendees = append(attendees, response.Email)
+	}
+	return attendees
+}
+
+// removedAttendees returns the attendees of previous that are not in current
+func removedAttendees(previous, current []string) []string {
+	kept := make(map[string]bool, len(current))
+	for _, email := range current {
+		kept[strings.ToLower(email)] = true
+	}
+	var removed []string
+	for _, email := range previous {
+		if !kept[strings.ToLower(email)] {
+			removed = append(removed, email)
+		}
+	}
+	return removed
+}
+
+// resolveOrganizer returns the organizer of a meeting being proposed or created: the signed-in
+// user from the Graph /me profile. The meeting history, proposals, notes and iMIP replies trust
+// this organizer, so ?organizer= may only repeat it. On failure it writes the error response
+// and returns false
+func resolveOrganizer(c *gin.Context, accessToken string, cfg *config.Config) (string, bool) {
+	organizer, ok := resolveUserEmail(c, accessToken, cfg)
+	if !ok {
+		return "", false
+	}
+	if requested := c.Query("organizer"); requested != "" && !strings.EqualFold(requested, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organizer must be the signed-in user"})
+		return "", false
+	}
+	return organizer, true
+}
+
+// resolveUserEmail returns the calling user's email from the Graph /me profile of their token
+// The caller's identity never comes from the request itself. Writes a 401 response and returns
+// false when the token cannot be resolved
+func resolveUserEmail(c *gin.Context, accessToken string, cfg *config.Config) (string, bool) {
+	email, err := fetchUserEmail(c.Request.Context(), accessToken, cfg)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Failed to identify user",
+			"details": err.Error(),
+		})
+		return "", false
+	}
+	return email, true
+}
+
+// respondMeetingError maps GraphClient errors for an existing meeting to HTTP responses
+func respondMeetingError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrEventNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrNotOrganizer):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidEvent):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrGraphBusy):
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
+
+// inviteSendTimeout bounds the background delivery of one meeting notice
+const inviteSendTimeout = 2 * time.Minute
+
+// sendInviteAsync delivers a meeting notice to attendees in the background using deliver
+// Graph and Google send their own notices when the organizer's events change, so custom senders
+// are only used for the local store and CalDAV or when MAIL_MODE=outlook
+// Delivery outlives the request that triggered it, so it runs detached from ctx's cancellation
+func sendInviteAsync(ctx context.Context, client services.GraphClient, accessToken, organizer string, invite *services.MeetingInvite, deliver func(context.Context, services.Sender, *services.MeetingInvite) error) {
+	mailMode := os.Getenv("MAIL_MODE")
+
+	switch calendarProvider(client, organizer) {
+	case "google":
+		log.Printf("Using Google Calendar - invites are sent automatically by Google")
+		return
+	case "graph":
+		if mailMode != "outlook" {
+			log.Printf("Using real Graph API - invites are sent automatically by Microsoft Graph")
+			return
+		}
+	}
+
+	if len(invite.Attendees) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), inviteSendTimeout)
+	go func() {
+		defer cancel()
+		var sender services.Sender
+
+		// Use token-aware factory for Outlook mode
+		if mailMode == "outlook" && accessToken != "" {
+			sender = services.GetInviteSenderWithToken(accessToken, organizer)
+		} else {
+			sender = services.GetInviteSender()
+		}
+
+		if err := deliver(ctx, sender, invite); err != nil {
+			log.Printf("Failed to send meeting notice: %v", err)
+			// Don't fail the request - the calendar change already succeeded
+		} else {
+			log.Printf("Successfully sent meeting notices to %d attendees", len(invite.Attendees))
+		}
+	}()
+}
+
+// deliverInvite, deliverUpdate and deliverCancellation send one kind of meeting notice for sendInviteAsync
+func deliverInvite(ctx context.Context, sender services.Sender, invite *services.MeetingInvite) error {
+	return sender.SendInvite(ctx, invite)
+}
+
+func deliverUpdate(ctx context.Context, sender services.Sender, invite *services.MeetingInvite) error {
+	return sender.SendUpdate(ctx, invite)
+}
+
+func deliverCancellation(ctx context.Context, sender services.Sender, invite *services.MeetingInvite) error {
+	return sender.SendCancellation(ctx, invite)
+}
+
 // FindMeetingTimes finds available meeting times for attendees using external AI API
 func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -226,22 +502,49 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Get organizer email
-		organizer := c.Query("organizer")
-		if organizer == "" {
-			// Fetch from Graph API /me endpoint
-			var err error
-			organizer, err = fetchUserEmail(accessToken, cfg)
-			if err != nil {
-				log.Printf("Failed to fetch user email: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error":   "Failed to get organizer email",
-					"details": err.Error(),
-				})
+		if !normalizeTimeZone(c, "TimeZone", &req.TimeZone) {
+			return
+		}
+		for i := range req.Attendees {
+			if !normalizeTimeZone(c, fmt.Sprintf("Attendees[%d].timezone", i), &req.Attendees[i].TimeZone) {
+				return
+			}
+		}
+		for i := range req.PriorityAttendees {
+			if !normalizeTimeZone(c, fmt.Sprintf("PriorityAttendees[%d].timezone", i), &req.PriorityAttendees[i].TimeZone) {
 				return
 			}
 		}
 
+		// The unauthenticated test route names the organizer in ?organizer=. That is not the
+		// caller's identity, so there it neither unlocks templates nor unmasks calendars
+		organizer, viewer := c.Query("organizer"), ""
+		if accessToken != "" || organizer == "" {
+			var ok bool
+			if organizer, ok = resolveOrganizer(c, accessToken, cfg); !ok {
+				return
+			}
+			viewer = organizer
+		}
+
+		var template *models.MeetingTemplate
+		if req.TemplateID != "" {
+			if viewer == "" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in to use meeting templates"})
+				return
+			}
+			var ok bool
+			template, ok = loadMeetingTemplate(c, cfg, viewer, req.TemplateID)
+			if !ok {
+				return
+			}
+			template.ApplyToFind(&req)
+		}
+		if len(req.Attendees) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Attendees is required"})
+			return
+		}
+
 		// Validate duration
 		if req.Duration <= 0 {
 			req.Duration = 30 // Default to 30 minutes
@@ -252,8 +555,8 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 			req.MaxSuggestions = 5
 		}
 
-		// Get the appropriate client
-		client := getGraphClient(accessToken, cfg)
+		// Get the appropriate client; calendars read as a fallback are masked like any other view
+		client := services.WithPrivacy(getGraphClient(c.Request.Context(), accessToken, cfg), cfg.DB, viewer)
 
 		// Resolve attendee display names to email addresses
 		// The Email field might contain display names instead of actual emails
@@ -263,46 +566,66 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		attendeeEmails := make([]string, 0, len(req.Attendees))
+		// Names the attendees were given by, for warnings about their calendars
+		names := map[string]string{}
+		var warnings []models.CalendarWarning
 		for _, attendee := range req.Attendees {
-			email := resolveToEmailAddress(attendee.Email, userService)
+			email := resolveToEmailAddress(c.Request.Context(), attendee.Email, userService)
 			if email != "" {
 				attendeeEmails = append(attendeeEmails, email)
+				if attendee.Email != email {
+					names[email] = attendee.Email
+				}
 			} else {
 				log.Printf("Warning: Could not resolve email for attendee: %s", attendee.Email)
+				warnings = append(warnings, models.CalendarWarning{
+					Participant: attendee.Email,
+					Message:     fmt.Sprintf("Couldn't find %s in the directory", attendee.Email),
+				})
 			}
 		}
 
 		// Extract priority attendee emails
 		priorityAttendeeEmails := make([]string, 0, len(req.PriorityAttendees))
 		for _, attendee := range req.PriorityAttendees {
-			email := resolveToEmailAddress(attendee.Email, userService)
+			email := resolveToEmailAddress(c.Request.Context(), attendee.Email, userService)
 			if email != "" {
 				priorityAttendeeEmails = append(priorityAttendeeEmails, email)
 			}
 		}
 
-		// Fetch calendar events for all participants (including organizer)
+		// Fetch free/busy time for all participants (including organizer)
 		allParticipants := append([]string{organizer}, attendeeEmails...)
-		participantCalendars := make(map[string][]models.Event)
-
+		// Only free/busy intervals leave the service; subjects and attendee lists stay private
+		busySlots, failures, truncated := fetchBusySlots(c.Request.Context(), client, allParticipants, req.StartTime, req.EndTime)
+		if err := c.Request.Context().Err(); err != nil {
+			log.Printf("findTimes request abandoned by the client: %v", err)
+			return
+		}
 		for _, participant := range allParticipants {
-			// Use email address to fetch calendar events via Graph API
-			events, err := client.GetUserEvents(participant, req.StartTime, req.EndTime)
-			if err != nil {
-				log.Printf("Warning: Failed to fetch calendar for %s: %v", participant, err)
-				// Continue with empty calendar for this participant
-				participantCalendars[participant] = []models.Event{}
-			} else {
-				participantCalendars[participant] = events
+			if err, failed := failures[participant]; failed {
+				name := names[participant]
+				if name == "" {
+					name = participant
+				}
+				warnings = append(warnings, models.CalendarWarning{
+					Participant: participant,
+					Message:     fmt.Sprintf("Couldn't read %s's calendar", name),
+					Details:     err.Error(),
+				})
 			}
 		}
 
+		// Keep the template's buffer free around the meeting by widening everyone's busy time
+		if template != nil && template.BufferMinutes > 0 {
+			padBusySlots(busySlots, time.Duration(template.BufferMinutes)*time.Minute)
+		}
+
 		// Call external Gemini API to find optimal meeting slots
 		log.Println("Calling external Gemini API to find optimal meeting slots")
-		log.Println("participantCalendars: ", participantCalendars)
-		log.Println("req: ", req)
+		log.Printf("Fetched busy intervals for %d participants", len(busySlots))
 
-		suggestions, err := findMeetingSlots(cfg.MeetingSlotsAPIURL, participantCalendars, req)
+		suggestions, err := findMeetingSlots(c.Request.Context(), cfg.MeetingSlotsAPIURL, busySlots, req)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Failed to find meeting times",
@@ -311,6 +634,10 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		if template != nil {
+			suggestions = preferTemplateWindow(suggestions, template, req.TimeZone)
+		}
+
 		// Limit suggestions
 		if len(suggestions) > req.MaxSuggestions {
 			suggestions = suggestions[:req.MaxSuggestions]
@@ -318,6 +645,7 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 
 		response := models.MeetingTimesResponse{
 			Suggestions: suggestions,
+			Warnings:    warnings,
 		}
 
 		if len(suggestions) == 0 {
@@ -326,25 +654,192 @@ func FindMeetingTimes(cfg *config.Config) gin.HandlerFunc {
 			response.Message = "Meeting times found successfully"
 		}
 
+		setTruncatedHeader(c, truncated)
 		c.JSON(http.StatusOK, response)
 	}
 }
 
+// Calendar reads for findTimes run in parallel, each with its own time limit
+const (
+	defaultFindTimesConcurrency  = 8
+	defaultFindTimesFetchTimeout = 15 * time.Second
+)
+
+// errFetchTimeout is reported for a participant whose calendar did not arrive in time
+var errFetchTimeout = errors.New("calendar read timed out")
+
+// fetchBusySlots returns each participant's busy intervals in [startTime, endTime), the error for
+// every participant whose calendar could not be read, who are left without busy time, and whether
+// any calendar had more events than were read
+// Schedules are read in bulk with GetSchedules, which needs only free/busy permission; participants
+// whose schedule is unavailable fall back to reading their calendar events, several at a time
+// Reads stop early when ctx is cancelled
+func fetchBusySlots(ctx context.Context, client services.GraphClient, participants []string, startTime, endTime time.Time) (map[string][]models.TimeSlot, map[string]error, bool) {
+	workers, timeout := findTimesFetchSettings()
+	busySlots := make(map[string][]models.TimeSlot)
+	failures := make(map[string]error)
+
+	schedules, err := withTimeout(ctx, timeout, func(ctx context.Context) ([]models.Schedule, error) {
+		return client.GetSchedules(ctx, participants, startTime, endTime, 0)
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to fetch schedules, reading calendars instead: %v", err)
+	}
+	byEmail := make(map[string]models.Schedule, len(schedules))
+	for _, schedule := range schedules {
+		byEmail[strings.ToLower(schedule.UserEmail)] = schedule
+	}
+
+	var pending []string
+	for _, participant := range participants {
+		schedule, ok := byEmail[strings.ToLower(participant)]
+		if ok && schedule.Error == "" {
+			busySlots[participant] = services.ScheduleBusyIntervals(schedule)
+			continue
+		}
+		if ok {
+			log.Printf("Warning: Schedule of %s unavailable (%s), reading calendar instead", participant, schedule.Error)
+		}
+		pending = append(pending, participant)
+	}
+
+	type result struct {
+		participant string
+		slots       []models.TimeSlot
+		truncated   bool
+		err         error
+	}
+	jobs := make(chan string)
+	results := make(chan result)
+	for i := 0; i < workers && i < len(pending); i++ {
+		go func() {
+			for participant := range jobs {
+				r, err := withTimeout(ctx, timeout, func(ctx context.Context) (result, error) {
+					// Use email address to fetch calendar events via Graph API
+					events, truncated, err := client.GetUserEvents(ctx, participant, startTime, endTime)
+					return result{slots: services.BusyIntervals(events), truncated: truncated}, err
+				})
+				r.participant, r.err = participant, err
+				results <- r
+			}
+		}()
+	}
+	go func() {
+		for _, participant := range pending {
+			jobs <- participant
+		}
+		close(jobs)
+	}()
+
+	truncated := false
+	for range pending {
+		r := <-results
+		if r.err != nil {
+			log.Printf("Warning: Failed to fetch calendar for %s: %v", r.participant, r.err)
+			failures[r.participant] = r.err
+			// Continue with empty calendar for this participant
+			busySlots[r.participant] = []models.TimeSlot{}
+			continue
+		}
+		if r.truncated {
+			log.Printf("Warning: Calendar for %s was truncated; later busy times are missing", r.participant)
+			truncated = true
+		}
+		busySlots[r.participant] = r.slots
+	}
+	return busySlots, failures, truncated
+}
+
+// withTimeout runs fetch with a context that ends after timeout and returns its result,
+// errFetchTimeout once timeout has passed, or ctx's error when ctx ends first
+// A fetch that ignores its context keeps running in the background; its result is discarded
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fetch func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := fetch(ctx)
+		done <- outcome{value, err}
+	}()
+
+	var zero T
+	select {
+	case o := <-done:
+		if o.err == nil || ctx.Err() == nil {
+			return o.value, o.err
+		}
+	case <-ctx.Done():
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return zero, errFetchTimeout
+	}
+	return zero, ctx.Err()
+}
+
+// findTimesFetchSettings reads how many calendars findTimes reads at once and how long each may take
+// - FIND_TIMES_CONCURRENCY: parallel calendar reads (default 8)
+// - FIND_TIMES_FETCH_TIMEOUT: Go duration one participant's read may take (default 15s)
+func findTimesFetchSettings() (int, time.Duration) {
+	workers, timeout := defaultFindTimesConcurrency, defaultFindTimesFetchTimeout
+	if value := os.Getenv("FIND_TIMES_CONCURRENCY"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			log.Printf("Warning: Invalid FIND_TIMES_CONCURRENCY %q, using %d", value, workers)
+		} else {
+			workers = n
+		}
+	}
+	if value := os.Getenv("FIND_TIMES_FETCH_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Printf("Warning: Invalid FIND_TIMES_FETCH_TIMEOUT %q, using %s", value, timeout)
+		} else {
+			timeout = d
+		}
+	}
+	return workers, timeout
+}
+
+// padBusySlots extends every busy interval by buffer on both sides
+func padBusySlots(busySlots map[string][]models.TimeSlot, buffer time.Duration) {
+	for participant, slots := range busySlots {
+		for i := range slots {
+			slots[i].Start = slots[i].Start.Add(-buffer)
+			slots[i].End = slots[i].End.Add(buffer)
+		}
+		busySlots[participant] = slots
+	}
+}
+
+// preferTemplateWindow moves suggestions inside the template's preferred time of day to the front
+// The window is read in timeZone, or UTC when it is empty
+func preferTemplateWindow(suggestions []models.MeetingSuggestion, template *models.MeetingTemplate, timeZone string) []models.MeetingSuggestion {
+	loc, err := services.LoadTimeZone(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return template.InPreferredWindow(suggestions[i].Start, suggestions[i].End, loc) &&
+			!template.InPreferredWindow(suggestions[j].Start, suggestions[j].End, loc)
+	})
+	return suggestions
+}
+
 // findMeetingSlots calls the configured external API to find optimal meeting slots
 // Falls back to local mock logic if external API is unavailable
-func findMeetingSlots(apiURL string, participantCalendars map[string][]models.Event, req models.FindMeetingTimesRequest) ([]models.MeetingSuggestion, error) {
+func findMeetingSlots(ctx context.Context, apiURL string, busySlots map[string][]models.TimeSlot, req models.FindMeetingTimesRequest) ([]models.MeetingSuggestion, error) {
 	// Try to call external API first
-	log.Printf("Calling external API for optimal meeting slots: %s", apiURL)
-	log.Printf("req: %+v", req)
-	log.Printf("req.Attendees: %+v", req.Attendees)
-	log.Printf("req.PriorityAttendees: %+v", req.PriorityAttendees)
-
-	log.Printf("--------------------------------------------------------")
-	log.Printf("participantCalendars: %+v", participantCalendars)
+	log.Printf("Calling external API for optimal meeting slots: %s (%d attendees)", apiURL, len(req.Attendees))
 
 	// Prepare the request payload for the external API
+	// participantCalendars carries only start/end intervals, never event details
 	payload := map[string]interface{}{
-		"participantCalendars": participantCalendars,
+		"participantCalendars": busySlots,
 		"attendees":            req.Attendees,
 		"priorityAttendees":    req.PriorityAttendees,
 		"duration":             req.Duration,
@@ -357,28 +852,34 @@ func findMeetingSlots(apiURL string, participantCalendars map[string][]models.Ev
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		log.Printf("Warning: Failed to marshal request: %v. Falling back to local logic.", err)
-		return findMeetingSlotsLocal(participantCalendars, req)
+		return findMeetingSlotsLocal(busySlots, req)
 	}
 
 	// Call the external API
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(payloadBytes))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		log.Printf("Warning: Failed to build external API request: %v. Falling back to local logic.", err)
+		return findMeetingSlotsLocal(busySlots, req)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
 		log.Printf("Warning: Failed to call external API: %v. Falling back to local logic.", err)
-		return findMeetingSlotsLocal(participantCalendars, req)
+		return findMeetingSlotsLocal(busySlots, req)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		log.Printf("Warning: External API returned status %d: %s. Falling back to local logic.", resp.StatusCode, string(bodyBytes))
-		return findMeetingSlotsLocal(participantCalendars, req)
+		return findMeetingSlotsLocal(busySlots, req)
 	}
 
 	// Read response body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Printf("Warning: Failed to read response body: %v. Falling back to local logic.", err)
-		return findMeetingSlotsLocal(participantCalendars, req)
+		return findMeetingSlotsLocal(busySlots, req)
 	}
 
 	log.Printf("API Response: %s", string(bodyBytes))
@@ -402,7 +903,7 @@ func findMeetingSlots(apiURL string, participantCalendars map[string][]models.Ev
 
 		if directResponse.Status == "no_slots_available" || len(directResponse.SuggestedSlots) == 0 {
 			log.Printf("No slots available. Reasoning: %s. Falling back to local logic.", directResponse.ReasoningSummary)
-			return findMeetingSlotsLocal(participantCalendars, req)
+			return findMeetingSlotsLocal(busySlots, req)
 		}
 
 		// Convert to our format
@@ -463,7 +964,25 @@ func findMeetingSlots(apiURL string, participantCalendars map[string][]models.Ev
 	}
 
 	log.Printf("Warning: Could not parse API response. Falling back to local logic.")
-	return findMeetingSlotsLocal(participantCalendars, req)
+	return findMeetingSlotsLocal(busySlots, req)
+}
+
+// normalizeTimeZone rewrites an optional request time zone, an IANA name or a Windows ID, to its IANA name
+// Writes a 400 response naming field and returns false when the zone is unknown
+func normalizeTimeZone(c *gin.Context, field string, timeZone *string) bool {
+	if *timeZone == "" {
+		return true
+	}
+	iana, err := services.NormalizeTimeZone(*timeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   fmt.Sprintf("Invalid %s", field),
+			"details": err.Error(),
+		})
+		return false
+	}
+	*timeZone = iana
+	return true
 }
 
 // parseFlexibleTime attempts to parse time strings in multiple formats including timezone offsets
@@ -485,22 +1004,9 @@ func parseFlexibleTime(timeStr string) (time.Time, error) {
 }
 
 // findMeetingSlotsLocal is a fallback function that uses local logic to find meeting slots
-func findMeetingSlotsLocal(participantCalendars map[string][]models.Event, req models.FindMeetingTimesRequest) ([]models.MeetingSuggestion, error) {
+func findMeetingSlotsLocal(busySlots map[string][]models.TimeSlot, req models.FindMeetingTimesRequest) ([]models.MeetingSuggestion, error) {
 	log.Println("Using local mock logic for finding meeting slots")
 
-	// Convert participantCalendars to the format expected by MockGraphClient.FindMeetingTimes
-	// We need to create a map of busy slots for each participant
-	busySlots := make(map[string][]models.TimeSlot)
-
-	for participant, events := range participantCalendars {
-		for _, event := range events {
-			busySlots[participant] = append(busySlots[participant], models.TimeSlot{
-				Start: event.Start,
-				End:   event.End,
-			})
-		}
-	}
-
 	// Use the same logic as MockGraphClient.findCommonFreeSlots
 	// Merge all busy slots
 	allBusySlots := []models.TimeSlot{}
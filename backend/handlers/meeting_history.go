@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMeetings lists the meetings the current user organized through the scheduler
+// startTime and endTime (RFC3339) limit the range; cancelled meetings need includeCancelled=true
+func ListMeetings(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Meeting history is not available"})
+			return
+		}
+
+		var startTime, endTime time.Time
+		var err error
+		if value := c.Query("startTime"); value != "" {
+			if startTime, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startTime format. Use RFC3339"})
+				return
+			}
+		}
+		if value := c.Query("endTime"); value != "" {
+			if endTime, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endTime format. Use RFC3339"})
+				return
+			}
+		}
+		if !startTime.IsZero() && !endTime.IsZero() && !endTime.After(startTime) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "endTime must be after startTime"})
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		meetings, err := models.NewMeetingStore(cfg.DB).ListOrganizedMeetings(c.Request.Context(), userEmail, startTime, endTime, c.Query("includeCancelled") == "true")
+		if err != nil {
+			log.Printf("Failed to list meetings for %s: %v", userEmail, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch meetings",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"meetings": meetings,
+			"count":    len(meetings),
+		})
+	}
+}
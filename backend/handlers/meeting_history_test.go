@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListMeetings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// The listing query's arguments: organizer, range start and end, and whether cancelled meetings are wanted
+	var listed []driver.Value
+	db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "FROM meetings"):
+			listed = args
+			return []string{"id", "event_id", "ical_uid", "provider", "provider_ical_uid", "subject", "description",
+					"start_time", "end_time", "location", "is_online", "organizer_email",
+					"suggestion_start", "suggestion_end", "suggestion_confidence", "suggestion_score",
+					"created_at", "updated_at", "cancelled_at"},
+				[][]driver.Value{
+					{"m-2", "evt-2", "evt-2@gruve.ai", "mock", "", "Retro", "", start.Add(48 * time.Hour), start.Add(49 * time.Hour), "", false, "alice@gruve.ai",
+						nil, nil, nil, nil, start, nil, start},
+					{"m-1", "evt-1", "evt-1@gruve.ai", "mock", "", "Design review", "Agenda", start, start.Add(time.Hour), "Room 1", true, "alice@gruve.ai",
+						start, start.Add(time.Hour), 0.9, 87.5, start.Add(-time.Hour), nil, nil},
+				}, nil
+		case strings.Contains(query, "FROM meeting_attendees"):
+			return []string{"meeting_id", "attendee_email", "response_status", "responded_at"},
+				[][]driver.Value{
+					{"m-1", "bob@gruve.ai", "accepted", start.Add(-30 * time.Minute)},
+					{"m-1", "carol@gruve.ai", "none", nil},
+					{"m-2", "bob@gruve.ai", "declined", start},
+				}, nil
+		}
+		return []string{"value"}, nil, nil
+	})
+	cfg := &config.Config{DB: db, GraphAPIBase: newMeServer(t, map[string]string{"alice-token": "alice@gruve.ai"}).URL}
+
+	router := gin.New()
+	router.Use(withAccessToken)
+	router.GET("/api/meetings", ListMeetings(cfg))
+
+	tests := []struct {
+		name          string
+		target        string
+		token         string
+		status        int
+		wantFrom      driver.Value
+		wantTo        driver.Value
+		wantCancelled bool
+	}{
+		{"open range", "/api/meetings", "alice-token", http.StatusOK, nil, nil, false},
+		{"range with cancelled meetings", "/api/meetings?startTime=2026-03-01T00:00:00Z&endTime=2026-03-08T00:00:00Z&includeCancelled=true",
+			"alice-token", http.StatusOK, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), true},
+		{"organizer parameter is ignored", "/api/meetings?organizer=bob@gruve.ai", "alice-token", http.StatusOK, nil, nil, false},
+		{"invalid startTime", "/api/meetings?startTime=yesterday", "alice-token", http.StatusBadRequest, nil, nil, false},
+		{"range ending before it starts", "/api/meetings?startTime=2026-03-08T00:00:00Z&endTime=2026-03-01T00:00:00Z", "alice-token", http.StatusBadRequest, nil, nil, false},
+		{"not signed in", "/api/meetings", "other", http.StatusUnauthorized, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listed = nil
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			if tt.status != http.StatusOK {
+				if listed != nil {
+					t.Errorf("rejected request listed meetings with %v", listed)
+				}
+				return
+			}
+			if len(listed) != 4 || listed[0] != "alice@gruve.ai" || !sameTime(listed[1], tt.wantFrom) || !sameTime(listed[2], tt.wantTo) || listed[3] != tt.wantCancelled {
+				t.Errorf("listed with %v, want alice@gruve.ai, %v, %v, %v", listed, tt.wantFrom, tt.wantTo, tt.wantCancelled)
+			}
+
+			var body struct {
+				Meetings []models.RecordedMeeting `json:"meetings"`
+				Count    int                      `json:"count"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Count != 2 || len(body.Meetings) != 2 {
+				t.Fatalf("meetings = %+v, want the two recorded", body.Meetings)
+			}
+			retro, review := body.Meetings[0], body.Meetings[1]
+			if retro.CancelledAt == nil || len(retro.Attendees) != 1 || retro.Suggestion != nil {
+				t.Errorf("cancelled meeting = %+v, want its cancellation and one response", retro)
+			}
+			if review.Suggestion == nil || review.Suggestion.Score != 87.5 || len(review.Attendees) != 2 ||
+				review.Attendees[0].Status != models.ResponseAccepted || review.Attendees[0].RespondedAt == nil {
+				t.Errorf("booked meeting = %+v, want its suggestion and both responses", review)
+			}
+		})
+	}
+}
+
+// sameTime reports whether a query argument is the wanted time, or both are unset
+func sameTime(arg, want driver.Value) bool {
+	if want == nil {
+		return arg == nil
+	}
+	got, ok := arg.(time.Time)
+	return ok && got.Equal(want.(time.Time))
+}
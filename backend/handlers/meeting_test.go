@@ -0,0 +1,665 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRemovedAttendees(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		current  []string
+		want     []string
+	}{
+		{"unchanged", []string{"a@gruve.ai", "b@gruve.ai"}, []string{"b@gruve.ai", "a@gruve.ai"}, nil},
+		{"one removed", []string{"a@gruve.ai", "b@gruve.ai"}, []string{"a@gruve.ai"}, []string{"b@gruve.ai"}},
+		{"case differs", []string{"A@Gruve.ai"}, []string{"a@gruve.ai"}, nil},
+		{"all replaced", []string{"a@gruve.ai"}, []string{"c@gruve.ai"}, []string{"a@gruve.ai"}},
+		{"previous unknown", nil, []string{"a@gruve.ai"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := removedAttendees(tt.previous, tt.current)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("removedAttendees(%v, %v) = %v, want %v", tt.previous, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondMeetingError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", services.ErrEventNotFound, http.StatusNotFound},
+		{"not organizer", services.ErrNotOrganizer, http.StatusForbidden},
+		{"invalid change", fmt.Errorf("%w: end time must be after start time", services.ErrInvalidEvent), http.StatusBadRequest},
+		{"busy", fmt.Errorf("failed to update calendar event: %w", services.ErrGraphBusy), http.StatusServiceUnavailable},
+		{"other", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondMeetingError(c, "Failed to update meeting", tt.err)
+			if w.Code != tt.want {
+				t.Errorf("respondMeetingError(%v) status = %d, want %d", tt.err, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// newMeServer serves Graph /me for the tokens in users, answering 401 for any other token
+func newMeServer(t *testing.T, users map[string]string) *httptest.Server {
+	t.Helper()
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, ok := users[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		if r.URL.Path != "/me" || !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"mail": email})
+	}))
+	t.Cleanup(graph.Close)
+	return graph
+}
+
+// withAccessToken stands in for middleware.AuthMiddleware, taking the bearer token as given
+func withAccessToken(c *gin.Context) {
+	if token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); token != "" {
+		c.Set("access_token", token)
+	}
+}
+
+func TestMeetingTemplateOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const templateID = "7b0c4a5e-8f0e-4d4e-9a59-0c6f7d0f1a11"
+
+	var owners []string
+	db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		// Records whose templates are searched; none are found
+		owners = append(owners, args[1].(string))
+		return []string{"id"}, nil, nil
+	})
+	cfg := &config.Config{DB: db, GraphAPIBase: newMeServer(t, map[string]string{"alice-token": "alice@gruve.ai"}).URL}
+
+	router := gin.New()
+	router.Use(withAccessToken)
+	router.POST("/api/meetings/create", CreateMeeting(cfg))
+	router.POST("/api/test/findTimes", FindMeetingTimes(cfg))
+
+	tests := []struct {
+		name      string
+		target    string
+		token     string
+		body      string
+		status    int
+		wantOwner string
+	}{
+		{
+			name:      "create looks up the signed-in user's templates",
+			target:    "/api/meetings/create",
+			token:     "alice-token",
+			body:      `{"start":"2026-03-02T09:00:00Z","templateId":"` + templateID + `"}`,
+			status:    http.StatusNotFound,
+			wantOwner: "alice@gruve.ai",
+		},
+		{
+			name:      "find looks up the signed-in user's templates",
+			target:    "/api/test/findTimes",
+			token:     "alice-token",
+			body:      `{"StartTime":"2026-03-02T09:00:00Z","EndTime":"2026-03-06T17:00:00Z","TemplateId":"` + templateID + `"}`,
+			status:    http.StatusNotFound,
+			wantOwner: "alice@gruve.ai",
+		},
+		{
+			name:   "create as someone else",
+			target: "/api/meetings/create?organizer=mallory@gruve.ai",
+			token:  "alice-token",
+			body:   `{"start":"2026-03-02T09:00:00Z","templateId":"` + templateID + `"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "find as someone else",
+			target: "/api/test/findTimes?organizer=mallory@gruve.ai",
+			token:  "alice-token",
+			body:   `{"StartTime":"2026-03-02T09:00:00Z","EndTime":"2026-03-06T17:00:00Z","TemplateId":"` + templateID + `"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "find without a token cannot use templates",
+			target: "/api/test/findTimes?organizer=mallory@gruve.ai",
+			body:   `{"StartTime":"2026-03-02T09:00:00Z","EndTime":"2026-03-06T17:00:00Z","TemplateId":"` + templateID + `"}`,
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owners = nil
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			var wantOwners []string
+			if tt.wantOwner != "" {
+				wantOwners = []string{tt.wantOwner}
+			}
+			if strings.Join(owners, ",") != strings.Join(wantOwners, ",") {
+				t.Errorf("templates looked up for %v, want %v", owners, wantOwners)
+			}
+		})
+	}
+}
+
+func TestResolveOrganizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{GraphAPIBase: newMeServer(t, map[string]string{"alice-token": "alice@gruve.ai"}).URL}
+
+	tests := []struct {
+		name   string
+		target string
+		token  string
+		want   string
+		ok     bool
+		status int
+	}{
+		{"signed-in user", "/api/meetings/create", "alice-token", "alice@gruve.ai", true, http.StatusOK},
+		{"parameter repeats the signed-in user", "/api/meetings/create?organizer=Alice@Gruve.ai", "alice-token", "alice@gruve.ai", true, http.StatusOK},
+		{"parameter names someone else", "/api/meetings/create?organizer=mallory@gruve.ai", "alice-token", "", false, http.StatusForbidden},
+		{"parameter without a valid token", "/api/meetings/create?organizer=alice@gruve.ai", "other", "", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, tt.target, nil)
+
+			got, ok := resolveOrganizer(c, tt.token, cfg)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("resolveOrganizer() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+// mockMeetingDB returns a database holding the mock meeting evt-1 that alice organizes with bob
+// attending, recording the statements run against it
+func mockMeetingDB(t *testing.T, statements *[]string) *config.Config {
+	t.Helper()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		*statements = append(*statements, strings.Join(strings.Fields(query), " "))
+		switch {
+		case strings.Contains(query, "FOR UPDATE"):
+			if args[0] != "evt-1" {
+				return nil, nil, nil
+			}
+			return []string{"id", "subject", "start_time", "end_time", "organizer", "location", "is_online", "online_url",
+					"body_preview", "sequence", "sensitivity", "is_all_day", "show_as"},
+				[][]driver.Value{{"evt-1", "Design review", start, start.Add(time.Hour), "alice@gruve.ai", "Room 1", false, nil, nil, int64(1), "normal", false, "busy"}}, nil
+		case strings.Contains(query, "FROM mock_event_attendees"):
+			return []string{"attendee_email"}, [][]driver.Value{{"bob@gruve.ai"}}, nil
+		case strings.HasPrefix(strings.TrimSpace(query), "UPDATE mock_events"), strings.HasPrefix(query, "DELETE FROM mock_events"):
+			return nil, [][]driver.Value{{}}, nil
+		}
+		return []string{"value"}, nil, nil
+	})
+	return &config.Config{DB: db, GraphAPIBase: newMeServer(t, map[string]string{
+		"alice-token": "alice@gruve.ai",
+		"bob-token":   "bob@gruve.ai",
+	}).URL}
+}
+
+func TestUpdateAndCancelMeeting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("GRAPH_MODE", "mock")
+	// Notices go to an unconfigured Gmail sender and fail in the background
+	t.Setenv("MAIL_MODE", "gmail")
+	t.Setenv("GMAIL_EMAIL", "")
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		token         string
+		body          string
+		status        int
+		wantSequence  int
+		wantStatement string
+	}{
+		{
+			name:          "update bumps the sequence",
+			method:        http.MethodPatch,
+			target:        "/api/calendar/meetings/evt-1",
+			token:         "alice-token",
+			body:          `{"subject":"Design review (moved)","start":"2026-03-02T10:00:00Z","end":"2026-03-02T11:00:00Z"}`,
+			status:        http.StatusOK,
+			wantSequence:  2,
+			wantStatement: "UPDATE mock_events SET subject",
+		},
+		{
+			name:   "update ending before it starts",
+			method: http.MethodPatch,
+			target: "/api/calendar/meetings/evt-1",
+			token:  "alice-token",
+			body:   `{"start":"2026-03-02T10:00:00Z","end":"2026-03-02T09:00:00Z"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update moving the start past the end",
+			method: http.MethodPatch,
+			target: "/api/calendar/meetings/evt-1",
+			token:  "alice-token",
+			body:   `{"start":"2026-03-02T12:00:00Z"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update by an attendee",
+			method: http.MethodPatch,
+			target: "/api/calendar/meetings/evt-1",
+			token:  "bob-token",
+			body:   `{"subject":"Mine now"}`,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "update of an unknown meeting",
+			method: http.MethodPatch,
+			target: "/api/calendar/meetings/evt-2",
+			token:  "alice-token",
+			body:   `{"subject":"Gone"}`,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "update without signing in",
+			method: http.MethodPatch,
+			target: "/api/calendar/meetings/evt-1",
+			body:   `{"subject":"Anonymous"}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:          "cancel with a comment",
+			method:        http.MethodDelete,
+			target:        "/api/calendar/meetings/evt-1",
+			token:         "alice-token",
+			body:          `{"comment":"Postponed"}`,
+			status:        http.StatusOK,
+			wantStatement: "DELETE FROM mock_events WHERE id = $1",
+		},
+		{
+			name:          "cancel without a body",
+			method:        http.MethodDelete,
+			target:        "/api/calendar/meetings/evt-1",
+			token:         "alice-token",
+			status:        http.StatusOK,
+			wantStatement: "UPDATE meetings SET cancelled_at",
+		},
+		{
+			name:   "cancel by an attendee",
+			method: http.MethodDelete,
+			target: "/api/calendar/meetings/evt-1",
+			token:  "bob-token",
+			status: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var statements []string
+			router := gin.New()
+			router.Use(withAccessToken)
+			cfg := mockMeetingDB(t, &statements)
+			router.PATCH("/api/calendar/meetings/:id", UpdateMeeting(cfg))
+			router.DELETE("/api/calendar/meetings/:id", CancelMeeting(cfg))
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			written := false
+			for _, statement := range statements {
+				if strings.HasPrefix(statement, "UPDATE mock_events") || strings.HasPrefix(statement, "DELETE FROM mock_events") {
+					written = true
+				}
+				if tt.wantStatement != "" && strings.HasPrefix(statement, tt.wantStatement) {
+					tt.wantStatement = ""
+				}
+			}
+			if tt.wantStatement != "" {
+				t.Errorf("statements %q do not include %q", statements, tt.wantStatement)
+			}
+			if tt.status != http.StatusOK {
+				if written {
+					t.Errorf("rejected change wrote the meeting: %q", statements)
+				}
+				return
+			}
+			if tt.method == http.MethodPatch {
+				var body struct {
+					Event models.Event `json:"event"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Event.Sequence != tt.wantSequence || strings.Join(body.Event.Attendees, ",") != "bob@gruve.ai" {
+					t.Errorf("event = %+v, want sequence %d with bob attending", body.Event, tt.wantSequence)
+				}
+			}
+		})
+	}
+}
+
+// calendarsClient answers GetSchedules and GetUserEvents from fixed calendars and records the
+// calendars it was asked to read
+type calendarsClient struct {
+	services.GraphClient
+	schedules    map[string]models.Schedule // Missing users are left out of the answer
+	schedulesErr error
+	events       map[string][]models.Event
+	eventsErr    map[string]error
+	truncated    map[string]bool
+	hang         map[string]bool // Calendar reads that only end with their context
+	delay        time.Duration
+
+	mu          sync.Mutex
+	read        []string
+	inFlight    int
+	maxInFlight int
+}
+
+// calendarsRead returns the calendars read so far, sorted
+func (f *calendarsClient) calendarsRead() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	read := append([]string{}, f.read...)
+	sort.Strings(read)
+	return read
+}
+
+func (f *calendarsClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	if f.schedulesErr != nil {
+		return nil, f.schedulesErr
+	}
+	var schedules []models.Schedule
+	for _, email := range userEmails {
+		if schedule, ok := f.schedules[email]; ok {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+func (f *calendarsClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	f.mu.Lock()
+	f.read = append(f.read, userEmail)
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if f.hang[userEmail] {
+		<-ctx.Done()
+		return nil, false, ctx.Err()
+	}
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+	return f.events[userEmail], f.truncated[userEmail], f.eventsErr[userEmail]
+}
+
+func TestFetchBusySlots(t *testing.T) {
+	t.Setenv("FIND_TIMES_FETCH_TIMEOUT", "50ms")
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	schedule := func(email string, from, to int) models.Schedule {
+		return models.Schedule{UserEmail: email, Items: []models.ScheduleItem{{Start: hour(from), End: hour(to), Status: models.ShowAsBusy}}}
+	}
+	events := func(from, to int) []models.Event {
+		return []models.Event{
+			{Start: hour(from), End: hour(to), ShowAs: models.ShowAsBusy},
+			{Start: hour(to), End: hour(to + 1), ShowAs: models.ShowAsFree},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		client        *calendarsClient
+		participants  []string
+		wantBusy      map[string]int // Busy intervals per participant
+		wantRead      []string       // Calendars read because no schedule was available
+		wantFailed    map[string]error
+		wantTruncated bool
+	}{
+		{
+			name: "schedules answer everyone",
+			client: &calendarsClient{schedules: map[string]models.Schedule{
+				"alice@gruve.ai": schedule("alice@gruve.ai", 9, 10),
+				"bob@gruve.ai":   schedule("Bob@gruve.ai", 11, 12),
+			}},
+			participants: []string{"alice@gruve.ai", "bob@gruve.ai"},
+			wantBusy:     map[string]int{"alice@gruve.ai": 1, "bob@gruve.ai": 1},
+		},
+		{
+			name: "schedule errors fall back to the calendar",
+			client: &calendarsClient{
+				schedules: map[string]models.Schedule{
+					"alice@gruve.ai": schedule("alice@gruve.ai", 9, 10),
+					"bob@gruve.ai":   {UserEmail: "bob@gruve.ai", Error: "mailbox not found"},
+				},
+				events: map[string][]models.Event{"bob@gruve.ai": events(13, 14)},
+			},
+			participants: []string{"alice@gruve.ai", "bob@gruve.ai", "carol@gruve.ai"},
+			wantBusy:     map[string]int{"alice@gruve.ai": 1, "bob@gruve.ai": 1, "carol@gruve.ai": 0},
+			wantRead:     []string{"bob@gruve.ai", "carol@gruve.ai"},
+		},
+		{
+			name: "failed getSchedule reads every calendar",
+			client: &calendarsClient{
+				schedulesErr: errors.New("getSchedule is not permitted"),
+				events:       map[string][]models.Event{"alice@gruve.ai": events(9, 10), "bob@gruve.ai": events(11, 12)},
+				truncated:    map[string]bool{"bob@gruve.ai": true},
+			},
+			participants:  []string{"alice@gruve.ai", "bob@gruve.ai"},
+			wantBusy:      map[string]int{"alice@gruve.ai": 1, "bob@gruve.ai": 1},
+			wantRead:      []string{"alice@gruve.ai", "bob@gruve.ai"},
+			wantTruncated: true,
+		},
+		{
+			name: "unread calendars are reported",
+			client: &calendarsClient{
+				schedulesErr: errors.New("getSchedule is not permitted"),
+				events:       map[string][]models.Event{"alice@gruve.ai": events(9, 10)},
+				eventsErr:    map[string]error{"bob@gruve.ai": services.ErrEventNotFound},
+				hang:         map[string]bool{"carol@gruve.ai": true},
+			},
+			participants: []string{"alice@gruve.ai", "bob@gruve.ai", "carol@gruve.ai"},
+			wantBusy:     map[string]int{"alice@gruve.ai": 1, "bob@gruve.ai": 0, "carol@gruve.ai": 0},
+			wantRead:     []string{"alice@gruve.ai", "bob@gruve.ai", "carol@gruve.ai"},
+			wantFailed:   map[string]error{"bob@gruve.ai": services.ErrEventNotFound, "carol@gruve.ai": errFetchTimeout},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			busy, failed, truncated := fetchBusySlots(context.Background(), tt.client, tt.participants, day, day.AddDate(0, 0, 1))
+
+			for _, participant := range tt.participants {
+				slots, ok := busy[participant]
+				if !ok || len(slots) != tt.wantBusy[participant] {
+					t.Errorf("busy slots of %s = %v, want %d", participant, slots, tt.wantBusy[participant])
+				}
+			}
+			if read := tt.client.calendarsRead(); strings.Join(read, ",") != strings.Join(tt.wantRead, ",") {
+				t.Errorf("calendars read = %v, want %v", read, tt.wantRead)
+			}
+			if len(failed) != len(tt.wantFailed) {
+				t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+			for participant, want := range tt.wantFailed {
+				if !errors.Is(failed[participant], want) {
+					t.Errorf("failure of %s = %v, want %v", participant, failed[participant], want)
+				}
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestFetchBusySlotsConcurrency(t *testing.T) {
+	tests := []struct {
+		name         string
+		concurrency  string
+		participants int
+		wantMax      int
+	}{
+		{"bounded", "2", 6, 2},
+		{"fewer participants than workers", "8", 3, 3},
+		{"one at a time", "1", 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FIND_TIMES_CONCURRENCY", tt.concurrency)
+			client := &calendarsClient{schedulesErr: errors.New("getSchedule is not permitted"), delay: 50 * time.Millisecond}
+			var participants []string
+			for i := 0; i < tt.participants; i++ {
+				participants = append(participants, fmt.Sprintf("user%d@gruve.ai", i))
+			}
+
+			day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+			busy, failed, _ := fetchBusySlots(context.Background(), client, participants, day, day.AddDate(0, 0, 1))
+			if len(busy) != tt.participants || len(failed) != 0 {
+				t.Errorf("read %d calendars with failures %v, want %d", len(busy), failed, tt.participants)
+			}
+			if client.maxInFlight != tt.wantMax {
+				t.Errorf("%d calendars were read at once, want %d", client.maxInFlight, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		fetch   func(ctx context.Context) (string, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "fetch in time",
+			ctx:   context.Background(),
+			fetch: func(ctx context.Context) (string, error) { return "events", nil },
+			want:  "events",
+		},
+		{
+			name:    "fetch fails in time",
+			ctx:     context.Background(),
+			fetch:   func(ctx context.Context) (string, error) { return "", services.ErrEventNotFound },
+			wantErr: services.ErrEventNotFound,
+		},
+		{
+			name: "fetch ends with its context",
+			ctx:  context.Background(),
+			fetch: func(ctx context.Context) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			wantErr: errFetchTimeout,
+		},
+		{
+			name: "fetch ignores its context",
+			ctx:  context.Background(),
+			fetch: func(ctx context.Context) (string, error) {
+				time.Sleep(time.Second)
+				return "late", nil
+			},
+			wantErr: errFetchTimeout,
+		},
+		{
+			name: "request cancelled",
+			ctx:  cancelled,
+			fetch: func(ctx context.Context) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := time.Now()
+			got, err := withTimeout(tt.ctx, 50*time.Millisecond, tt.fetch)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("withTimeout() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+			if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+				t.Errorf("withTimeout() returned after %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestFetchBusySlotsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &calendarsClient{
+		schedulesErr: errors.New("getSchedule is not permitted"),
+		hang:         map[string]bool{"alice@gruve.ai": true, "bob@gruve.ai": true},
+	}
+	participants := []string{"alice@gruve.ai", "bob@gruve.ai"}
+
+	started := time.Now()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	busy, failed, _ := fetchBusySlots(ctx, client, participants, day, day.AddDate(0, 0, 1))
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("fetchBusySlots() kept reading for %s after the request was cancelled", elapsed)
+	}
+	for _, participant := range participants {
+		if !errors.Is(failed[participant], context.Canceled) || len(busy[participant]) != 0 {
+			t.Errorf("%s: busy %v, failure %v, want cancelled", participant, busy[participant], failed[participant])
+		}
+	}
+}
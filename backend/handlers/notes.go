@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notesAccess is the caller's view of a meeting's notes
+type notesAccess struct {
+	Store       *models.NotesStore
+	Notes       *models.MeetingNotes
+	User        string
+	IsOrganizer bool
+	Attendees   []string
+}
+
+// GetMeetingNotes returns a meeting's notes and action items
+func GetMeetingNotes(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, ok := authorizeNotesAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, access.Notes)
+	}
+}
+
+// SaveMeetingNotes replaces a meeting's markdown notes
+// Only the organizer may change the access list
+func SaveMeetingNotes(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.SaveNotesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		access, ok := authorizeNotesAccess(c, cfg)
+		if !ok {
+			return
+		}
+		if req.AccessList != nil && !access.IsOrganizer {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can change who can access notes"})
+			return
+		}
+
+		notes, err := access.Store.SaveNotes(c.Request.Context(), c.Param("id"), req.Content, req.AccessList, access.User)
+		if err != nil {
+			respondNotesError(c, "Failed to save meeting notes", err)
+			return
+		}
+		if notes.AccessList == nil {
+			notes.AccessList = access.Attendees
+		}
+
+		c.JSON(http.StatusOK, notes)
+	}
+}
+
+// AddMeetingActionItem adds an action item to a meeting
+func AddMeetingActionItem(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ActionItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid action item",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		access, ok := authorizeNotesAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		item, err := access.Store.AddActionItem(c.Request.Context(), c.Param("id"), req, access.User)
+		if err != nil {
+			respondNotesError(c, "Failed to add action item", err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, item)
+	}
+}
+
+// UpdateMeetingActionItem edits an action item or marks it done
+func UpdateMeetingActionItem(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.UpdateActionItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid action item",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		access, ok := authorizeNotesAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		item, err := access.Store.UpdateActionItem(c.Request.Context(), c.Param("id"), c.Param("itemId"), req)
+		if err != nil {
+			respondNotesError(c, "Failed to update action item", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// DeleteMeetingActionItem removes an action item from a meeting
+func DeleteMeetingActionItem(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, ok := authorizeNotesAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		if err := access.Store.DeleteActionItem(c.Request.Context(), c.Param("id"), c.Param("itemId")); err != nil {
+			respondNotesError(c, "Failed to delete action item", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Action item deleted"})
+	}
+}
+
+// ListMyActionItems returns the calling user's open action items across meetings
+// Pass includeDone=true to include completed ones
+func ListMyActionItems(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Meeting notes are not available"})
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		items, err := models.NewNotesStore(cfg.DB).ListUserActionItems(c.Request.Context(), userEmail, c.Query("includeDone") == "true")
+		if err != nil {
+			respondNotesError(c, "Failed to fetch action items", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"actionItems": items,
+			"count":       len(items),
+		})
+	}
+}
+
+// authorizeNotesAccess checks that the caller may read and edit the notes of the meeting in the :id
+// route parameter: its organizer always may, everyone else must be on the access list, which
+// defaults to the meeting's attendees. Notes are only kept for meetings recorded in the database
+func authorizeNotesAccess(c *gin.Context, cfg *config.Config) (*notesAccess, bool) {
+	if cfg.DB == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Meeting notes are not available"})
+		return nil, false
+	}
+
+	userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+	if !ok {
+		return nil, false
+	}
+
+	eventID := c.Param("id")
+	responses, organizer, err := models.NewMeetingStore(cfg.DB).GetResponses(c.Request.Context(), eventID)
+	if err != nil {
+		respondNotesError(c, "Failed to fetch meeting", err)
+		return nil, false
+	}
+
+	store := models.NewNotesStore(cfg.DB)
+	notes, err := store.GetNotes(c.Request.Context(), eventID)
+	if err != nil {
+		respondNotesError(c, "Failed to fetch meeting notes", err)
+		return nil, false
+	}
+
+	attendees := make([]string, 0, len(responses))
+	for _, response := range responses {
+		attendees = append(attendees, response.Email)
+	}
+	if notes.AccessList == nil {
+		notes.AccessList = attendees
+	}
+
+	isOrganizer := strings.EqualFold(organizer, userEmail)
+	if !notesAccessAllowed(organizer, notes.AccessList, userEmail) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this meeting's notes"})
+		return nil, false
+	}
+
+	return &notesAccess{
+		Store:       store,
+		Notes:       notes,
+		User:        userEmail,
+		IsOrganizer: isOrganizer,
+		Attendees:   attendees,
+	}, true
+}
+
+// notesAccessAllowed reports whether user, as identified by Graph /me, may open the notes of a
+// meeting organized by organizer with the given access list
+func notesAccessAllowed(organizer string, accessList []string, user string) bool {
+	if user == "" {
+		return false
+	}
+	if strings.EqualFold(organizer, user) {
+		return true
+	}
+	for _, email := range accessList {
+		if strings.EqualFold(email, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// respondNotesError maps NotesStore and MeetingStore errors to HTTP responses
+func respondNotesError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, models.ErrMeetingNotFound) || errors.Is(err, models.ErrActionItemNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNotesAccessAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		organizer  string
+		accessList []string
+		user       string
+		want       bool
+	}{
+		{"organizer", "alice@gruve.ai", nil, "alice@gruve.ai", true},
+		{"organizer in other case", "Alice@Gruve.ai", nil, "alice@gruve.ai", true},
+		{"on access list", "alice@gruve.ai", []string{"bob@gruve.ai"}, "BOB@gruve.ai", true},
+		{"not on access list", "alice@gruve.ai", []string{"bob@gruve.ai"}, "carol@gruve.ai", false},
+		{"empty access list", "alice@gruve.ai", []string{}, "bob@gruve.ai", false},
+		{"unknown caller", "", []string{""}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notesAccessAllowed(tt.organizer, tt.accessList, tt.user); got != tt.want {
+				t.Errorf("notesAccessAllowed(%q, %v, %q) = %v, want %v", tt.organizer, tt.accessList, tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveUserEmailIgnoresQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/me" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"mail": "alice@gruve.ai"})
+	}))
+	defer graph.Close()
+	cfg := &config.Config{GraphAPIBase: graph.URL}
+
+	tests := []struct {
+		name   string
+		token  string
+		want   string
+		ok     bool
+		status int
+	}{
+		{"identity from /me", "token", "alice@gruve.ai", true, http.StatusOK},
+		{"invalid token", "other", "", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/notes?email=mallory@gruve.ai", nil)
+
+			got, ok := resolveUserEmail(c, tt.token, cfg)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("resolveUserEmail() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
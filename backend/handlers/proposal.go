@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMeetingProposals lists the new times attendees proposed for a meeting via iMIP COUNTER
+func GetMeetingProposals(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		store, _, ok := authorizeProposalAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		proposals, err := store.GetProposals(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to fetch proposals",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"eventId":   c.Param("id"),
+			"proposals": proposals,
+		})
+	}
+}
+
+// AcceptMeetingProposal moves a meeting to a proposed time and sends attendees the updated invite
+func AcceptMeetingProposal(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+		eventID := c.Param("id")
+
+		store, organizer, ok := authorizeProposalAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		proposal, ok := getPendingProposal(c, store, eventID)
+		if !ok {
+			return
+		}
+
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
+		event, err := client.UpdateCalendarEvent(c.Request.Context(), organizer, eventID, models.UpdateMeetingRequest{
+			Start: &proposal.Start,
+			End:   &proposal.End,
+		})
+		if err != nil {
+			respondMeetingError(c, "Failed to reschedule meeting", err)
+			return
+		}
+
+		if err := store.ResolveProposal(c.Request.Context(), proposal.ID, models.ProposalAccepted); err != nil {
+			// The event already moved; the proposal is left pending rather than failing the request
+			log.Printf("Warning: Failed to mark proposal %s accepted: %v", proposal.ID, err)
+		} else {
+			proposal.Status = models.ProposalAccepted
+		}
+
+		sendInviteAsync(c.Request.Context(), client, accessToken, organizer, &services.MeetingInvite{
+			Subject:     event.Subject,
+			Description: event.BodyPreview,
+			StartTime:   event.Start.Format(time.RFC3339),
+			EndTime:     event.End.Format(time.RFC3339),
+			Attendees:   event.Attendees,
+			Organizer:   organizer,
+			Location:    event.Location,
+			UID:         services.InviteUID(event.ID),
+			Sequence:    event.Sequence,
+			RSVPLinks:   syncMeetingRecord(c.Request.Context(), cfg, event),
+		}, deliverUpdate)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Proposal accepted and meeting rescheduled",
+			"event":    event,
+			"proposal": proposal,
+		})
+	}
+}
+
+// DeclineMeetingProposal rejects a proposed time, leaving the meeting unchanged
+func DeclineMeetingProposal(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		store, _, ok := authorizeProposalAccess(c, cfg)
+		if !ok {
+			return
+		}
+
+		proposal, ok := getPendingProposal(c, store, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		if err := store.ResolveProposal(c.Request.Context(), proposal.ID, models.ProposalDeclined); err != nil {
+			respondProposalError(c, "Failed to decline proposal", err)
+			return
+		}
+		proposal.Status = models.ProposalDeclined
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Proposal declined",
+			"proposal": proposal,
+		})
+	}
+}
+
+// authorizeProposalAccess checks that the caller organizes the meeting in the :id route parameter
+// Proposals are only tracked for meetings recorded in the database
+func authorizeProposalAccess(c *gin.Context, cfg *config.Config) (*models.MeetingStore, string, bool) {
+	if cfg.DB == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Meeting proposals are not available"})
+		return nil, "", false
+	}
+
+	organizer, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+	if !ok {
+		return nil, "", false
+	}
+
+	store := models.NewMeetingStore(cfg.DB)
+	meetingOrganizer, err := store.GetOrganizer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondProposalError(c, "Failed to fetch meeting", err)
+		return nil, "", false
+	}
+	if !strings.EqualFold(meetingOrganizer, organizer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage proposals"})
+		return nil, "", false
+	}
+
+	return store, organizer, true
+}
+
+// getPendingProposal loads the proposal in the :proposalId route parameter and checks it is still pending
+func getPendingProposal(c *gin.Context, store *models.MeetingStore, eventID string) (*models.MeetingProposal, bool) {
+	proposal, err := store.GetProposal(c.Request.Context(), eventID, c.Param("proposalId"))
+	if err != nil {
+		respondProposalError(c, "Failed to fetch proposal", err)
+		return nil, false
+	}
+	if proposal.Status != models.ProposalPending {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Proposal has already been resolved",
+			"status": proposal.Status,
+		})
+		return nil, false
+	}
+	return proposal, true
+}
+
+// respondProposalError maps MeetingStore errors for proposals to HTTP responses
+func respondProposalError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, models.ErrMeetingNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rsvpPageTemplate renders the confirmation page behind invite RSVP links
+// Responses are only recorded on POST so link scanners that prefetch URLs cannot answer for the attendee
+var rsvpPageTemplate = template.Must(template.New("rsvp").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Invitation.Subject}}</title></head>
+<body>
+<h2>{{.Invitation.Subject}}</h2>
+<p>{{.When}}<br>Organizer: {{.Invitation.Organizer}}</p>
+{{if .Recorded}}<p>Thanks, your response has been recorded: <strong>{{.Invitation.Status}}</strong>.</p>
+{{else}}<p>Responding as {{.Invitation.Email}} (current response: {{.Invitation.Status}}).</p>
+<form method="POST">
+<button name="response" value="accepted"{{if eq .Selected "accepted"}} autofocus{{end}}>Accept</button>
+<button name="response" value="tentative"{{if eq .Selected "tentative"}} autofocus{{end}}>Tentative</button>
+<button name="response" value="declined"{{if eq .Selected "declined"}} autofocus{{end}}>Decline</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+// GetMeetingResponses returns each attendee's RSVP status for a meeting
+// In real mode statuses come from Graph and are mirrored into the meetings table when it is available
+func GetMeetingResponses(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken := c.GetString("access_token")
+		eventID := c.Param("id")
+
+		// Only the signed-in organizer may see who responded
+		organizer, ok := resolveUserEmail(c, accessToken, cfg)
+		if !ok {
+			return
+		}
+
+		client := getGraphClient(c.Request.Context(), accessToken, cfg)
+		responses, err := client.GetEventResponses(c.Request.Context(), organizer, eventID)
+		if err != nil {
+			respondMeetingError(c, "Failed to fetch meeting responses", err)
+			return
+		}
+
+		if os.Getenv("GRAPH_MODE") == "real" && cfg.DB != nil {
+			store := models.NewMeetingStore(cfg.DB)
+			for _, response := range responses {
+				if response.RespondedAt == nil {
+					continue
+				}
+				if err := store.SetResponse(c.Request.Context(), eventID, response.Email, response.Status, *response.RespondedAt); err != nil {
+					log.Printf("Warning: Failed to record response for %s: %v", response.Email, err)
+				}
+			}
+		}
+
+		summary := map[models.ResponseStatus]int{
+			models.ResponseAccepted:  0,
+			models.ResponseTentative: 0,
+			models.ResponseDeclined:  0,
+			models.ResponseNone:      0,
+		}
+		for _, response := range responses {
+			summary[response.Status]++
+		}
+
+		c.JSON(http.StatusOK, models.MeetingResponsesResponse{
+			EventID:   eventID,
+			Responses: responses,
+			Summary:   summary,
+		})
+	}
+}
+
+// RSVPPage shows the meeting behind an RSVP link and asks the attendee to confirm their response
+func RSVPPage(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.String(http.StatusServiceUnavailable, "RSVP tracking is not available")
+			return
+		}
+
+		invitation, err := models.NewMeetingStore(cfg.DB).GetInvitationByToken(c.Request.Context(), c.Param("token"))
+		if err != nil {
+			respondRSVPError(c, err)
+			return
+		}
+
+		renderRSVPPage(c, invitation, c.Query("response"), false)
+	}
+}
+
+// RSVPRespond records the response submitted from the RSVP page
+func RSVPRespond(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.String(http.StatusServiceUnavailable, "RSVP tracking is not available")
+			return
+		}
+
+		status := models.ResponseStatus(c.PostForm("response"))
+		if status == "" {
+			status = models.ResponseStatus(c.Query("response"))
+		}
+		if status == models.ResponseNone || !status.IsValid() {
+			c.String(http.StatusBadRequest, "response must be accepted, tentative or declined")
+			return
+		}
+
+		invitation, err := models.NewMeetingStore(cfg.DB).RespondByToken(c.Request.Context(), c.Param("token"), status)
+		if err != nil {
+			respondRSVPError(c, err)
+			return
+		}
+
+		log.Printf("Recorded RSVP %s from %s for event %s", status, invitation.Email, invitation.EventID)
+		renderRSVPPage(c, invitation, string(status), true)
+	}
+}
+
+// renderRSVPPage writes the RSVP confirmation page
+func renderRSVPPage(c *gin.Context, invitation *models.RSVPInvitation, selected string, recorded bool) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	err := rsvpPageTemplate.Execute(c.Writer, gin.H{
+		"Invitation": invitation,
+		"When":       invitation.Start.UTC().Format("Mon Jan 2, 2006 15:04") + " - " + invitation.End.UTC().Format("15:04 MST"),
+		"Selected":   selected,
+		"Recorded":   recorded,
+	})
+	if err != nil {
+		log.Printf("Failed to render RSVP page: %v", err)
+	}
+}
+
+// respondRSVPError maps MeetingStore errors for RSVP links to HTTP responses
+func respondRSVPError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrMeetingNotFound) {
+		c.String(http.StatusNotFound, "This invitation link is invalid or the meeting no longer exists")
+		return
+	}
+	log.Printf("Failed to process RSVP: %v", err)
+	c.String(http.StatusInternalServerError, "Failed to process your response")
+}
+
+// recordMeeting stores a newly created meeting in the meeting history and for RSVP tracking
+// Returns each attendee's RSVP link, or nil when tracking is unavailable
+func recordMeeting(ctx context.Context, cfg *config.Config, event models.Event, description, provider string, suggestion *models.MeetingSuggestion) map[string]string {
+	if cfg.DB == nil {
+		return nil
+	}
+
+	tokens, err := models.NewMeetingStore(cfg.DB).RecordMeeting(ctx, event, description, services.InviteUID(event.ID), provider, suggestion)
+	if err != nil {
+		log.Printf("Warning: Failed to record meeting %s: %v", event.ID, err)
+		return nil
+	}
+	return rsvpLinks(cfg, tokens)
+}
+
+// syncMeetingRecord updates the RSVP record of a meeting after its event changed
+// Returns each attendee's RSVP link, or nil when the meeting is not tracked
+func syncMeetingRecord(ctx context.Context, cfg *config.Config, event models.Event) map[string]string {
+	if cfg.DB == nil {
+		return nil
+	}
+
+	tokens, err := models.NewMeetingStore(cfg.DB).SyncMeeting(ctx, event)
+	if err != nil {
+		if !errors.Is(err, models.ErrMeetingNotFound) {
+			log.Printf("Warning: Failed to sync meeting %s for RSVP tracking: %v", event.ID, err)
+		}
+		return nil
+	}
+	return rsvpLinks(cfg, tokens)
+}
+
+// cancelMeetingRecord marks a meeting as cancelled in the meeting history
+func cancelMeetingRecord(ctx context.Context, cfg *config.Config, eventID string) {
+	if cfg.DB == nil {
+		return
+	}
+
+	err := models.NewMeetingStore(cfg.DB).CancelMeeting(ctx, eventID)
+	if err != nil && !errors.Is(err, models.ErrMeetingNotFound) {
+		log.Printf("Warning: Failed to mark meeting %s as cancelled: %v", eventID, err)
+	}
+}
+
+// calendarProvider names the calendar backing userEmail's calendar behind client, as kept in the meeting history
+func calendarProvider(client services.GraphClient, userEmail string) string {
+	switch services.CalendarBackend(client, userEmail).(type) {
+	case *services.GraphAPIClient:
+		return "graph"
+	case *services.GoogleCalendarClient:
+		return "google"
+	case *services.CalDAVClient:
+		return "caldav"
+	}
+	return "mock"
+}
+
+// rsvpLinks builds public RSVP page URLs from attendee tokens
+func rsvpLinks(cfg *config.Config, tokens map[string]string) map[string]string {
+	if cfg.BackendURL == "" || len(tokens) == 0 {
+		return nil
+	}
+
+	base := strings.TrimRight(cfg.BackendURL, "/") + "/rsvp/"
+	links := make(map[string]string, len(tokens))
+	for email, token := range tokens {
+		links[email] = base + token
+	}
+	return links
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRSVPFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		form       url.Values
+		status     int
+		wantStatus string // Response recorded for bob, empty when none is
+		want       string
+	}{
+		{
+			name:   "link shows the meeting without responding",
+			method: http.MethodGet,
+			target: "/rsvp/bob-token?response=accepted",
+			status: http.StatusOK,
+			want:   `value="accepted" autofocus`,
+		},
+		{
+			name:       "confirming records the response",
+			method:     http.MethodPost,
+			target:     "/rsvp/bob-token",
+			form:       url.Values{"response": {"declined"}},
+			status:     http.StatusOK,
+			wantStatus: "declined",
+			want:       "your response has been recorded: <strong>declined</strong>",
+		},
+		{
+			name:       "response from the link query",
+			method:     http.MethodPost,
+			target:     "/rsvp/bob-token?response=tentative",
+			status:     http.StatusOK,
+			wantStatus: "tentative",
+			want:       "<strong>tentative</strong>",
+		},
+		{
+			name:   "no response",
+			method: http.MethodPost,
+			target: "/rsvp/bob-token",
+			form:   url.Values{"response": {"none"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown response",
+			method: http.MethodPost,
+			target: "/rsvp/bob-token",
+			form:   url.Values{"response": {"maybe"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown link",
+			method: http.MethodGet,
+			target: "/rsvp/other-token",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "responding to an unknown link",
+			method: http.MethodPost,
+			target: "/rsvp/other-token",
+			form:   url.Values{"response": {"accepted"}},
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One invitation, for bob, whose response is kept in recorded
+			recorded := ""
+			db := newFakeDB(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				if args[0] != "bob-token" {
+					return nil, nil, nil
+				}
+				if strings.Contains(query, "UPDATE meeting_attendees") {
+					recorded = args[1].(string)
+					return nil, [][]driver.Value{{}}, nil
+				}
+				status := recorded
+				if status == "" {
+					status = "none"
+				}
+				return []string{"event_id", "subject", "start_time", "end_time", "organizer_email", "attendee_email", "response_status"},
+					[][]driver.Value{{"evt-1", "Design review", start, start.Add(time.Hour), "alice@gruve.ai", "bob@gruve.ai", status}}, nil
+			})
+			cfg := &config.Config{DB: db}
+			router := gin.New()
+			router.GET("/rsvp/:token", RSVPPage(cfg))
+			router.POST("/rsvp/:token", RSVPRespond(cfg))
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.form.Encode()))
+			if tt.form != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body.String(), tt.status)
+			}
+			if recorded != tt.wantStatus {
+				t.Errorf("recorded response %q, want %q", recorded, tt.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("page does not contain %q:\n%s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSharingSettings returns how much of the calling user's calendar other people may see
+func GetSharingSettings(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, models.SharingSettings{
+			UserEmail:    userEmail,
+			SharingLevel: services.GetSharingLevel(c.Request.Context(), cfg.DB, userEmail),
+		})
+	}
+}
+
+// UpdateSharingSettings sets the calling user's calendar sharing level
+func UpdateSharingSettings(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sharing settings are not available"})
+			return
+		}
+
+		var req models.SharingSettings
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !req.SharingLevel.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sharingLevel must be freeBusy, limited or full"})
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		if err := models.NewSharingStore(cfg.DB).SetSharingLevel(c.Request.Context(), userEmail, req.SharingLevel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update sharing settings",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.SharingSettings{
+			UserEmail:    userEmail,
+			SharingLevel: req.SharingLevel,
+		})
+	}
+}
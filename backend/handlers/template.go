@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateMeetingTemplate stores a reusable meeting template for the calling user
+func CreateMeetingTemplate(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireTemplateStore(c, cfg) {
+			return
+		}
+
+		req, ok := bindTemplateRequest(c)
+		if !ok {
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		template, err := models.NewTemplateStore(cfg.DB).CreateTemplate(c.Request.Context(), userEmail, req)
+		if err != nil {
+			respondTemplateError(c, "Failed to create meeting template", err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, template)
+	}
+}
+
+// ListMeetingTemplates returns the calling user's meeting templates
+func ListMeetingTemplates(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireTemplateStore(c, cfg) {
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		templates, err := models.NewTemplateStore(cfg.DB).ListTemplates(c.Request.Context(), userEmail)
+		if err != nil {
+			respondTemplateError(c, "Failed to fetch meeting templates", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"templates": templates,
+			"count":     len(templates),
+		})
+	}
+}
+
+// GetMeetingTemplate returns one of the calling user's meeting templates
+func GetMeetingTemplate(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireTemplateStore(c, cfg) {
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		template, err := models.NewTemplateStore(cfg.DB).GetTemplate(c.Request.Context(), userEmail, c.Param("id"))
+		if err != nil {
+			respondTemplateError(c, "Failed to fetch meeting template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// UpdateMeetingTemplate replaces one of the calling user's meeting templates
+func UpdateMeetingTemplate(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireTemplateStore(c, cfg) {
+			return
+		}
+
+		req, ok := bindTemplateRequest(c)
+		if !ok {
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		template, err := models.NewTemplateStore(cfg.DB).UpdateTemplate(c.Request.Context(), userEmail, c.Param("id"), req)
+		if err != nil {
+			respondTemplateError(c, "Failed to update meeting template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// DeleteMeetingTemplate removes one of the calling user's meeting templates
+func DeleteMeetingTemplate(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireTemplateStore(c, cfg) {
+			return
+		}
+
+		userEmail, ok := resolveUserEmail(c, c.GetString("access_token"), cfg)
+		if !ok {
+			return
+		}
+
+		if err := models.NewTemplateStore(cfg.DB).DeleteTemplate(c.Request.Context(), userEmail, c.Param("id")); err != nil {
+			respondTemplateError(c, "Failed to delete meeting template", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Meeting template deleted"})
+	}
+}
+
+// loadMeetingTemplate fetches the template a FindMeetingTimes or CreateMeeting request refers to
+// Writes an error response and returns false when it is unavailable or not owned by owner
+func loadMeetingTemplate(c *gin.Context, cfg *config.Config, owner, id string) (*models.MeetingTemplate, bool) {
+	if !requireTemplateStore(c, cfg) {
+		return nil, false
+	}
+
+	template, err := models.NewTemplateStore(cfg.DB).GetTemplate(c.Request.Context(), owner, id)
+	if err != nil {
+		respondTemplateError(c, "Failed to load meeting template", err)
+		return nil, false
+	}
+	return template, true
+}
+
+// requireTemplateStore writes a 503 response when templates cannot be stored
+func requireTemplateStore(c *gin.Context, cfg *config.Config) bool {
+	if cfg.DB == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Meeting templates are not available"})
+		return false
+	}
+	return true
+}
+
+// bindTemplateRequest parses and validates a template request body
+func bindTemplateRequest(c *gin.Context) (models.MeetingTemplateRequest, bool) {
+	var req models.MeetingTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return req, false
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid meeting template",
+			"details": err.Error(),
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// respondTemplateError maps TemplateStore errors to HTTP responses
+func respondTemplateError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, models.ErrTemplateNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -40,13 +40,14 @@ func SearchUsers(cfg *config.Config) gin.HandlerFunc {
 		query := c.Query("q")
 
 		var users []models.MSUser
+		var truncated bool
 
 		if query == "" {
 			// If no query, return all users
-			users, err = userService.GetAllUsers()
+			users, truncated, err = userService.GetAllUsers(c.Request.Context())
 		} else {
 			// Search users
-			users, err = userService.SearchUsers(query)
+			users, truncated, err = userService.SearchUsers(c.Request.Context(), query)
 		}
 
 		if err != nil {
@@ -55,6 +56,7 @@ func SearchUsers(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		setTruncatedHeader(c, truncated)
 		c.JSON(http.StatusOK, users)
 	}
 }
@@ -70,13 +72,14 @@ func GetAllUsers(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		users, err := userService.GetAllUsers()
+		users, truncated, err := userService.GetAllUsers(c.Request.Context())
 		if err != nil {
 			log.Printf("Failed to fetch all users: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 			return
 		}
 
+		setTruncatedHeader(c, truncated)
 		c.JSON(http.StatusOK, users)
 	}
 }
@@ -100,7 +103,7 @@ func GetCurrentUser(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		user, err := userService.GetUserByID(userID)
+		user, err := userService.GetUserByID(c.Request.Context(), userID)
 		if err != nil {
 			log.Printf("Failed to fetch current user %s: %v", userID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user information"})
@@ -110,3 +113,11 @@ func GetCurrentUser(cfg *config.Config) gin.HandlerFunc {
 		c.JSON(http.StatusOK, user)
 	}
 }
+
+// setTruncatedHeader marks a response built from directory or calendar reads that stopped at
+// GRAPH_MAX_ITEMS. Every paged endpoint reports it this way, so response bodies keep their shape
+func setTruncatedHeader(c *gin.Context, truncated bool) {
+	if truncated {
+		c.Header("X-Results-Truncated", "true")
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookWorkTimeout bounds the background refresh of one calendar or renewal of one subscription
+const webhookWorkTimeout = 2 * time.Minute
+
+// notificationStore is the part of the subscription store notifications are checked against
+type notificationStore interface {
+	GetSubscription(ctx context.Context, id string) (*models.GraphSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+}
+
+// GraphWebhook receives Graph change and lifecycle notifications for event subscriptions
+// Answers the validationToken handshake Graph sends when a subscription is created,
+// refreshes the cached calendar of every user a genuine notification names and renews
+// subscriptions Graph asks to reauthorize
+func GraphWebhook(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("validationToken"); token != "" {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(token))
+			return
+		}
+		if cfg.DB == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Change notifications are not available"})
+			return
+		}
+
+		var batch models.GraphNotificationBatch
+		if err := c.ShouldBindJSON(&batch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid notification payload",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		affected, reauthorize, err := applyNotifications(c.Request.Context(), models.NewSubscriptionStore(cfg.DB), batch)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to look up subscription",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		cache := models.NewEventCacheStore(cfg.DB)
+		for email := range affected {
+			if err := cache.InvalidateCalendar(c.Request.Context(), email); err != nil {
+				log.Printf("Warning: Failed to invalidate cached calendar of %s: %v", email, err)
+			}
+			services.InvalidateFreeBusy(c.Request.Context(), cfg, email)
+		}
+
+		// Graph expects an answer within a few seconds, so the work is done afterwards
+		c.Status(http.StatusAccepted)
+		background := context.WithoutCancel(c.Request.Context())
+		for email := range affected {
+			go func(email string) {
+				ctx, cancel := context.WithTimeout(background, webhookWorkTimeout)
+				defer cancel()
+				if err := services.RefreshCalendar(ctx, cfg, email); err != nil {
+					log.Printf("Warning: Failed to refresh cached calendar of %s: %v", email, err)
+				}
+			}(email)
+		}
+		for _, sub := range reauthorize {
+			go func(sub models.GraphSubscription) {
+				ctx, cancel := context.WithTimeout(background, webhookWorkTimeout)
+				defer cancel()
+				if err := services.ReauthorizeSubscription(ctx, cfg, sub); err != nil {
+					log.Printf("Warning: Failed to reauthorize subscription %s: %v", sub.ID, err)
+				}
+			}(sub)
+		}
+	}
+}
+
+// applyNotifications checks each notification of batch against its subscription and returns the
+// users whose calendars may have changed and the subscriptions Graph asked to reauthorize
+// Notifications of unknown subscriptions or with a wrong clientState are ignored
+func applyNotifications(ctx context.Context, subs notificationStore, batch models.GraphNotificationBatch) (map[string]bool, []models.GraphSubscription, error) {
+	affected := map[string]bool{}
+	var reauthorize []models.GraphSubscription
+	for _, notification := range batch.Value {
+		sub, err := subs.GetSubscription(ctx, notification.SubscriptionID)
+		if errors.Is(err, models.ErrSubscriptionNotFound) {
+			log.Printf("Warning: Ignoring notification for unknown subscription %s", notification.SubscriptionID)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !services.ValidClientState(sub.ClientState, notification.ClientState) {
+			log.Printf("Warning: Ignoring notification with a wrong clientState for subscription %s", sub.ID)
+			continue
+		}
+
+		switch notification.LifecycleEvent {
+		case "subscriptionRemoved":
+			// Graph dropped the subscription; the subscription manager creates a new one on its next run
+			if err := subs.DeleteSubscription(ctx, sub.ID); err != nil {
+				log.Printf("Warning: Failed to forget subscription %s: %v", sub.ID, err)
+			}
+		case "reauthorizationRequired":
+			// Notifications stop unless the subscription is reauthorized before it expires
+			reauthorize = append(reauthorize, *sub)
+			continue
+		}
+		affected[sub.UserEmail] = true
+	}
+	return affected, reauthorize, nil
+}
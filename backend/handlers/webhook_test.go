@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memorySubscriptions stands in for the subscription store
+type memorySubscriptions struct {
+	subs    map[string]models.GraphSubscription
+	deleted []string
+	err     error
+}
+
+func (m *memorySubscriptions) GetSubscription(ctx context.Context, id string) (*models.GraphSubscription, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	sub, ok := m.subs[id]
+	if !ok {
+		return nil, models.ErrSubscriptionNotFound
+	}
+	return &sub, nil
+}
+
+func (m *memorySubscriptions) DeleteSubscription(ctx context.Context, id string) error {
+	m.deleted = append(m.deleted, id)
+	delete(m.subs, id)
+	return nil
+}
+
+func TestGraphWebhookHandshake(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/webhooks/graph", GraphWebhook(&config.Config{}))
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		body   string
+	}{
+		{"validation token echoed as text", "/webhooks/graph?validationToken=abc%20123", http.StatusOK, "abc 123"},
+		{"notifications without a database", "/webhooks/graph", http.StatusServiceUnavailable, "not available"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(`{"value":[]}`)))
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("got %d %q, want %d containing %q", w.Code, w.Body.String(), tt.status, tt.body)
+			}
+		})
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/graph?validationToken=abc", nil))
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("handshake Content-Type = %q, want text/plain", got)
+	}
+}
+
+func TestApplyNotifications(t *testing.T) {
+	subscriptions := func() *memorySubscriptions {
+		return &memorySubscriptions{subs: map[string]models.GraphSubscription{
+			"sub-alice": {ID: "sub-alice", UserEmail: "alice@gruve.ai", ClientState: "alice-secret"},
+			"sub-bob":   {ID: "sub-bob", UserEmail: "bob@gruve.ai", ClientState: "bob-secret"},
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		notifications   []models.GraphNotification
+		storeErr        error
+		wantAffected    []string
+		wantReauthorize []string
+		wantDeleted     []string
+		wantErr         bool
+	}{
+		{
+			name: "change with the right clientState",
+			notifications: []models.GraphNotification{
+				{SubscriptionID: "sub-alice", ClientState: "alice-secret", ChangeType: "updated"},
+				{SubscriptionID: "sub-alice", ClientState: "alice-secret", ChangeType: "deleted"},
+			},
+			wantAffected: []string{"alice@gruve.ai"},
+		},
+		{
+			name: "wrong clientState or unknown subscription",
+			notifications: []models.GraphNotification{
+				{SubscriptionID: "sub-alice", ClientState: "bob-secret", ChangeType: "updated"},
+				{SubscriptionID: "sub-alice", ClientState: "", ChangeType: "updated"},
+				{SubscriptionID: "sub-carol", ClientState: "alice-secret", ChangeType: "created"},
+			},
+		},
+		{
+			name: "subscription removed",
+			notifications: []models.GraphNotification{
+				{SubscriptionID: "sub-bob", ClientState: "bob-secret", LifecycleEvent: "subscriptionRemoved"},
+			},
+			wantAffected: []string{"bob@gruve.ai"},
+			wantDeleted:  []string{"sub-bob"},
+		},
+		{
+			name: "reauthorization required",
+			notifications: []models.GraphNotification{
+				{SubscriptionID: "sub-bob", ClientState: "bob-secret", LifecycleEvent: "reauthorizationRequired"},
+				{SubscriptionID: "sub-alice", ClientState: "wrong", LifecycleEvent: "reauthorizationRequired"},
+			},
+			wantReauthorize: []string{"sub-bob"},
+		},
+		{
+			name:          "missed notifications",
+			notifications: []models.GraphNotification{{SubscriptionID: "sub-alice", ClientState: "alice-secret", LifecycleEvent: "missed"}},
+			wantAffected:  []string{"alice@gruve.ai"},
+		},
+		{
+			name:          "store failure",
+			notifications: []models.GraphNotification{{SubscriptionID: "sub-alice", ClientState: "alice-secret"}},
+			storeErr:      errors.New("connection refused"),
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := subscriptions()
+			store.err = tt.storeErr
+
+			affected, reauthorize, err := applyNotifications(context.Background(), store, models.GraphNotificationBatch{Value: tt.notifications})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyNotifications() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var gotAffected, gotReauthorize []string
+			for email := range affected {
+				gotAffected = append(gotAffected, email)
+			}
+			sort.Strings(gotAffected)
+			for _, sub := range reauthorize {
+				gotReauthorize = append(gotReauthorize, sub.ID)
+			}
+			if strings.Join(gotAffected, ",") != strings.Join(tt.wantAffected, ",") {
+				t.Errorf("affected = %v, want %v", gotAffected, tt.wantAffected)
+			}
+			if strings.Join(gotReauthorize, ",") != strings.Join(tt.wantReauthorize, ",") {
+				t.Errorf("reauthorize = %v, want %v", gotReauthorize, tt.wantReauthorize)
+			}
+			if strings.Join(store.deleted, ",") != strings.Join(tt.wantDeleted, ",") {
+				t.Errorf("deleted = %v, want %v", store.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"Smart-Meeting-Scheduler/config"
 	"Smart-Meeting-Scheduler/handlers"
 	"Smart-Meeting-Scheduler/middleware"
+	"Smart-Meeting-Scheduler/services"
+	"context"
+	"log"
 	"net/http"
 	"os"
 
@@ -18,6 +21,44 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Poll for iMIP replies and counter-proposals sent by attendees
+	if cfg.DB != nil {
+		processor, err := services.NewIMIPProcessorFromEnv(cfg.DB)
+		if err != nil {
+			log.Printf("Warning: iMIP processing disabled: %v", err)
+		} else if processor != nil {
+			log.Printf("Polling for iMIP messages every %s", processor.Interval)
+			go processor.Run(context.Background())
+		}
+	}
+
+	// Keep a local copy of tracked Graph calendars current with delta queries
+	syncWorker, err := services.NewCalendarSyncWorkerFromEnv(cfg)
+	if err != nil {
+		log.Printf("Warning: Calendar sync disabled: %v", err)
+	} else if syncWorker != nil {
+		log.Printf("Syncing calendars every %s", syncWorker.Settings.Interval)
+		go syncWorker.Run(context.Background())
+	}
+
+	// Keep the users table in step with the directory
+	directoryWorker, err := services.NewDirectorySyncWorkerFromEnv(cfg)
+	if err != nil {
+		log.Printf("Warning: Directory sync disabled: %v", err)
+	} else if directoryWorker != nil {
+		log.Printf("Syncing the directory every %s", directoryWorker.Interval)
+		go directoryWorker.Run(context.Background())
+	}
+
+	// Subscribe to change notifications for tracked calendars and keep the subscriptions renewed
+	subscriptionManager, err := services.NewSubscriptionManagerFromEnv(cfg)
+	if err != nil {
+		log.Printf("Warning: Change notifications disabled: %v", err)
+	} else if subscriptionManager != nil {
+		log.Printf("Receiving change notifications at %s", subscriptionManager.NotificationURL)
+		go subscriptionManager.Run(context.Background())
+	}
+
 	r := gin.New()
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
@@ -41,7 +82,7 @@ func main() {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", cfg.FrontendURL)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -61,6 +102,16 @@ func main() {
 	r.GET("/auth/me", handlers.AuthMe(cfg))
 	r.POST("/auth/logout", handlers.Logout(cfg))
 
+	// RSVP links from emailed invites (token-authenticated)
+	r.GET("/rsvp/:token", handlers.RSVPPage(cfg))
+	r.POST("/rsvp/:token", handlers.RSVPRespond(cfg))
+
+	// Graph change notifications (clientState-authenticated)
+	r.POST("/webhooks/graph", handlers.GraphWebhook(cfg))
+
+	// Subscribable calendar feeds (token-authenticated)
+	r.GET("/feeds/:token/calendar.ics", handlers.CalendarFeed(cfg))
+
 	auth := r.Group("/graph")
 	auth.Use(middleware.AuthMiddleware(cfg))
 	auth.GET("/me", handlers.GraphMe(cfg))
@@ -73,8 +124,38 @@ func main() {
 	api.Use(middleware.AuthMiddleware(cfg))
 	api.GET("/calendar/events", handlers.CalendarEvents(cfg))
 	api.POST("/calendar/availability", handlers.CalendarAvailability(cfg))
+	api.POST("/calendar/import", handlers.ImportCalendar(cfg))
 	api.POST("/calendar/meetings", handlers.CreateMeeting(cfg))
+	api.PATCH("/calendar/meetings/:id", handlers.UpdateMeeting(cfg))
+	api.DELETE("/calendar/meetings/:id", handlers.CancelMeeting(cfg))
+	api.GET("/calendar/meetings/:id/responses", handlers.GetMeetingResponses(cfg))
+	api.GET("/calendar/meetings/:id/proposals", handlers.GetMeetingProposals(cfg))
+	api.POST("/calendar/meetings/:id/proposals/:proposalId/accept", handlers.AcceptMeetingProposal(cfg))
+	api.POST("/calendar/meetings/:id/proposals/:proposalId/decline", handlers.DeclineMeetingProposal(cfg))
+	api.GET("/calendar/meetings/:id/notes", handlers.GetMeetingNotes(cfg))
+	api.PUT("/calendar/meetings/:id/notes", handlers.SaveMeetingNotes(cfg))
+	api.POST("/calendar/meetings/:id/action-items", handlers.AddMeetingActionItem(cfg))
+	api.PATCH("/calendar/meetings/:id/action-items/:itemId", handlers.UpdateMeetingActionItem(cfg))
+	api.DELETE("/calendar/meetings/:id/action-items/:itemId", handlers.DeleteMeetingActionItem(cfg))
 	api.POST("/calendar/findTimes", handlers.FindMeetingTimes(cfg))
+	api.POST("/feeds", handlers.CreateCalendarFeed(cfg))
+	api.GET("/feeds", handlers.ListCalendarFeeds(cfg))
+	api.DELETE("/feeds/:token", handlers.RevokeCalendarFeed(cfg))
+	api.GET("/meetings", handlers.ListMeetings(cfg))
+	api.GET("/analytics/meetings", handlers.MeetingAnalytics(cfg))
+	api.GET("/action-items", handlers.ListMyActionItems(cfg))
+	api.POST("/templates", handlers.CreateMeetingTemplate(cfg))
+	api.GET("/templates", handlers.ListMeetingTemplates(cfg))
+	api.GET("/templates/:id", handlers.GetMeetingTemplate(cfg))
+	api.PUT("/templates/:id", handlers.UpdateMeetingTemplate(cfg))
+	api.DELETE("/templates/:id", handlers.DeleteMeetingTemplate(cfg))
+	api.GET("/admin/directory-sync", handlers.DirectorySyncStatus(cfg))
+	api.GET("/admin/graph-retries", handlers.GraphRetryStatus(cfg))
+	api.GET("/admin/calendar-providers", handlers.ListCalendarProviders(cfg))
+	api.PUT("/admin/calendar-providers/:email", handlers.SetCalendarProvider(cfg))
+	api.DELETE("/admin/calendar-providers/:email", handlers.DeleteCalendarProvider(cfg))
+	api.GET("/settings/sharing", handlers.GetSharingSettings(cfg))
+	api.PUT("/settings/sharing", handlers.UpdateSharingSettings(cfg))
 
 	// Test endpoints (no auth)
 	r.POST("/api/test/findTimes", handlers.FindMeetingTimes(cfg))
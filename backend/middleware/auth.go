@@ -3,6 +3,7 @@ package middleware
 import (
 	"Smart-Meeting-Scheduler/config"
 	"Smart-Meeting-Scheduler/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -37,7 +38,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 
 		fmt.Printf("Session found with access token length: %d\n", len(session.AccessToken))
 		// Fetch user ID from Microsoft Graph
-		userID, err := getUserIDFromGraph(session.AccessToken, cfg)
+		userID, err := getUserIDFromGraph(c.Request.Context(), session.AccessToken, cfg)
 		if err != nil {
 			fmt.Printf("Failed to get user ID from Graph: %v\n", err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -55,8 +56,8 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 }
 
 // getUserIDFromGraph fetches the user ID from Microsoft Graph /me endpoint
-func getUserIDFromGraph(accessToken string, cfg *config.Config) (string, error) {
-	req, err := http.NewRequest("GET", cfg.GraphAPIBase+"/me?$select=id", nil)
+func getUserIDFromGraph(ctx context.Context, accessToken string, cfg *config.Config) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", cfg.GraphAPIBase+"/me?$select=id", nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -64,7 +65,7 @@ func getUserIDFromGraph(accessToken string, cfg *config.Config) (string, error)
 	req.Header.Add("Authorization", "Bearer "+accessToken)
 	req.Header.Add("Accept", "application/json")
 
-	client := &http.Client{}
+	client := utils.NewGraphHTTPClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call Graph API: %w", err)
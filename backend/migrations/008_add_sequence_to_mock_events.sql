@@ -0,0 +1,3 @@
+-- Track the iCalendar SEQUENCE of mock events so update and cancellation
+-- notices supersede the original invite in attendees' calendars
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,25 @@
+-- Link meetings to the calendar event they were created as
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS event_id TEXT UNIQUE;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS organizer_email TEXT;
+
+-- Organizers and attendees are not always present in the users table,
+-- so keep the user references optional and track everyone by email
+ALTER TABLE meetings ALTER COLUMN organizer_id DROP NOT NULL;
+
+ALTER TABLE meeting_attendees DROP CONSTRAINT IF EXISTS meeting_attendees_pkey;
+ALTER TABLE meeting_attendees ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE meeting_attendees ADD COLUMN IF NOT EXISTS attendee_email TEXT;
+ALTER TABLE meeting_attendees ADD COLUMN IF NOT EXISTS responded_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE meeting_attendees ADD COLUMN IF NOT EXISTS rsvp_token TEXT UNIQUE;
+
+-- Backfill emails for rows created before this migration
+UPDATE meeting_attendees ma
+SET attendee_email = LOWER(u.email)
+FROM users u
+WHERE ma.user_id = u.id AND ma.attendee_email IS NULL;
+
+ALTER TABLE meeting_attendees ALTER COLUMN attendee_email SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_meeting_attendees_meeting_email
+    ON meeting_attendees(meeting_id, attendee_email);
+CREATE INDEX IF NOT EXISTS idx_meeting_attendees_email ON meeting_attendees(attendee_email);
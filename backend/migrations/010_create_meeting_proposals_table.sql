@@ -0,0 +1,20 @@
+-- iCalendar UID sent in invites, used to match inbound iMIP replies to meetings
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS ical_uid TEXT UNIQUE;
+
+UPDATE meetings SET ical_uid = event_id || '@gruve.ai'
+WHERE ical_uid IS NULL AND event_id IS NOT NULL;
+
+-- New times proposed by attendees through iMIP COUNTER messages
+CREATE TABLE IF NOT EXISTS meeting_proposals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    meeting_id UUID NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    attendee_email TEXT NOT NULL,
+    proposed_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    proposed_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    comment TEXT,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, accepted, declined, superseded
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_proposals_meeting_id ON meeting_proposals(meeting_id);
@@ -0,0 +1,5 @@
+-- Track events imported from .ics files so re-importing a file updates them in place
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS ical_uid TEXT;
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS imported_by VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_mock_events_imported_uid ON mock_events(imported_by, ical_uid);
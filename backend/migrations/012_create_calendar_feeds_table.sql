@@ -0,0 +1,10 @@
+-- Secret tokens for subscribable per-user .ics calendar feeds
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    token TEXT PRIMARY KEY,
+    user_email TEXT NOT NULL,
+    free_busy_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_accessed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_user_email ON calendar_feeds(user_email);
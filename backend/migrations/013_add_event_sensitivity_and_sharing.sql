@@ -0,0 +1,11 @@
+-- Event sensitivity (normal, private, confidential); private and confidential
+-- events are shown to people other than their attendees as free/busy only
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS sensitivity VARCHAR(20) NOT NULL DEFAULT 'normal';
+
+-- How much of each user's calendar other people may see (freeBusy, limited, full)
+-- Users without a row get the DEFAULT_SHARING_LEVEL
+CREATE TABLE IF NOT EXISTS user_sharing_settings (
+    user_email TEXT PRIMARY KEY,
+    sharing_level VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- All-day events span whole days in the owner's time zone; start_time and
+-- end_time hold the UTC instants of those midnights
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS is_all_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Free/busy status (free, tentative, busy, oof, workingElsewhere, unknown);
+-- free and workingElsewhere events do not block meeting slots
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS show_as VARCHAR(20) NOT NULL DEFAULT 'busy';
+
+-- IANA time zone of each user, used to place all-day events
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(100);
@@ -0,0 +1,20 @@
+-- Reusable meeting shapes ("weekly 1:1", "design review") that fill in defaults
+-- when finding times for or creating a meeting
+CREATE TABLE IF NOT EXISTS meeting_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_email TEXT NOT NULL,
+    name TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    duration_minutes INTEGER NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    is_online BOOLEAN NOT NULL DEFAULT FALSE,
+    attendees TEXT[] NOT NULL DEFAULT '{}',
+    buffer_minutes INTEGER NOT NULL DEFAULT 0,
+    preferred_start VARCHAR(5) NOT NULL DEFAULT '', -- time of day, HH:MM
+    preferred_end VARCHAR(5) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meeting_templates_owner_email ON meeting_templates(owner_email);
@@ -0,0 +1,27 @@
+-- Markdown notes for meetings recorded by the scheduler, keyed by calendar event ID
+-- access_list NULL means the meeting's attendees; the organizer always has access
+CREATE TABLE IF NOT EXISTS meeting_notes (
+    event_id TEXT PRIMARY KEY REFERENCES meetings(event_id) ON DELETE CASCADE,
+    content TEXT NOT NULL DEFAULT '',
+    access_list TEXT[],
+    updated_by TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Follow-ups agreed in a meeting, each owned by one person
+CREATE TABLE IF NOT EXISTS action_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id TEXT NOT NULL REFERENCES meetings(event_id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    owner_email TEXT NOT NULL,
+    due_date DATE,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_action_items_event_id ON action_items(event_id);
+CREATE INDEX IF NOT EXISTS idx_action_items_owner_open ON action_items(owner_email) WHERE NOT done;
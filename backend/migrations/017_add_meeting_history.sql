@@ -0,0 +1,14 @@
+-- Keep a history of every meeting created through the scheduler, whichever calendar holds it
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS provider TEXT;
+-- UID the calendar provider gave the event; ical_uid stays the UID of the scheduler's own invites
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS provider_ical_uid TEXT;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+
+-- The find-times suggestion the organizer booked, if any
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS suggestion_start TIMESTAMP WITH TIME ZONE;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS suggestion_end TIMESTAMP WITH TIME ZONE;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS suggestion_confidence DOUBLE PRECISION;
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS suggestion_score DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_meetings_organizer_start ON meetings(organizer_email, start_time);
@@ -0,0 +1,22 @@
+-- Local copy of Graph calendars kept current by the calendarView delta sync worker
+-- Delta links and sync windows are kept in sync_state under calendarView:<email>
+CREATE TABLE IF NOT EXISTS cached_events (
+    user_email TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    organizer TEXT,
+    attendees TEXT[] NOT NULL DEFAULT '{}',
+    location TEXT,
+    online_url TEXT,
+    body_preview TEXT,
+    sensitivity TEXT NOT NULL DEFAULT 'normal',
+    is_all_day BOOLEAN NOT NULL DEFAULT FALSE,
+    show_as TEXT NOT NULL DEFAULT 'busy',
+    ical_uid TEXT,
+    synced_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_email, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cached_events_user_time ON cached_events(user_email, start_time, end_time);
@@ -0,0 +1,4 @@
+-- Users removed from the directory by the /users/delta sync are kept but hidden
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
@@ -0,0 +1,11 @@
+-- Graph change-notification subscriptions for the events of calendars kept in cached_events
+CREATE TABLE IF NOT EXISTS graph_subscriptions (
+    id TEXT PRIMARY KEY, -- Subscription ID assigned by Graph
+    user_email TEXT NOT NULL,
+    resource TEXT NOT NULL,
+    client_state TEXT NOT NULL, -- Secret Graph echoes in every notification
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_graph_subscriptions_user_email ON graph_subscriptions(user_email);
@@ -0,0 +1,19 @@
+-- Shared cache of calendar reads used when FREE_BUSY_CACHE_BACKEND=postgres
+-- Each row holds the events of one user's calendar over one window, as JSON
+CREATE TABLE IF NOT EXISTS free_busy_cache (
+    user_email TEXT NOT NULL,
+    kind TEXT NOT NULL, -- calendarView or events, matching the GraphClient method
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]',
+    cached_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_email, kind, window_start, window_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_free_busy_cache_cached_at ON free_busy_cache(cached_at);
+
+-- When each user's cached calendar was last invalidated, so reads started earlier are not stored
+CREATE TABLE IF NOT EXISTS free_busy_invalidations (
+    user_email TEXT PRIMARY KEY,
+    invalidated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
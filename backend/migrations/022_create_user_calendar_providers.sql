@@ -0,0 +1,7 @@
+-- Calendar provider each user is bound to (microsoft, google, caldav or local)
+-- Users without a row use the CALENDAR_PROVIDER of the deployment
+CREATE TABLE IF NOT EXISTS user_calendar_providers (
+    user_email TEXT PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
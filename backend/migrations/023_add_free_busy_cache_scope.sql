@@ -0,0 +1,5 @@
+-- Reads made with a user's delegated token are only reused for callers with the same token
+ALTER TABLE free_busy_cache ADD COLUMN IF NOT EXISTS scope TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE free_busy_cache DROP CONSTRAINT IF EXISTS free_busy_cache_pkey;
+ALTER TABLE free_busy_cache ADD PRIMARY KEY (user_email, kind, scope, window_start, window_end);
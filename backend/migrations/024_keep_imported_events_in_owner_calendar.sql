@@ -0,0 +1,16 @@
+-- Events imported from an .ics file belong to the calendar of the user who imported them.
+-- Their attendees are kept as data instead of mock_event_attendees rows, which would put
+-- the events in those attendees' calendars and free/busy
+ALTER TABLE mock_events ADD COLUMN IF NOT EXISTS imported_attendees TEXT[];
+
+UPDATE mock_events e
+SET imported_attendees = (
+    SELECT ARRAY_AGG(ea.attendee_email ORDER BY ea.id)
+    FROM mock_event_attendees ea
+    WHERE ea.event_id = e.id AND LOWER(ea.attendee_email) <> e.imported_by
+)
+WHERE e.imported_by IS NOT NULL AND e.imported_attendees IS NULL;
+
+DELETE FROM mock_event_attendees ea
+USING mock_events e
+WHERE ea.event_id = e.id AND e.imported_by IS NOT NULL;
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// MeetingStats summarizes the meetings in a period
+type MeetingStats struct {
+	MeetingCount        int     `json:"meetingCount"`
+	TotalHours          float64 `json:"totalHours"`          // Time spent in meetings; overlapping meetings count once
+	AverageSize         float64 `json:"averageSize"`         // Participants per meeting, over meetings whose attendees are visible
+	OutsideWorkingHours float64 `json:"outsideWorkingHours"` // Meeting hours outside 9am-6pm on weekdays
+	BackToBackStreaks   int     `json:"backToBackStreaks"`   // Runs of two or more meetings without a break between them
+	LongestStreak       int     `json:"longestStreak"`       // Meetings in the longest run
+}
+
+// WeeklyMeetingStats is the MeetingStats of one week, starting Monday
+type WeeklyMeetingStats struct {
+	WeekStart string `json:"weekStart"` // Date of the Monday, YYYY-MM-DD
+	MeetingStats
+}
+
+// Collaborator is someone a user shares meetings with
+type Collaborator struct {
+	Email    string  `json:"email"`
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// UserMeetingAnalytics is the meeting analytics of one user
+type UserMeetingAnalytics struct {
+	UserEmail        string               `json:"userEmail"`
+	TimeZone         string               `json:"timeZone"` // Zone used for working hours and weeks
+	Totals           MeetingStats         `json:"totals"`
+	Weekly           []WeeklyMeetingStats `json:"weekly"`
+	TopCollaborators []Collaborator       `json:"topCollaborators"`
+}
+
+// TeamMeetingAnalytics combines the meeting analytics of several users
+// Hours add up across users, so a meeting two of them attend counts twice
+type TeamMeetingAnalytics struct {
+	Totals           MeetingStats         `json:"totals"`
+	Weekly           []WeeklyMeetingStats `json:"weekly"`
+	TopCollaborators []Collaborator       `json:"topCollaborators"` // People outside the team
+}
+
+// MeetingAnalyticsResponse represents the response for meeting analytics
+type MeetingAnalyticsResponse struct {
+	StartTime time.Time              `json:"startTime"`
+	EndTime   time.Time              `json:"endTime"`
+	Users     []UserMeetingAnalytics `json:"users"`
+	Team      *TeamMeetingAnalytics  `json:"team,omitempty"` // Only when more than one user was requested
+}
@@ -4,16 +4,91 @@ import "time"
 
 // Event represents a calendar event from Microsoft Graph or local storage
 type Event struct {
-	ID          string    `json:"id"`
-	Subject     string    `json:"subject"`
-	Start       time.Time `json:"start"`
-	End         time.Time `json:"end"`
-	Organizer   string    `json:"organizer"`
-	Attendees   []string  `json:"attendees"`
-	OnlineURL   string    `json:"onlineUrl,omitempty"`
-	Location    string    `json:"location,omitempty"`
-	BodyPreview string    `json:"bodyPreview,omitempty"`
-	IsOnline    bool      `json:"isOnline"`
+	ID          string      `json:"id"`
+	Subject     string      `json:"subject"`
+	Start       time.Time   `json:"start"`
+	End         time.Time   `json:"end"`
+	Organizer   string      `json:"organizer"`
+	Attendees   []string    `json:"attendees"`
+	OnlineURL   string      `json:"onlineUrl,omitempty"`
+	Location    string      `json:"location,omitempty"`
+	BodyPreview string      `json:"bodyPreview,omitempty"`
+	IsOnline    bool        `json:"isOnline"`
+	Sequence    int         `json:"sequence,omitempty"` // iCalendar SEQUENCE, bumped on every update
+	Sensitivity Sensitivity `json:"sensitivity,omitempty"`
+	IsAllDay    bool        `json:"isAllDay"` // Start and End are midnights in the owner's time zone
+	ShowAs      ShowAs      `json:"showAs,omitempty"`
+	ICalUID     string      `json:"iCalUId,omitempty"` // UID the provider assigned to the event, if any
+}
+
+// ShowAs is the free/busy status an event puts on its owner's calendar
+type ShowAs string
+
+const (
+	ShowAsFree             ShowAs = "free"
+	ShowAsTentative        ShowAs = "tentative"
+	ShowAsBusy             ShowAs = "busy"
+	ShowAsOutOfOffice      ShowAs = "oof"
+	ShowAsWorkingElsewhere ShowAs = "workingElsewhere"
+	ShowAsUnknown          ShowAs = "unknown"
+)
+
+// IsValid reports whether s is a known show-as status
+func (s ShowAs) IsValid() bool {
+	switch s {
+	case ShowAsFree, ShowAsTentative, ShowAsBusy, ShowAsOutOfOffice, ShowAsWorkingElsewhere, ShowAsUnknown:
+		return true
+	}
+	return false
+}
+
+// BlocksTime reports whether an event with this status makes its owner unavailable
+// Free and working-elsewhere events leave the time open for meetings; an unset status counts as busy
+func (s ShowAs) BlocksTime() bool {
+	return s != ShowAsFree && s != ShowAsWorkingElsewhere
+}
+
+// Sensitivity marks how private an event is to its owner
+type Sensitivity string
+
+const (
+	SensitivityNormal       Sensitivity = "normal"
+	SensitivityPrivate      Sensitivity = "private"
+	SensitivityConfidential Sensitivity = "confidential"
+)
+
+// IsValid reports whether s is a known sensitivity
+func (s Sensitivity) IsValid() bool {
+	switch s {
+	case SensitivityNormal, SensitivityPrivate, SensitivityConfidential:
+		return true
+	}
+	return false
+}
+
+// SharingLevel is how much of a user's calendar other people may see
+// Attendees of an event always see that event in full
+type SharingLevel string
+
+const (
+	SharingFreeBusy SharingLevel = "freeBusy" // Start and end times only
+	SharingLimited  SharingLevel = "limited"  // Adds subject and location
+	SharingFull     SharingLevel = "full"     // Every detail except private and confidential events
+)
+
+// IsValid reports whether l is a known sharing level
+func (l SharingLevel) IsValid() bool {
+	switch l {
+	case SharingFreeBusy, SharingLimited, SharingFull:
+		return true
+	}
+	return false
+}
+
+// SharingSettings is a user's calendar sharing configuration
+type SharingSettings struct {
+	UserEmail    string       `json:"userEmail"`
+	SharingLevel SharingLevel `json:"sharingLevel"`
 }
 
 // TimeSlot represents a time slot for availability
@@ -31,4 +106,37 @@ type AvailabilityResponse struct {
 	WorkingHours       TimeSlot   `json:"workingHours"`
 	TotalFreeTime      int        `json:"totalFreeTimeMinutes"`
 	TotalBusyTime      int        `json:"totalBusyTimeMinutes"`
+	Truncated          bool       `json:"-"` // The calendar had more events than were read; sent as X-Results-Truncated
+}
+
+// ScheduleItem is one period a user's free/busy schedule marks as taken
+type ScheduleItem struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Status ShowAs    `json:"status"`
+}
+
+// WorkingHours is the part of each week a user works, as set in their mailbox
+type WorkingHours struct {
+	DaysOfWeek []string `json:"daysOfWeek"` // Lowercase English day names
+	StartTime  string   `json:"startTime"`  // HH:MM in TimeZone
+	EndTime    string   `json:"endTime"`
+	TimeZone   string   `json:"timeZone"` // IANA name
+}
+
+// Schedule is one user's free/busy information over a time range
+type Schedule struct {
+	UserEmail        string         `json:"userEmail"`
+	AvailabilityView string         `json:"availabilityView"` // One digit per interval: 0 free, 1 tentative, 2 busy, 3 away, 4 working elsewhere
+	Items            []ScheduleItem `json:"items"`
+	WorkingHours     *WorkingHours  `json:"workingHours,omitempty"`
+	Error            string         `json:"error,omitempty"` // Why the schedule could not be read
+}
+
+// ImportCalendarResponse summarizes an .ics import into the local calendar store
+type ImportCalendarResponse struct {
+	Imported int      `json:"imported"` // Occurrences created
+	Updated  int      `json:"updated"`  // Occurrences already imported from an earlier upload
+	Removed  int      `json:"removed"`  // Occurrences of re-imported series no longer in the file
+	Warnings []string `json:"warnings,omitempty"`
 }
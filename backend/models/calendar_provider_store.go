@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// CalendarProviderBinding ties a user's calendar to a provider other than the deployment's default
+type CalendarProviderBinding struct {
+	UserEmail string    `json:"userEmail"`
+	Provider  string    `json:"provider"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CalendarProviderStore persists per-user calendar provider bindings
+type CalendarProviderStore struct {
+	db *sql.DB
+}
+
+func NewCalendarProviderStore(db *sql.DB) *CalendarProviderStore {
+	return &CalendarProviderStore{db: db}
+}
+
+// GetCalendarProvider returns the provider userEmail is bound to
+// The second return value is false when the user has no binding
+func (s *CalendarProviderStore) GetCalendarProvider(ctx context.Context, userEmail string) (string, bool, error) {
+	var provider string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT provider FROM user_calendar_providers WHERE user_email = LOWER($1)
+	`, userEmail).Scan(&provider)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return provider, true, nil
+}
+
+// ListCalendarProviders returns every binding, ordered by email
+func (s *CalendarProviderStore) ListCalendarProviders(ctx context.Context) ([]CalendarProviderBinding, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT user_email, provider, updated_at FROM user_calendar_providers ORDER BY user_email
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindings := []CalendarProviderBinding{}
+	for rows.Next() {
+		var binding CalendarProviderBinding
+		if err := rows.Scan(&binding.UserEmail, &binding.Provider, &binding.UpdatedAt); err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, rows.Err()
+}
+
+// SetCalendarProvider binds userEmail to provider
+func (s *CalendarProviderStore) SetCalendarProvider(ctx context.Context, userEmail, provider string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_calendar_providers (user_email, provider)
+		VALUES (LOWER($1), $2)
+		ON CONFLICT (user_email) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			updated_at = CURRENT_TIMESTAMP
+	`, userEmail, provider)
+	return err
+}
+
+// DeleteCalendarProvider removes userEmail's binding, returning false if there was none
+func (s *CalendarProviderStore) DeleteCalendarProvider(ctx context.Context, userEmail string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM user_calendar_providers WHERE user_email = LOWER($1)`, userEmail)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
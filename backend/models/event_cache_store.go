@@ -0,0 +1,249 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// calendarSyncKeyPrefix prefixes the sync_state keys of calendarView delta syncs
+const calendarSyncKeyPrefix = "calendarView:"
+
+// CalendarSyncState is where the delta sync of one user's calendar left off
+type CalendarSyncState struct {
+	DeltaLink   string    `json:"deltaLink,omitempty"` // Empty until the first full sync completes
+	WindowStart time.Time `json:"windowStart"`         // Range the delta link covers
+	WindowEnd   time.Time `json:"windowEnd"`
+	LastSynced  time.Time `json:"lastSynced"`
+}
+
+// Covers reports whether the synced window holds every event overlapping [start, end)
+func (s CalendarSyncState) Covers(start, end time.Time) bool {
+	return s.DeltaLink != "" && !start.Before(s.WindowStart) && !end.After(s.WindowEnd)
+}
+
+// EventCacheStore persists the local copy of users' calendars and their delta sync state
+type EventCacheStore struct {
+	db *sql.DB
+}
+
+func NewEventCacheStore(db *sql.DB) *EventCacheStore {
+	return &EventCacheStore{db: db}
+}
+
+// TrackUser adds userEmail to the calendars the sync worker keeps current
+func (s *EventCacheStore) TrackUser(ctx context.Context, userEmail string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sync_state (key, value)
+		VALUES ($1, '{}')
+		ON CONFLICT (key) DO NOTHING
+	`, calendarSyncKey(userEmail))
+	return err
+}
+
+// UntrackUser stops syncing userEmail's calendar and drops its cached events
+func (s *EventCacheStore) UntrackUser(ctx context.Context, userEmail string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sync_state WHERE key = $1`, calendarSyncKey(userEmail)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cached_events WHERE user_email = LOWER($1)`, userEmail); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TrackedUsers lists the users whose calendars are synced
+func (s *EventCacheStore) TrackedUsers(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key
+		FROM sync_state
+		WHERE key LIKE $1 || '%'
+		ORDER BY key
+	`, calendarSyncKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		users = append(users, strings.TrimPrefix(key, calendarSyncKeyPrefix))
+	}
+	return users, rows.Err()
+}
+
+// GetSyncState returns the sync state of userEmail's calendar
+// Reports false when the user is not tracked
+func (s *EventCacheStore) GetSyncState(ctx context.Context, userEmail string) (CalendarSyncState, bool, error) {
+	var state CalendarSyncState
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM sync_state WHERE key = $1`, calendarSyncKey(userEmail)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return CalendarSyncState{}, true, err
+	}
+	return state, true, nil
+}
+
+// SaveSyncState stores where the sync of userEmail's calendar left off
+func (s *EventCacheStore) SaveSyncState(ctx context.Context, userEmail string, state CalendarSyncState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO sync_state (key, value, last_updated)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, last_updated = CURRENT_TIMESTAMP
+	`, calendarSyncKey(userEmail), string(value))
+	return err
+}
+
+// InvalidateCalendar marks userEmail's cached calendar stale, so reads go to Graph until it is synced again
+func (s *EventCacheStore) InvalidateCalendar(ctx context.Context, userEmail string) error {
+	state, tracked, err := s.GetSyncState(ctx, userEmail)
+	if err != nil || !tracked {
+		return err
+	}
+	state.LastSynced = time.Time{}
+	return s.SaveSyncState(ctx, userEmail, state)
+}
+
+// UpsertEvents stores events of userEmail's calendar, replacing earlier copies
+func (s *EventCacheStore) UpsertEvents(ctx context.Context, userEmail string, events []Event, syncedAt time.Time) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO cached_events (user_email, event_id, subject, start_time, end_time, organizer,
+		                           attendees, location, online_url, body_preview, sensitivity,
+		                           is_all_day, show_as, ical_uid, synced_at)
+		VALUES (LOWER($1), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (user_email, event_id) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time,
+			organizer = EXCLUDED.organizer,
+			attendees = EXCLUDED.attendees,
+			location = EXCLUDED.location,
+			online_url = EXCLUDED.online_url,
+			body_preview = EXCLUDED.body_preview,
+			sensitivity = EXCLUDED.sensitivity,
+			is_all_day = EXCLUDED.is_all_day,
+			show_as = EXCLUDED.show_as,
+			ical_uid = EXCLUDED.ical_uid,
+			synced_at = EXCLUDED.synced_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		attendees := event.Attendees
+		if attendees == nil {
+			attendees = []string{}
+		}
+		sensitivity := event.Sensitivity
+		if sensitivity == "" {
+			sensitivity = SensitivityNormal
+		}
+		showAs := event.ShowAs
+		if showAs == "" {
+			showAs = ShowAsBusy
+		}
+		_, err := stmt.ExecContext(ctx, userEmail, event.ID, event.Subject, event.Start, event.End, event.Organizer,
+			pq.Array(attendees), event.Location, event.OnlineURL, event.BodyPreview, sensitivity,
+			event.IsAllDay, showAs, event.ICalUID, syncedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteEvents removes events of userEmail's calendar by ID
+func (s *EventCacheStore) DeleteEvents(ctx context.Context, userEmail string, eventIDs []string) error {
+	if len(eventIDs) == 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM cached_events
+		WHERE user_email = LOWER($1) AND event_id = ANY($2)
+	`, userEmail, pq.Array(eventIDs))
+	return err
+}
+
+// DeleteEventsSyncedBefore removes events of userEmail's calendar not seen since t
+// A full sync uses it to drop events that disappeared while no delta link was held
+func (s *EventCacheStore) DeleteEventsSyncedBefore(ctx context.Context, userEmail string, t time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM cached_events
+		WHERE user_email = LOWER($1) AND synced_at < $2
+	`, userEmail, t)
+	return err
+}
+
+// GetEvents returns the cached events of userEmail's calendar overlapping [start, end)
+func (s *EventCacheStore) GetEvents(ctx context.Context, userEmail string, start, end time.Time) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT event_id, subject, start_time, end_time, COALESCE(organizer, ''), attendees,
+		       COALESCE(location, ''), COALESCE(online_url, ''), COALESCE(body_preview, ''),
+		       sensitivity, is_all_day, show_as, COALESCE(ical_uid, '')
+		FROM cached_events
+		WHERE user_email = LOWER($1)
+		  AND start_time < $3
+		  AND end_time > $2
+		ORDER BY start_time ASC
+	`, userEmail, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var attendees pq.StringArray
+		err := rows.Scan(&event.ID, &event.Subject, &event.Start, &event.End, &event.Organizer, &attendees,
+			&event.Location, &event.OnlineURL, &event.BodyPreview,
+			&event.Sensitivity, &event.IsAllDay, &event.ShowAs, &event.ICalUID)
+		if err != nil {
+			return nil, err
+		}
+		event.Attendees = []string(attendees)
+		event.IsOnline = event.OnlineURL != ""
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func calendarSyncKey(userEmail string) string {
+	return calendarSyncKeyPrefix + strings.ToLower(userEmail)
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrFeedNotFound is returned when a calendar feed token does not exist or was revoked
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+// CalendarFeed is a subscribable .ics feed of a user's calendar
+type CalendarFeed struct {
+	Token          string     `json:"token"`
+	UserEmail      string     `json:"userEmail"`
+	FreeBusyOnly   bool       `json:"freeBusyOnly"`
+	URL            string     `json:"url,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt,omitempty"`
+}
+
+// CreateFeedRequest represents a request to create a calendar feed
+type CreateFeedRequest struct {
+	FreeBusyOnly bool `json:"freeBusyOnly"`
+}
+
+// FeedStore persists calendar feed tokens
+type FeedStore struct {
+	db *sql.DB
+}
+
+func NewFeedStore(db *sql.DB) *FeedStore {
+	return &FeedStore{db: db}
+}
+
+// CreateFeed issues a new feed token for userEmail
+func (s *FeedStore) CreateFeed(ctx context.Context, userEmail string, freeBusyOnly bool) (*CalendarFeed, error) {
+	feed := CalendarFeed{
+		Token:        generateSecretToken(),
+		UserEmail:    userEmail,
+		FreeBusyOnly: freeBusyOnly,
+	}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO calendar_feeds (token, user_email, free_busy_only)
+		VALUES ($1, LOWER($2), $3)
+		RETURNING user_email, created_at
+	`, feed.Token, userEmail, freeBusyOnly).Scan(&feed.UserEmail, &feed.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ListFeeds returns every feed issued for userEmail, newest first
+func (s *FeedStore) ListFeeds(ctx context.Context, userEmail string) ([]CalendarFeed, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT token, user_email, free_busy_only, created_at, last_accessed_at
+		FROM calendar_feeds
+		WHERE user_email = LOWER($1)
+		ORDER BY created_at DESC
+	`, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []CalendarFeed{}
+	for rows.Next() {
+		var feed CalendarFeed
+		var lastAccessed sql.NullTime
+		if err := rows.Scan(&feed.Token, &feed.UserEmail, &feed.FreeBusyOnly, &feed.CreatedAt, &lastAccessed); err != nil {
+			return nil, err
+		}
+		if lastAccessed.Valid {
+			feed.LastAccessedAt = &lastAccessed.Time
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+// AccessFeed returns the feed for token and records the access time
+func (s *FeedStore) AccessFeed(ctx context.Context, token string) (*CalendarFeed, error) {
+	var feed CalendarFeed
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE calendar_feeds
+		SET last_accessed_at = CURRENT_TIMESTAMP
+		WHERE token = $1
+		RETURNING token, user_email, free_busy_only, created_at, last_accessed_at
+	`, token).Scan(&feed.Token, &feed.UserEmail, &feed.FreeBusyOnly, &feed.CreatedAt, &feed.LastAccessedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// RevokeFeed deletes a feed token owned by userEmail
+func (s *FeedStore) RevokeFeed(ctx context.Context, userEmail, token string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM calendar_feeds WHERE token = $1 AND user_email = LOWER($2)
+	`, token, userEmail)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// FreeBusyEntry is one cached read of a user's calendar over [WindowStart, WindowEnd)
+type FreeBusyEntry struct {
+	UserEmail   string    `json:"userEmail"`
+	Kind        string    `json:"kind"`  // GraphClient method the events came from
+	Scope       string    `json:"scope"` // Access the read was made with; reused only for the same scope
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+	Events      []Event   `json:"events"`
+	CachedAt    time.Time `json:"cachedAt"` // When the read started
+}
+
+// Covers reports whether the entry holds every event overlapping [start, end)
+func (e FreeBusyEntry) Covers(start, end time.Time) bool {
+	return !start.Before(e.WindowStart) && !end.After(e.WindowEnd)
+}
+
+// Overlaps reports whether the entry shares any time with [start, end)
+func (e FreeBusyEntry) Overlaps(start, end time.Time) bool {
+	return start.Before(e.WindowEnd) && end.After(e.WindowStart)
+}
+
+// FreeBusyCacheStore persists cached calendar reads so every backend instance can share them
+type FreeBusyCacheStore struct {
+	db *sql.DB
+}
+
+func NewFreeBusyCacheStore(db *sql.DB) *FreeBusyCacheStore {
+	return &FreeBusyCacheStore{db: db}
+}
+
+// GetEntries returns the entries of userEmail's calendar of the given kind and scope cached after since
+func (s *FreeBusyCacheStore) GetEntries(ctx context.Context, userEmail, kind, scope string, since time.Time) ([]FreeBusyEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT window_start, window_end, events, cached_at
+		FROM free_busy_cache
+		WHERE user_email = LOWER($1) AND kind = $2 AND scope = $3 AND cached_at > $4
+		ORDER BY window_start
+	`, userEmail, kind, scope, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []FreeBusyEntry
+	for rows.Next() {
+		entry := FreeBusyEntry{UserEmail: strings.ToLower(userEmail), Kind: kind, Scope: scope}
+		var events []byte
+		if err := rows.Scan(&entry.WindowStart, &entry.WindowEnd, &events, &entry.CachedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(events, &entry.Events); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SaveEntry stores entry in place of the replaced entries, unless the user's calendar was
+// invalidated after the entry's read started. Entries cached before expiredBefore are dropped
+func (s *FreeBusyCacheStore) SaveEntry(ctx context.Context, entry FreeBusyEntry, replaced []FreeBusyEntry, expiredBefore time.Time) error {
+	events, err := json.Marshal(entry.Events)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, old := range replaced {
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM free_busy_cache
+			WHERE user_email = LOWER($1) AND kind = $2 AND scope = $3 AND window_start = $4 AND window_end = $5
+		`, old.UserEmail, old.Kind, old.Scope, old.WindowStart, old.WindowEnd)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM free_busy_cache WHERE cached_at <= $1`, expiredBefore); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO free_busy_cache (user_email, kind, scope, window_start, window_end, events, cached_at)
+		SELECT LOWER($1), $2::text, $3::text, $4::timestamptz, $5::timestamptz, $6::jsonb, $7::timestamptz
+		WHERE NOT EXISTS (
+			SELECT 1 FROM free_busy_invalidations
+			WHERE user_email = LOWER($1) AND invalidated_at >= $7
+		)
+		ON CONFLICT (user_email, kind, scope, window_start, window_end) DO UPDATE SET
+			events = EXCLUDED.events,
+			cached_at = EXCLUDED.cached_at
+	`, entry.UserEmail, entry.Kind, entry.Scope, entry.WindowStart, entry.WindowEnd, string(events), entry.CachedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// InvalidateUsers drops every cached entry of the given users' calendars
+func (s *FreeBusyCacheStore) InvalidateUsers(ctx context.Context, userEmails []string, at time.Time) error {
+	if len(userEmails) == 0 {
+		return nil
+	}
+	lowered := make([]string, len(userEmails))
+	for i, email := range userEmails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM free_busy_cache WHERE user_email = ANY($1)`, pq.Array(lowered)); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO free_busy_invalidations (user_email, invalidated_at)
+		SELECT DISTINCT email, $2::timestamptz FROM UNNEST($1::text[]) AS email
+		ON CONFLICT (user_email) DO UPDATE SET
+			invalidated_at = GREATEST(free_busy_invalidations.invalidated_at, EXCLUDED.invalidated_at)
+	`, pq.Array(lowered), at)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -34,7 +34,11 @@ func (r *FindMeetingTimesRequest) UnmarshalJSON(data []byte) error {
 		}
 		r.Duration = minutes
 	case nil:
-		r.Duration = 30 // Default
+		if r.TemplateID == "" {
+			r.Duration = 30 // Default
+		} else {
+			r.Duration = 0 // Filled from the template, or defaulted by the handler when it has none
+		}
 	default:
 		return fmt.Errorf("duration must be a number or string, got %T", v)
 	}
@@ -51,36 +55,144 @@ type MeetingSuggestion struct {
 }
 
 // CreateMeetingRequest represents a request to create a new meeting
+// Subject, End and Attendees may be left out when TemplateID supplies them
 type CreateMeetingRequest struct {
-	Subject     string    `json:"subject" binding:"required"`
-	Start       time.Time `json:"start" binding:"required"`
-	End         time.Time `json:"end" binding:"required"`
-	Attendees   []string  `json:"attendees" binding:"required"`
-	Description string    `json:"description,omitempty"`
-	Location    string    `json:"location,omitempty"`
-	IsOnline    bool      `json:"isOnline"`
+	Subject     string      `json:"subject"`
+	Start       time.Time   `json:"start" binding:"required"`
+	End         time.Time   `json:"end"`
+	Attendees   []string    `json:"attendees"`
+	Description string      `json:"description,omitempty"`
+	Location    string      `json:"location,omitempty"`
+	IsOnline    bool        `json:"isOnline"`
+	Sensitivity Sensitivity `json:"sensitivity,omitempty"` // Defaults to normal
+	TemplateID  string      `json:"templateId,omitempty"`  // Meeting template that fills in defaults
+
+	// Suggestion is the find-times suggestion the meeting was booked from, kept in the meeting history
+	Suggestion *MeetingSuggestion `json:"suggestion,omitempty"`
+}
+
+// UpdateMeetingRequest represents a partial update to an existing meeting
+// Nil fields are left unchanged; a non-nil Attendees list replaces the current attendees
+type UpdateMeetingRequest struct {
+	Subject     *string    `json:"subject,omitempty"`
+	Start       *time.Time `json:"start,omitempty"`
+	End         *time.Time `json:"end,omitempty"`
+	Attendees   []string   `json:"attendees,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+}
+
+// CancelMeetingRequest represents a request to cancel a meeting
+type CancelMeetingRequest struct {
+	Comment string `json:"comment,omitempty"` // Optional message sent with the cancellation notice
+}
+
+// ResponseStatus is an attendee's reply to a meeting invitation
+type ResponseStatus string
+
+const (
+	ResponseNone      ResponseStatus = "none"
+	ResponseAccepted  ResponseStatus = "accepted"
+	ResponseTentative ResponseStatus = "tentative"
+	ResponseDeclined  ResponseStatus = "declined"
+)
+
+// IsValid reports whether s is one of the known response statuses
+func (s ResponseStatus) IsValid() bool {
+	switch s {
+	case ResponseNone, ResponseAccepted, ResponseTentative, ResponseDeclined:
+		return true
+	}
+	return false
+}
+
+// AttendeeResponse represents one attendee's current response to a meeting
+type AttendeeResponse struct {
+	Email       string         `json:"email"`
+	Status      ResponseStatus `json:"status"`
+	RespondedAt *time.Time     `json:"respondedAt,omitempty"`
+}
+
+// MeetingResponsesResponse represents the response for a meeting's RSVP summary
+type MeetingResponsesResponse struct {
+	EventID   string                 `json:"eventId"`
+	Responses []AttendeeResponse     `json:"responses"`
+	Summary   map[ResponseStatus]int `json:"summary"`
+}
+
+// RecordedMeeting is a meeting created through the scheduler, as kept in the meeting history
+type RecordedMeeting struct {
+	ID          string             `json:"id"`
+	EventID     string             `json:"eventId"`           // Provider event ID
+	ICalUID     string             `json:"iCalUId,omitempty"` // UID of the scheduler's invites
+	Provider    string             `json:"provider,omitempty"`
+	ProviderUID string             `json:"providerICalUId,omitempty"` // UID the provider assigned, if any
+	Subject     string             `json:"subject"`
+	Description string             `json:"description,omitempty"`
+	Start       time.Time          `json:"start"`
+	End         time.Time          `json:"end"`
+	Location    string             `json:"location,omitempty"`
+	IsOnline    bool               `json:"isOnline"`
+	Organizer   string             `json:"organizer"`
+	Attendees   []AttendeeResponse `json:"attendees"`
+	Suggestion  *MeetingSuggestion `json:"suggestion,omitempty"` // Find-times suggestion it was booked from
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   *time.Time         `json:"updatedAt,omitempty"`
+	CancelledAt *time.Time         `json:"cancelledAt,omitempty"`
+}
+
+// ProposalStatus is the state of a new time proposed by an attendee
+type ProposalStatus string
+
+const (
+	ProposalPending    ProposalStatus = "pending"
+	ProposalAccepted   ProposalStatus = "accepted"
+	ProposalDeclined   ProposalStatus = "declined"
+	ProposalSuperseded ProposalStatus = "superseded"
+)
+
+// MeetingProposal represents a new meeting time proposed by an attendee (iMIP COUNTER)
+type MeetingProposal struct {
+	ID            string         `json:"id"`
+	EventID       string         `json:"eventId"`
+	AttendeeEmail string         `json:"attendeeEmail"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	Comment       string         `json:"comment,omitempty"`
+	Status        ProposalStatus `json:"status"`
+	ReceivedAt    time.Time      `json:"receivedAt"`
 }
 
 // FindMeetingTimesRequest represents a request to find available meeting times
 type AttendeeWithTimezone struct {
 	Email    string `json:"email" binding:"required"`
-	TimeZone string `json:"timezone,omitempty"`
+	TimeZone string `json:"timezone,omitempty"` // IANA name or Windows ID, normalized to IANA
 }
 
+// Attendees and Duration may be left out when TemplateID supplies them
 type FindMeetingTimesRequest struct {
-	Attendees         []AttendeeWithTimezone `json:"Attendees" binding:"required"`
+	Attendees         []AttendeeWithTimezone `json:"Attendees"`
 	PriorityAttendees []AttendeeWithTimezone `json:"PriorityAttendees,omitempty"`
-	Duration          int                    `json:"Duration" binding:"required"` // in minutes
+	Duration          int                    `json:"Duration"` // in minutes
 	StartTime         time.Time              `json:"StartTime" binding:"required"`
 	EndTime           time.Time              `json:"EndTime" binding:"required"`
-	TimeZone          string                 `json:"TimeZone,omitempty"` // Organizer's timezone
+	TimeZone          string                 `json:"TimeZone,omitempty"` // Organizer's timezone (IANA name or Windows ID)
 	MaxSuggestions    int                    `json:"MaxSuggestions,omitempty"`
+	TemplateID        string                 `json:"TemplateId,omitempty"` // Meeting template that fills in defaults
 }
 
 // MeetingTimesResponse represents the response for finding meeting times
 type MeetingTimesResponse struct {
 	Suggestions []MeetingSuggestion `json:"suggestions"`
 	Message     string              `json:"message,omitempty"`
+	Warnings    []CalendarWarning   `json:"warnings,omitempty"` // Participants whose busy time is missing from the suggestions
+}
+
+// CalendarWarning reports a participant whose calendar could not be taken into account
+type CalendarWarning struct {
+	Participant string `json:"participant"`
+	Message     string `json:"message"`           // e.g. "Couldn't read Prerna's calendar"
+	Details     string `json:"details,omitempty"` // Underlying error
 }
 
 // parseDurationString parses duration strings like "30m", "1h", "1.5h", "2h" into minutes
@@ -0,0 +1,577 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrMeetingNotFound is returned when no meeting was recorded for an event or RSVP token
+var ErrMeetingNotFound = errors.New("meeting not found")
+
+// RSVPInvitation is the meeting and attendee an RSVP token was issued for
+type RSVPInvitation struct {
+	EventID   string         `json:"eventId"`
+	Subject   string         `json:"subject"`
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Organizer string         `json:"organizer"`
+	Email     string         `json:"email"`
+	Status    ResponseStatus `json:"status"`
+}
+
+// MeetingStore persists meetings created through the scheduler and their attendee responses
+type MeetingStore struct {
+	db *sql.DB
+}
+
+func NewMeetingStore(db *sql.DB) *MeetingStore {
+	return &MeetingStore{db: db}
+}
+
+// RecordMeeting stores a meeting created as event in provider's calendar and its attendees
+// icalUID is the UID sent in invites and is used to match inbound replies; suggestion is the
+// find-times suggestion the meeting was booked from, if any
+// Returns the RSVP token issued to each attendee, keyed by lowercase email
+func (s *MeetingStore) RecordMeeting(ctx context.Context, event Event, description, icalUID, provider string, suggestion *MeetingSuggestion) (map[string]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var suggestionStart, suggestionEnd, confidence, score interface{}
+	if suggestion != nil {
+		suggestionStart, suggestionEnd = suggestion.Start, suggestion.End
+		confidence, score = suggestion.Confidence, suggestion.Score
+	}
+
+	var meetingID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO meetings (subject, start_time, end_time, description, location, is_online,
+		                      organizer_id, organizer_email, event_id, ical_uid, provider, provider_ical_uid,
+		                      suggestion_start, suggestion_end, suggestion_confidence, suggestion_score)
+		VALUES ($1, $2, $3, $4, $5, $6,
+		        (SELECT id FROM users WHERE LOWER(email) = LOWER($7) LIMIT 1), LOWER($7), $8, $9, $10, NULLIF($11, ''),
+		        $12, $13, $14, $15)
+		ON CONFLICT (event_id) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			start_time = EXCLUDED.start_time,
+			end_time = EXCLUDED.end_time,
+			description = EXCLUDED.description,
+			location = EXCLUDED.location,
+			is_online = EXCLUDED.is_online,
+			provider_ical_uid = COALESCE(EXCLUDED.provider_ical_uid, meetings.provider_ical_uid),
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, event.Subject, event.Start, event.End, description, event.Location, event.IsOnline,
+		event.Organizer, event.ID, icalUID, provider, event.ICalUID,
+		suggestionStart, suggestionEnd, confidence, score).Scan(&meetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := syncMeetingAttendees(ctx, tx, meetingID, event.Attendees)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, tx.Commit()
+}
+
+// SyncMeeting updates a recorded meeting after its event changed
+// Attendees no longer on the event are dropped and new ones are issued RSVP tokens
+// Returns ErrMeetingNotFound if the event was not created through the scheduler
+func (s *MeetingStore) SyncMeeting(ctx context.Context, event Event) (map[string]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var meetingID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE meetings
+		SET subject = $2, start_time = $3, end_time = $4, location = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE event_id = $1
+		RETURNING id
+	`, event.ID, event.Subject, event.Start, event.End, event.Location).Scan(&meetingID)
+	if err == sql.ErrNoRows {
+		return nil, ErrMeetingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := syncMeetingAttendees(ctx, tx, meetingID, event.Attendees)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, tx.Commit()
+}
+
+// CancelMeeting marks the meeting created as eventID as cancelled; it stays in the history
+// Returns ErrMeetingNotFound if the event was not created through the scheduler
+func (s *MeetingStore) CancelMeeting(ctx context.Context, eventID string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE meetings
+		SET cancelled_at = COALESCE(cancelled_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE event_id = $1
+	`, eventID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrMeetingNotFound
+	}
+	return nil
+}
+
+// ListOrganizedMeetings returns the meetings organizerEmail created through the scheduler,
+// most recent first. A zero from or to leaves that end of the range open; cancelled meetings
+// are only included with includeCancelled
+func (s *MeetingStore) ListOrganizedMeetings(ctx context.Context, organizerEmail string, from, to time.Time, includeCancelled bool) ([]RecordedMeeting, error) {
+	var fromArg, toArg interface{}
+	if !from.IsZero() {
+		fromArg = from
+	}
+	if !to.IsZero() {
+		toArg = to
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, COALESCE(event_id, ''), COALESCE(ical_uid, ''), COALESCE(provider, ''),
+		       COALESCE(provider_ical_uid, ''), subject,
+		       COALESCE(description, ''), start_time, end_time, COALESCE(location, ''),
+		       COALESCE(is_online, false), COALESCE(organizer_email, ''),
+		       suggestion_start, suggestion_end, suggestion_confidence, suggestion_score,
+		       created_at, updated_at, cancelled_at
+		FROM meetings
+		WHERE organizer_email = LOWER($1)
+		  AND ($2::timestamptz IS NULL OR end_time > $2)
+		  AND ($3::timestamptz IS NULL OR start_time < $3)
+		  AND ($4 OR cancelled_at IS NULL)
+		ORDER BY start_time DESC
+	`, organizerEmail, fromArg, toArg, includeCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meetings := []RecordedMeeting{}
+	index := map[string]int{}
+	for rows.Next() {
+		var m RecordedMeeting
+		var suggestionStart, suggestionEnd, updatedAt, cancelledAt sql.NullTime
+		var confidence, score sql.NullFloat64
+		err := rows.Scan(&m.ID, &m.EventID, &m.ICalUID, &m.Provider,
+			&m.ProviderUID, &m.Subject,
+			&m.Description, &m.Start, &m.End, &m.Location,
+			&m.IsOnline, &m.Organizer,
+			&suggestionStart, &suggestionEnd, &confidence, &score,
+			&m.CreatedAt, &updatedAt, &cancelledAt)
+		if err != nil {
+			return nil, err
+		}
+		if suggestionStart.Valid && suggestionEnd.Valid {
+			m.Suggestion = &MeetingSuggestion{
+				Start:      suggestionStart.Time,
+				End:        suggestionEnd.Time,
+				Confidence: confidence.Float64,
+				Score:      score.Float64,
+			}
+		}
+		if updatedAt.Valid {
+			m.UpdatedAt = &updatedAt.Time
+		}
+		if cancelledAt.Valid {
+			m.CancelledAt = &cancelledAt.Time
+		}
+		m.Attendees = []AttendeeResponse{}
+		index[m.ID] = len(meetings)
+		meetings = append(meetings, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(meetings) == 0 {
+		return meetings, nil
+	}
+
+	ids := make([]string, 0, len(meetings))
+	for _, m := range meetings {
+		ids = append(ids, m.ID)
+	}
+	attendeeRows, err := s.db.QueryContext(ctx, `
+		SELECT meeting_id, attendee_email, COALESCE(response_status, 'none'), responded_at
+		FROM meeting_attendees
+		WHERE meeting_id = ANY($1::uuid[])
+		ORDER BY attendee_email ASC
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer attendeeRows.Close()
+
+	for attendeeRows.Next() {
+		var meetingID string
+		var response AttendeeResponse
+		var respondedAt sql.NullTime
+		if err := attendeeRows.Scan(&meetingID, &response.Email, &response.Status, &respondedAt); err != nil {
+			return nil, err
+		}
+		if respondedAt.Valid {
+			response.RespondedAt = &respondedAt.Time
+		}
+		if i, ok := index[meetingID]; ok {
+			meetings[i].Attendees = append(meetings[i].Attendees, response)
+		}
+	}
+
+	return meetings, attendeeRows.Err()
+}
+
+// syncMeetingAttendees makes the meeting's attendee rows match attendees
+// Existing attendees keep their token and response
+func syncMeetingAttendees(ctx context.Context, tx *sql.Tx, meetingID string, attendees []string) (map[string]string, error) {
+	emails := make([]string, 0, len(attendees))
+	for _, attendee := range attendees {
+		emails = append(emails, strings.ToLower(strings.TrimSpace(attendee)))
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM meeting_attendees
+		WHERE meeting_id = $1 AND NOT (attendee_email = ANY($2))
+	`, meetingID, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]string, len(emails))
+	for _, email := range emails {
+		if _, seen := tokens[email]; seen {
+			continue
+		}
+
+		// On conflict the existing token is returned unchanged
+		var token string
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO meeting_attendees (meeting_id, user_id, attendee_email, rsvp_token)
+			VALUES ($1, (SELECT id FROM users WHERE LOWER(email) = $2 LIMIT 1), $2, $3)
+			ON CONFLICT (meeting_id, attendee_email) DO UPDATE SET
+				rsvp_token = COALESCE(meeting_attendees.rsvp_token, EXCLUDED.rsvp_token)
+			RETURNING rsvp_token
+		`, meetingID, email, generateSecretToken()).Scan(&token)
+		if err != nil {
+			return nil, err
+		}
+		tokens[email] = token
+	}
+
+	return tokens, nil
+}
+
+// GetOrganizer returns the organizer email of the meeting created as eventID
+func (s *MeetingStore) GetOrganizer(ctx context.Context, eventID string) (string, error) {
+	var organizer string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(organizer_email, '') FROM meetings WHERE event_id = $1
+	`, eventID).Scan(&organizer)
+	if err == sql.ErrNoRows {
+		return "", ErrMeetingNotFound
+	}
+	return organizer, err
+}
+
+// GetOrganizerByUID returns the organizer email of the meeting whose invites carried icalUID
+func (s *MeetingStore) GetOrganizerByUID(ctx context.Context, icalUID string) (string, error) {
+	var organizer string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(organizer_email, '') FROM meetings WHERE ical_uid = $1
+	`, icalUID).Scan(&organizer)
+	if err == sql.ErrNoRows {
+		return "", ErrMeetingNotFound
+	}
+	return organizer, err
+}
+
+// GetResponses returns every attendee's response for the meeting created as eventID
+// along with the meeting's organizer email
+func (s *MeetingStore) GetResponses(ctx context.Context, eventID string) ([]AttendeeResponse, string, error) {
+	var meetingID, organizer string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, COALESCE(organizer_email, '') FROM meetings WHERE event_id = $1
+	`, eventID).Scan(&meetingID, &organizer)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrMeetingNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT attendee_email, COALESCE(response_status, 'none'), responded_at
+		FROM meeting_attendees
+		WHERE meeting_id = $1
+		ORDER BY attendee_email ASC
+	`, meetingID)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	responses := []AttendeeResponse{}
+	for rows.Next() {
+		var response AttendeeResponse
+		var respondedAt sql.NullTime
+		if err := rows.Scan(&response.Email, &response.Status, &respondedAt); err != nil {
+			return nil, "", err
+		}
+		if respondedAt.Valid {
+			response.RespondedAt = &respondedAt.Time
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, organizer, rows.Err()
+}
+
+// SetResponse records an attendee's response to the meeting created as eventID
+// Responses older than the one already stored are ignored
+func (s *MeetingStore) SetResponse(ctx context.Context, eventID, email string, status ResponseStatus, respondedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE meeting_attendees ma
+		SET response_status = $3, responded_at = $4
+		FROM meetings m
+		WHERE ma.meeting_id = m.id
+		  AND m.event_id = $1
+		  AND ma.attendee_email = LOWER($2)
+		  AND (ma.responded_at IS NULL OR ma.responded_at <= $4)
+	`, eventID, email, string(status), respondedAt)
+	return err
+}
+
+// SetResponseByUID records an attendee's response to the meeting whose invites carried icalUID
+// Returns ErrMeetingNotFound if the UID or attendee is unknown
+func (s *MeetingStore) SetResponseByUID(ctx context.Context, icalUID, email string, status ResponseStatus, respondedAt time.Time) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE meeting_attendees ma
+		SET response_status = $3, responded_at = $4
+		FROM meetings m
+		WHERE ma.meeting_id = m.id
+		  AND m.ical_uid = $1
+		  AND ma.attendee_email = LOWER($2)
+		  AND (ma.responded_at IS NULL OR ma.responded_at <= $4)
+	`, icalUID, email, string(status), respondedAt)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Distinguish unknown attendees from stale replies that were ignored
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM meeting_attendees ma JOIN meetings m ON m.id = ma.meeting_id
+				WHERE m.ical_uid = $1 AND ma.attendee_email = LOWER($2)
+			)
+		`, icalUID, email).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrMeetingNotFound
+		}
+	}
+	return nil
+}
+
+// AddProposal stores a new time proposed by an attendee of the meeting whose invites carried icalUID
+// Earlier pending proposals from the same attendee are superseded
+func (s *MeetingStore) AddProposal(ctx context.Context, icalUID, email string, start, end time.Time, comment string) (*MeetingProposal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var meetingID, eventID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT m.id, COALESCE(m.event_id, '')
+		FROM meetings m
+		JOIN meeting_attendees ma ON ma.meeting_id = m.id
+		WHERE m.ical_uid = $1 AND ma.attendee_email = LOWER($2)
+	`, icalUID, email).Scan(&meetingID, &eventID)
+	if err == sql.ErrNoRows {
+		return nil, ErrMeetingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE meeting_proposals
+		SET status = $3, resolved_at = CURRENT_TIMESTAMP
+		WHERE meeting_id = $1 AND attendee_email = LOWER($2) AND status = $4
+	`, meetingID, email, string(ProposalSuperseded), string(ProposalPending))
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := MeetingProposal{
+		EventID:       eventID,
+		AttendeeEmail: strings.ToLower(email),
+		Start:         start,
+		End:           end,
+		Comment:       comment,
+		Status:        ProposalPending,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO meeting_proposals (meeting_id, attendee_email, proposed_start, proposed_end, comment, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, received_at
+	`, meetingID, proposal.AttendeeEmail, start, end, comment, string(ProposalPending)).Scan(&proposal.ID, &proposal.ReceivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proposal, tx.Commit()
+}
+
+// GetProposals returns the time proposals received for the meeting created as eventID, newest first
+func (s *MeetingStore) GetProposals(ctx context.Context, eventID string) ([]MeetingProposal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, m.event_id, p.attendee_email, p.proposed_start, p.proposed_end,
+		       COALESCE(p.comment, ''), p.status, p.received_at
+		FROM meeting_proposals p
+		JOIN meetings m ON m.id = p.meeting_id
+		WHERE m.event_id = $1
+		ORDER BY p.received_at DESC
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	proposals := []MeetingProposal{}
+	for rows.Next() {
+		var proposal MeetingProposal
+		err := rows.Scan(&proposal.ID, &proposal.EventID, &proposal.AttendeeEmail, &proposal.Start,
+			&proposal.End, &proposal.Comment, &proposal.Status, &proposal.ReceivedAt)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	return proposals, rows.Err()
+}
+
+// GetProposal returns a single proposal for the meeting created as eventID
+func (s *MeetingStore) GetProposal(ctx context.Context, eventID, proposalID string) (*MeetingProposal, error) {
+	var proposal MeetingProposal
+	err := s.db.QueryRowContext(ctx, `
+		SELECT p.id, m.event_id, p.attendee_email, p.proposed_start, p.proposed_end,
+		       COALESCE(p.comment, ''), p.status, p.received_at
+		FROM meeting_proposals p
+		JOIN meetings m ON m.id = p.meeting_id
+		WHERE m.event_id = $1 AND p.id::text = $2
+	`, eventID, proposalID).Scan(&proposal.ID, &proposal.EventID, &proposal.AttendeeEmail, &proposal.Start,
+		&proposal.End, &proposal.Comment, &proposal.Status, &proposal.ReceivedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrMeetingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &proposal, nil
+}
+
+// ResolveProposal marks a pending proposal accepted or declined
+// Accepting supersedes every other pending proposal for the same meeting
+func (s *MeetingStore) ResolveProposal(ctx context.Context, proposalID string, status ProposalStatus) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var meetingID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE meeting_proposals
+		SET status = $2, resolved_at = CURRENT_TIMESTAMP
+		WHERE id::text = $1 AND status = $3
+		RETURNING meeting_id
+	`, proposalID, string(status), string(ProposalPending)).Scan(&meetingID)
+	if err == sql.ErrNoRows {
+		return ErrMeetingNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == ProposalAccepted {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE meeting_proposals
+			SET status = $2, resolved_at = CURRENT_TIMESTAMP
+			WHERE meeting_id = $1 AND status = $3
+		`, meetingID, string(ProposalSuperseded), string(ProposalPending))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetInvitationByToken returns the meeting and attendee an RSVP token was issued for
+func (s *MeetingStore) GetInvitationByToken(ctx context.Context, token string) (*RSVPInvitation, error) {
+	var invitation RSVPInvitation
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(m.event_id, ''), m.subject, m.start_time, m.end_time,
+		       COALESCE(m.organizer_email, ''), ma.attendee_email, COALESCE(ma.response_status, 'none')
+		FROM meeting_attendees ma
+		JOIN meetings m ON m.id = ma.meeting_id
+		WHERE ma.rsvp_token = $1
+	`, token).Scan(&invitation.EventID, &invitation.Subject, &invitation.Start, &invitation.End,
+		&invitation.Organizer, &invitation.Email, &invitation.Status)
+	if err == sql.ErrNoRows {
+		return nil, ErrMeetingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// RespondByToken records the response of the attendee an RSVP token was issued for
+func (s *MeetingStore) RespondByToken(ctx context.Context, token string, status ResponseStatus) (*RSVPInvitation, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE meeting_attendees
+		SET response_status = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE rsvp_token = $1
+	`, token, string(status))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrMeetingNotFound
+	}
+
+	return s.GetInvitationByToken(ctx, token)
+}
+
+// generateSecretToken creates an unguessable token for RSVP links and calendar feeds
+func generateSecretToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
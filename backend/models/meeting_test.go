@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFindMeetingTimesRequestDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"number", `{"Duration": 45}`, 45},
+		{"string with unit", `{"Duration": "1h"}`, 60},
+		{"left out", `{}`, 30},
+		{"left out with template", `{"TemplateId": "standup"}`, 0},
+		{"given with template", `{"Duration": 15, "TemplateId": "standup"}`, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req FindMeetingTimesRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.body, err)
+			}
+			if req.Duration != tt.want {
+				t.Errorf("Unmarshal(%s) Duration = %d, want %d", tt.body, req.Duration, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrActionItemNotFound is returned when an action item does not exist on the given meeting
+var ErrActionItemNotFound = errors.New("action item not found")
+
+// dueDateLayout is the format of action item due dates
+const dueDateLayout = "2006-01-02"
+
+// MeetingNotes are the shared markdown notes of a meeting
+type MeetingNotes struct {
+	EventID     string       `json:"eventId"`
+	Content     string       `json:"content"` // Markdown
+	AccessList  []string     `json:"accessList"`
+	UpdatedBy   string       `json:"updatedBy,omitempty"`
+	UpdatedAt   *time.Time   `json:"updatedAt,omitempty"`
+	ActionItems []ActionItem `json:"actionItems"`
+}
+
+// ActionItem is a follow-up from a meeting owned by one person
+type ActionItem struct {
+	ID          string     `json:"id"`
+	EventID     string     `json:"eventId"`
+	Description string     `json:"description"`
+	Owner       string     `json:"owner"`
+	DueDate     string     `json:"dueDate,omitempty"` // YYYY-MM-DD
+	Done        bool       `json:"done"`
+	CreatedBy   string     `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Set when listing a user's action items across meetings
+	MeetingSubject string     `json:"meetingSubject,omitempty"`
+	MeetingStart   *time.Time `json:"meetingStart,omitempty"`
+}
+
+// SaveNotesRequest represents a request to write a meeting's notes
+// AccessList replaces who may read and edit the notes; an empty list restores the default
+// (the meeting's attendees) and leaving it out keeps the current list
+type SaveNotesRequest struct {
+	Content    string    `json:"content"`
+	AccessList *[]string `json:"accessList,omitempty"`
+}
+
+// ActionItemRequest represents a request to add an action item to a meeting
+type ActionItemRequest struct {
+	Description string `json:"description" binding:"required"`
+	Owner       string `json:"owner" binding:"required"`
+	DueDate     string `json:"dueDate,omitempty"` // YYYY-MM-DD
+}
+
+// Validate checks the due date format
+func (r ActionItemRequest) Validate() error {
+	return validateDueDate(r.DueDate)
+}
+
+// UpdateActionItemRequest represents a partial update to an action item
+// Nil fields are left unchanged; an empty DueDate clears it
+type UpdateActionItemRequest struct {
+	Description *string `json:"description,omitempty"`
+	Owner       *string `json:"owner,omitempty"`
+	DueDate     *string `json:"dueDate,omitempty"`
+	Done        *bool   `json:"done,omitempty"`
+}
+
+// Validate checks that set fields are not blank and the due date format
+func (r UpdateActionItemRequest) Validate() error {
+	if r.Description != nil && strings.TrimSpace(*r.Description) == "" {
+		return fmt.Errorf("description cannot be empty")
+	}
+	if r.Owner != nil && strings.TrimSpace(*r.Owner) == "" {
+		return fmt.Errorf("owner cannot be empty")
+	}
+	if r.DueDate != nil {
+		return validateDueDate(*r.DueDate)
+	}
+	return nil
+}
+
+func validateDueDate(dueDate string) error {
+	if dueDate == "" {
+		return nil
+	}
+	if _, err := time.Parse(dueDateLayout, dueDate); err != nil {
+		return fmt.Errorf("dueDate must be YYYY-MM-DD")
+	}
+	return nil
+}
+
+// NotesStore persists meeting notes and action items for meetings recorded in MeetingStore
+type NotesStore struct {
+	db *sql.DB
+}
+
+func NewNotesStore(db *sql.DB) *NotesStore {
+	return &NotesStore{db: db}
+}
+
+// GetNotes returns the notes and action items of the meeting created as eventID
+// Meetings without notes yet have empty content and a nil AccessList
+func (s *NotesStore) GetNotes(ctx context.Context, eventID string) (*MeetingNotes, error) {
+	notes := MeetingNotes{EventID: eventID}
+	var accessList []string
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT content, access_list, updated_by, updated_at
+		FROM meeting_notes
+		WHERE event_id = $1
+	`, eventID).Scan(&notes.Content, pq.Array(&accessList), &updatedBy, &updatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	notes.AccessList = accessList
+	notes.UpdatedBy = updatedBy.String
+	if updatedAt.Valid {
+		notes.UpdatedAt = &updatedAt.Time
+	}
+
+	items, err := s.ListActionItems(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	notes.ActionItems = items
+
+	return &notes, nil
+}
+
+// SaveNotes writes the notes content of the meeting created as eventID
+// A nil accessList keeps the stored one; an empty one resets it to the meeting's attendees
+func (s *NotesStore) SaveNotes(ctx context.Context, eventID, content string, accessList *[]string, updatedBy string) (*MeetingNotes, error) {
+	var list interface{}
+	if accessList != nil && len(*accessList) > 0 {
+		lowered := make([]string, 0, len(*accessList))
+		for _, email := range *accessList {
+			lowered = append(lowered, strings.ToLower(strings.TrimSpace(email)))
+		}
+		list = pq.Array(lowered)
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO meeting_notes (event_id, content, access_list, updated_by)
+		VALUES ($1, $2, $3, LOWER($4))
+		ON CONFLICT (event_id) DO UPDATE SET
+			content = EXCLUDED.content,
+			access_list = CASE WHEN $5 THEN EXCLUDED.access_list ELSE meeting_notes.access_list END,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, eventID, content, list, updatedBy, accessList != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetNotes(ctx, eventID)
+}
+
+const actionItemColumns = `a.id, a.event_id, a.description, a.owner_email, a.due_date, a.done,
+	COALESCE(a.created_by, ''), a.created_at, a.updated_at, a.completed_at`
+
+// ListActionItems returns the action items of the meeting created as eventID, oldest first
+func (s *NotesStore) ListActionItems(ctx context.Context, eventID string) ([]ActionItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+actionItemColumns+`
+		FROM action_items a
+		WHERE a.event_id = $1
+		ORDER BY a.created_at ASC
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ActionItem{}
+	for rows.Next() {
+		item, err := scanActionItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// ListUserActionItems returns the action items owned by ownerEmail across all meetings
+// Completed items are only included with includeDone. Items are ordered by due date, undated last
+func (s *NotesStore) ListUserActionItems(ctx context.Context, ownerEmail string, includeDone bool) ([]ActionItem, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+actionItemColumns+`, m.subject, m.start_time
+		FROM action_items a
+		JOIN meetings m ON m.event_id = a.event_id
+		WHERE a.owner_email = LOWER($1) AND ($2 OR NOT a.done)
+		ORDER BY a.due_date ASC NULLS LAST, m.start_time ASC
+	`, ownerEmail, includeDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ActionItem{}
+	for rows.Next() {
+		var subject string
+		var start time.Time
+		item, err := scanActionItem(rows, &subject, &start)
+		if err != nil {
+			return nil, err
+		}
+		item.MeetingSubject = subject
+		item.MeetingStart = &start
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// AddActionItem adds an action item to the meeting created as eventID
+func (s *NotesStore) AddActionItem(ctx context.Context, eventID string, req ActionItemRequest, createdBy string) (*ActionItem, error) {
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO action_items AS a (event_id, description, owner_email, due_date, created_by)
+		VALUES ($1, $2, LOWER($3), $4::date, LOWER($5))
+		RETURNING `+actionItemColumns,
+		eventID, req.Description, strings.TrimSpace(req.Owner), nullableDate(req.DueDate), createdBy)
+	return scanActionItem(row)
+}
+
+// UpdateActionItem applies a partial update to an action item of the meeting created as eventID
+func (s *NotesStore) UpdateActionItem(ctx context.Context, eventID, itemID string, req UpdateActionItemRequest) (*ActionItem, error) {
+	if _, err := uuid.Parse(itemID); err != nil {
+		return nil, ErrActionItemNotFound
+	}
+
+	var dueDate interface{}
+	if req.DueDate != nil {
+		dueDate = nullableDate(*req.DueDate)
+	}
+	var owner *string
+	if req.Owner != nil {
+		trimmed := strings.TrimSpace(*req.Owner)
+		owner = &trimmed
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE action_items AS a SET
+			description = COALESCE($3, a.description),
+			owner_email = COALESCE(LOWER($4), a.owner_email),
+			due_date = CASE WHEN $5 THEN $6::date ELSE a.due_date END,
+			done = COALESCE($7::boolean, a.done),
+			completed_at = CASE
+				WHEN $7::boolean IS NULL THEN a.completed_at
+				WHEN $7::boolean THEN COALESCE(a.completed_at, CURRENT_TIMESTAMP)
+				ELSE NULL
+			END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE a.id = $1 AND a.event_id = $2
+		RETURNING `+actionItemColumns,
+		itemID, eventID, req.Description, owner, req.DueDate != nil, dueDate, req.Done)
+	return scanActionItem(row)
+}
+
+// DeleteActionItem removes an action item from the meeting created as eventID
+func (s *NotesStore) DeleteActionItem(ctx context.Context, eventID, itemID string) error {
+	if _, err := uuid.Parse(itemID); err != nil {
+		return ErrActionItemNotFound
+	}
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM action_items WHERE id = $1 AND event_id = $2
+	`, itemID, eventID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrActionItemNotFound
+	}
+	return nil
+}
+
+// scanActionItem reads a row selected with actionItemColumns followed by any extra columns
+func scanActionItem(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*ActionItem, error) {
+	var item ActionItem
+	var dueDate, completedAt sql.NullTime
+	dest := append([]interface{}{&item.ID, &item.EventID, &item.Description, &item.Owner, &dueDate, &item.Done,
+		&item.CreatedBy, &item.CreatedAt, &item.UpdatedAt, &completedAt}, extra...)
+	err := row.Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, ErrActionItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dueDate.Valid {
+		item.DueDate = dueDate.Time.Format(dueDateLayout)
+	}
+	if completedAt.Valid {
+		item.CompletedAt = &completedAt.Time
+	}
+	return &item, nil
+}
+
+// nullableDate converts an empty due date to NULL
+func nullableDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
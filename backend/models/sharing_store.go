@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SharingStore persists per-user calendar sharing levels
+type SharingStore struct {
+	db *sql.DB
+}
+
+func NewSharingStore(db *sql.DB) *SharingStore {
+	return &SharingStore{db: db}
+}
+
+// GetSharingLevel returns the sharing level configured by userEmail
+// The second return value is false when the user has not configured one
+func (s *SharingStore) GetSharingLevel(ctx context.Context, userEmail string) (SharingLevel, bool, error) {
+	var level SharingLevel
+	err := s.db.QueryRowContext(ctx, `
+		SELECT sharing_level FROM user_sharing_settings WHERE user_email = LOWER($1)
+	`, userEmail).Scan(&level)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return level, true, nil
+}
+
+// SetSharingLevel stores the sharing level of userEmail
+func (s *SharingStore) SetSharingLevel(ctx context.Context, userEmail string, level SharingLevel) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_sharing_settings (user_email, sharing_level)
+		VALUES (LOWER($1), $2)
+		ON CONFLICT (user_email) DO UPDATE SET
+			sharing_level = EXCLUDED.sharing_level,
+			updated_at = CURRENT_TIMESTAMP
+	`, userEmail, string(level))
+	return err
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrSubscriptionNotFound is returned when a change notification names an unknown subscription
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// GraphSubscription is a Graph change-notification subscription to one user's events
+type GraphSubscription struct {
+	ID          string    `json:"id"`
+	UserEmail   string    `json:"userEmail"`
+	Resource    string    `json:"resource"`
+	ClientState string    `json:"-"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// GraphNotification is one change notification posted by Graph
+// Lifecycle notifications set LifecycleEvent instead of ChangeType
+type GraphNotification struct {
+	SubscriptionID string `json:"subscriptionId"`
+	ClientState    string `json:"clientState"`
+	ChangeType     string `json:"changeType,omitempty"`
+	LifecycleEvent string `json:"lifecycleEvent,omitempty"`
+	Resource       string `json:"resource,omitempty"`
+	ResourceData   *struct {
+		ID string `json:"id"`
+	} `json:"resourceData,omitempty"`
+}
+
+// GraphNotificationBatch is the body of a change-notification request
+type GraphNotificationBatch struct {
+	Value []GraphNotification `json:"value"`
+}
+
+// NewSubscriptionClientState returns a secret to register as a subscription's clientState
+func NewSubscriptionClientState() string {
+	return generateSecretToken()
+}
+
+// SubscriptionStore persists Graph change-notification subscriptions
+type SubscriptionStore struct {
+	db *sql.DB
+}
+
+func NewSubscriptionStore(db *sql.DB) *SubscriptionStore {
+	return &SubscriptionStore{db: db}
+}
+
+// SaveSubscription stores a subscription, replacing an earlier copy with the same ID
+func (s *SubscriptionStore) SaveSubscription(ctx context.Context, sub GraphSubscription) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO graph_subscriptions (id, user_email, resource, client_state, expires_at)
+		VALUES ($1, LOWER($2), $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			user_email = EXCLUDED.user_email,
+			resource = EXCLUDED.resource,
+			client_state = EXCLUDED.client_state,
+			expires_at = EXCLUDED.expires_at
+	`, sub.ID, sub.UserEmail, sub.Resource, sub.ClientState, sub.ExpiresAt)
+	return err
+}
+
+// GetSubscription returns the subscription with the given Graph ID
+// Returns ErrSubscriptionNotFound if there is none
+func (s *SubscriptionStore) GetSubscription(ctx context.Context, id string) (*GraphSubscription, error) {
+	var sub GraphSubscription
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_email, resource, client_state, expires_at, created_at
+		FROM graph_subscriptions
+		WHERE id = $1
+	`, id).Scan(&sub.ID, &sub.UserEmail, &sub.Resource, &sub.ClientState, &sub.ExpiresAt, &sub.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions returns every stored subscription
+func (s *SubscriptionStore) ListSubscriptions(ctx context.Context) ([]GraphSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_email, resource, client_state, expires_at, created_at
+		FROM graph_subscriptions
+		ORDER BY user_email, expires_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []GraphSubscription
+	for rows.Next() {
+		var sub GraphSubscription
+		if err := rows.Scan(&sub.ID, &sub.UserEmail, &sub.Resource, &sub.ClientState, &sub.ExpiresAt, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// UpdateExpiry records the new expiry of a renewed subscription
+func (s *SubscriptionStore) UpdateExpiry(ctx context.Context, id string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE graph_subscriptions SET expires_at = $2 WHERE id = $1`, id, expiresAt)
+	return err
+}
+
+// DeleteSubscription forgets a subscription
+func (s *SubscriptionStore) DeleteSubscription(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM graph_subscriptions WHERE id = $1`, id)
+	return err
+}
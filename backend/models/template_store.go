@@ -0,0 +1,288 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrTemplateNotFound is returned when a meeting template does not exist or belongs to someone else
+var ErrTemplateNotFound = errors.New("meeting template not found")
+
+// timeOfDayLayout is the format of a template's preferred time-of-day window
+const timeOfDayLayout = "15:04"
+
+// MeetingTemplate is a reusable meeting shape whose fields fill in defaults
+// for FindMeetingTimes and CreateMeeting requests that reference it
+type MeetingTemplate struct {
+	ID              string    `json:"id"`
+	OwnerEmail      string    `json:"ownerEmail"`
+	Name            string    `json:"name"`
+	Subject         string    `json:"subject,omitempty"`
+	DurationMinutes int       `json:"durationMinutes"`
+	Description     string    `json:"description,omitempty"` // Agenda sent with the invite
+	Location        string    `json:"location,omitempty"`
+	IsOnline        bool      `json:"isOnline"`
+	Attendees       []string  `json:"attendees"`
+	BufferMinutes   int       `json:"bufferMinutes"`            // Free time kept before and after the meeting
+	PreferredStart  string    `json:"preferredStart,omitempty"` // Time of day (HH:MM) suggestions should start after
+	PreferredEnd    string    `json:"preferredEnd,omitempty"`   // Time of day (HH:MM) suggestions should end before
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// MeetingTemplateRequest represents a request to create or replace a meeting template
+type MeetingTemplateRequest struct {
+	Name            string   `json:"name" binding:"required"`
+	Subject         string   `json:"subject,omitempty"`
+	DurationMinutes int      `json:"durationMinutes" binding:"required"`
+	Description     string   `json:"description,omitempty"`
+	Location        string   `json:"location,omitempty"`
+	IsOnline        bool     `json:"isOnline"`
+	Attendees       []string `json:"attendees,omitempty"`
+	BufferMinutes   int      `json:"bufferMinutes,omitempty"`
+	PreferredStart  string   `json:"preferredStart,omitempty"`
+	PreferredEnd    string   `json:"preferredEnd,omitempty"`
+}
+
+// Validate checks the duration, buffer and preferred time-of-day window
+func (r MeetingTemplateRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.DurationMinutes <= 0 || r.DurationMinutes > 24*60 {
+		return fmt.Errorf("durationMinutes must be between 1 and 1440")
+	}
+	if r.BufferMinutes < 0 || r.BufferMinutes > 24*60 {
+		return fmt.Errorf("bufferMinutes must be between 0 and 1440")
+	}
+	if (r.PreferredStart == "") != (r.PreferredEnd == "") {
+		return fmt.Errorf("preferredStart and preferredEnd must be set together")
+	}
+	if r.PreferredStart != "" {
+		start, err := time.Parse(timeOfDayLayout, r.PreferredStart)
+		if err != nil {
+			return fmt.Errorf("preferredStart must be HH:MM")
+		}
+		end, err := time.Parse(timeOfDayLayout, r.PreferredEnd)
+		if err != nil {
+			return fmt.Errorf("preferredEnd must be HH:MM")
+		}
+		if !end.After(start) {
+			return fmt.Errorf("preferredEnd must be after preferredStart")
+		}
+	}
+	return nil
+}
+
+// ApplyToCreate fills the empty fields of a CreateMeetingRequest from the template
+// Template attendees are added to the requested ones; End defaults to Start plus the template duration
+func (t *MeetingTemplate) ApplyToCreate(req *CreateMeetingRequest) {
+	if req.Subject == "" {
+		req.Subject = t.Subject
+	}
+	if req.End.IsZero() && !req.Start.IsZero() {
+		req.End = req.Start.Add(time.Duration(t.DurationMinutes) * time.Minute)
+	}
+	if req.Description == "" {
+		req.Description = t.Description
+	}
+	if req.Location == "" {
+		req.Location = t.Location
+	}
+	req.IsOnline = req.IsOnline || t.IsOnline
+	req.Attendees = mergeEmails(req.Attendees, t.Attendees)
+}
+
+// ApplyToFind fills the empty fields of a FindMeetingTimesRequest from the template
+func (t *MeetingTemplate) ApplyToFind(req *FindMeetingTimesRequest) {
+	if req.Duration <= 0 {
+		req.Duration = t.DurationMinutes
+	}
+
+	requested := make([]string, 0, len(req.Attendees))
+	for _, attendee := range req.Attendees {
+		requested = append(requested, attendee.Email)
+	}
+	for _, email := range t.Attendees {
+		if !containsEmail(requested, email) {
+			req.Attendees = append(req.Attendees, AttendeeWithTimezone{Email: email})
+			requested = append(requested, email)
+		}
+	}
+}
+
+// InPreferredWindow reports whether a meeting from start to end falls inside the template's
+// preferred time of day in loc. Templates without a preferred window accept every time
+func (t *MeetingTemplate) InPreferredWindow(start, end time.Time, loc *time.Location) bool {
+	if t.PreferredStart == "" || t.PreferredEnd == "" {
+		return true
+	}
+	from, err1 := time.Parse(timeOfDayLayout, t.PreferredStart)
+	to, err2 := time.Parse(timeOfDayLayout, t.PreferredEnd)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+
+	start = start.In(loc)
+	end = end.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	windowStart := day.Add(time.Duration(from.Hour())*time.Hour + time.Duration(from.Minute())*time.Minute)
+	windowEnd := day.Add(time.Duration(to.Hour())*time.Hour + time.Duration(to.Minute())*time.Minute)
+	return !start.Before(windowStart) && !end.After(windowEnd)
+}
+
+// mergeEmails appends the addresses in extra that are not already in emails (case-insensitive)
+func mergeEmails(emails, extra []string) []string {
+	merged := append([]string{}, emails...)
+	for _, email := range extra {
+		if !containsEmail(merged, email) {
+			merged = append(merged, email)
+		}
+	}
+	return merged
+}
+
+func containsEmail(emails []string, email string) bool {
+	for _, e := range emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateStore persists meeting templates
+type TemplateStore struct {
+	db *sql.DB
+}
+
+func NewTemplateStore(db *sql.DB) *TemplateStore {
+	return &TemplateStore{db: db}
+}
+
+const templateColumns = `id, owner_email, name, subject, duration_minutes, description, location, is_online,
+	attendees, buffer_minutes, preferred_start, preferred_end, created_at, updated_at`
+
+// CreateTemplate stores a new template owned by ownerEmail
+func (s *TemplateStore) CreateTemplate(ctx context.Context, ownerEmail string, req MeetingTemplateRequest) (*MeetingTemplate, error) {
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO meeting_templates (owner_email, name, subject, duration_minutes, description, location,
+		                               is_online, attendees, buffer_minutes, preferred_start, preferred_end)
+		VALUES (LOWER($1), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+templateColumns,
+		ownerEmail, req.Name, req.Subject, req.DurationMinutes, req.Description, req.Location,
+		req.IsOnline, pq.Array(nonNilEmails(req.Attendees)), req.BufferMinutes, req.PreferredStart, req.PreferredEnd)
+	return scanTemplate(row)
+}
+
+// ListTemplates returns the templates owned by ownerEmail ordered by name
+func (s *TemplateStore) ListTemplates(ctx context.Context, ownerEmail string) ([]MeetingTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+templateColumns+`
+		FROM meeting_templates
+		WHERE owner_email = LOWER($1)
+		ORDER BY name
+	`, ownerEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []MeetingTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+// GetTemplate returns a template owned by ownerEmail
+func (s *TemplateStore) GetTemplate(ctx context.Context, ownerEmail, id string) (*MeetingTemplate, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrTemplateNotFound
+	}
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+templateColumns+`
+		FROM meeting_templates
+		WHERE id = $1 AND owner_email = LOWER($2)
+	`, id, ownerEmail)
+	return scanTemplate(row)
+}
+
+// UpdateTemplate replaces every field of a template owned by ownerEmail
+func (s *TemplateStore) UpdateTemplate(ctx context.Context, ownerEmail, id string, req MeetingTemplateRequest) (*MeetingTemplate, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrTemplateNotFound
+	}
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE meeting_templates SET
+			name = $3,
+			subject = $4,
+			duration_minutes = $5,
+			description = $6,
+			location = $7,
+			is_online = $8,
+			attendees = $9,
+			buffer_minutes = $10,
+			preferred_start = $11,
+			preferred_end = $12,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND owner_email = LOWER($2)
+		RETURNING `+templateColumns,
+		id, ownerEmail, req.Name, req.Subject, req.DurationMinutes, req.Description, req.Location,
+		req.IsOnline, pq.Array(nonNilEmails(req.Attendees)), req.BufferMinutes, req.PreferredStart, req.PreferredEnd)
+	return scanTemplate(row)
+}
+
+// DeleteTemplate removes a template owned by ownerEmail
+func (s *TemplateStore) DeleteTemplate(ctx context.Context, ownerEmail, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrTemplateNotFound
+	}
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM meeting_templates WHERE id = $1 AND owner_email = LOWER($2)
+	`, id, ownerEmail)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// scanTemplate reads a row selected with templateColumns
+func scanTemplate(row interface{ Scan(...interface{}) error }) (*MeetingTemplate, error) {
+	var t MeetingTemplate
+	err := row.Scan(&t.ID, &t.OwnerEmail, &t.Name, &t.Subject, &t.DurationMinutes, &t.Description, &t.Location,
+		&t.IsOnline, pq.Array(&t.Attendees), &t.BufferMinutes, &t.PreferredStart, &t.PreferredEnd,
+		&t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.Attendees == nil {
+		t.Attendees = []string{}
+	}
+	return &t, nil
+}
+
+// nonNilEmails stores an empty array rather than NULL for templates without attendees
+func nonNilEmails(emails []string) []string {
+	if emails == nil {
+		return []string{}
+	}
+	return emails
+}
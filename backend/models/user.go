@@ -1,11 +1,17 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// directorySyncKey is the sync_state key of the /users/delta sync
+const directorySyncKey = "directory:users"
+
 type User struct {
 	ID                string `json:"id" db:"id"`
 	DisplayName       string `json:"displayName" db:"display_name"`
@@ -54,8 +60,8 @@ func (s *UserStore) GetDB() *sql.DB {
 }
 
 // UpsertUser updates or inserts a single user within a transaction
-func (s *UserStore) UpsertUser(tx *sql.Tx, user MSUser) error {
-	_, err := tx.Exec(`
+func (s *UserStore) UpsertUser(ctx context.Context, tx *sql.Tx, user MSUser) error {
+	_, err := tx.ExecContext(ctx, `
 		INSERT INTO users (id, display_name, email, user_principal_name, last_synced, raw_json, timezone)
 		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, $5, $6)
 		ON CONFLICT (id) DO UPDATE SET
@@ -70,15 +76,15 @@ func (s *UserStore) UpsertUser(tx *sql.Tx, user MSUser) error {
 }
 
 // UpsertUsers updates or inserts multiple users
-func (s *UserStore) UpsertUsers(users []MSUser) error {
-	tx, err := s.db.Begin()
+func (s *UserStore) UpsertUsers(ctx context.Context, users []MSUser) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
 	for _, user := range users {
-		if err := s.UpsertUser(tx, user); err != nil {
+		if err := s.UpsertUser(ctx, tx, user); err != nil {
 			return err
 		}
 	}
@@ -87,12 +93,12 @@ func (s *UserStore) UpsertUsers(users []MSUser) error {
 }
 
 // SearchUsers searches for users by name or email with smart matching
-func (s *UserStore) SearchUsers(query string) ([]MSUser, error) {
+func (s *UserStore) SearchUsers(ctx context.Context, query string) ([]MSUser, error) {
 	// Add % at the end for partial matching, but also check for exact start of words
 	startsWithPattern := query + "%"
 	containsPattern := "%" + query + "%"
 
-	rows, err := s.db.Query(`
+	rows, err := s.db.QueryContext(ctx, `
 		WITH RankedUsers AS (
 			SELECT 
 				id, 
@@ -116,13 +122,14 @@ func (s *UserStore) SearchUsers(query string) ([]MSUser, error) {
 					SIMILARITY(LOWER(email), LOWER($3))
 				) as similarity_score
 			FROM users
-			WHERE 
+			WHERE deleted_at IS NULL AND (
 				display_name ILIKE $2 OR
 				email ILIKE $2 OR
 				user_principal_name ILIKE $2 OR
 				-- Add fuzzy matching using pg_trgm
 				similarity(LOWER(display_name), LOWER($3)) > 0.3 OR
 				similarity(LOWER(email), LOWER($3)) > 0.3
+			)
 		)
 		SELECT 
 			id, 
@@ -157,8 +164,8 @@ func (s *UserStore) SearchUsers(query string) ([]MSUser, error) {
 }
 
 // GetAllUsers retrieves all users from the database
-func (s *UserStore) GetAllUsers() ([]MSUser, error) {
-	rows, err := s.db.Query(`
+func (s *UserStore) GetAllUsers(ctx context.Context) ([]MSUser, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT 
 			id, 
 			display_name, 
@@ -167,6 +174,7 @@ func (s *UserStore) GetAllUsers() ([]MSUser, error) {
 			last_synced,
 			timezone
 		FROM users
+		WHERE deleted_at IS NULL
 		ORDER BY display_name ASC
 	`)
 
@@ -189,8 +197,8 @@ func (s *UserStore) GetAllUsers() ([]MSUser, error) {
 }
 
 // GetUserByID retrieves a specific user by their ID
-func (s *UserStore) GetUserByID(userID string) (*MSUser, error) {
-	row := s.db.QueryRow(`
+func (s *UserStore) GetUserByID(ctx context.Context, userID string) (*MSUser, error) {
+	row := s.db.QueryRowContext(ctx, `
 		SELECT 
 			id, 
 			display_name, 
@@ -210,3 +218,157 @@ func (s *UserStore) GetUserByID(userID string) (*MSUser, error) {
 
 	return &user, nil
 }
+
+// GetTimeZone returns the IANA time zone stored for the user with the given email
+// Returns an empty string when the user is unknown or has no time zone
+func (s *UserStore) GetTimeZone(ctx context.Context, email string) (string, error) {
+	var timeZone sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT timezone FROM users
+		WHERE LOWER(email) = LOWER($1) OR LOWER(user_principal_name) = LOWER($1)
+		LIMIT 1
+	`, email).Scan(&timeZone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return timeZone.String, nil
+}
+
+// ApplyDirectoryChanges stores users reported changed by a /users/delta query
+// Delta pages may carry only the properties that changed, so missing values keep what is stored
+// and raw_json is merged. last_synced is set to syncedAt. Returns how many users were stored;
+// new users without a mail or user principal name are skipped
+func (s *UserStore) ApplyDirectoryChanges(ctx context.Context, users []MSUser, syncedAt time.Time) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stored := 0
+	for _, user := range users {
+		rawJSON := user.RawJSON
+		if len(rawJSON) == 0 {
+			rawJSON = json.RawMessage("{}")
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE users SET
+				display_name = COALESCE(NULLIF($2, ''), display_name),
+				email = COALESCE(NULLIF($3, ''), email),
+				user_principal_name = COALESCE(NULLIF($4, ''), user_principal_name),
+				timezone = COALESCE(NULLIF($5, ''), timezone),
+				raw_json = COALESCE(raw_json, '{}'::jsonb) || $6::jsonb,
+				last_synced = $7,
+				deleted_at = NULL
+			WHERE id = $1
+		`, user.ID, user.DisplayName, user.Email, user.UserPrincipalName, user.TimeZone, string(rawJSON), syncedAt)
+		if err != nil {
+			return stored, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			stored++
+			continue
+		}
+
+		email := user.Email
+		if email == "" {
+			email = user.UserPrincipalName
+		}
+		if email == "" || user.UserPrincipalName == "" {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO users (id, display_name, email, user_principal_name, last_synced, raw_json, timezone)
+			VALUES ($1, $2, $3, $4, $7, $5::jsonb, NULLIF($6, ''))
+		`, user.ID, user.DisplayName, email, user.UserPrincipalName, string(rawJSON), user.TimeZone, syncedAt)
+		if err != nil {
+			return stored, err
+		}
+		stored++
+	}
+
+	return stored, tx.Commit()
+}
+
+// SoftDeleteUsers hides users removed from the directory; they are restored if they reappear
+func (s *UserStore) SoftDeleteUsers(ctx context.Context, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE users SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// SoftDeleteUsersSyncedBefore hides users a full directory sync did not return
+func (s *UserStore) SoftDeleteUsersSyncedBefore(ctx context.Context, t time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE users SET deleted_at = CURRENT_TIMESTAMP
+		WHERE deleted_at IS NULL AND (last_synced IS NULL OR last_synced < $1)
+	`, t)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// CountUsers returns how many users are active and how many are soft-deleted
+func (s *UserStore) CountUsers(ctx context.Context) (active int, deleted int, err error) {
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE deleted_at IS NULL), COUNT(*) FILTER (WHERE deleted_at IS NOT NULL)
+		FROM users
+	`).Scan(&active, &deleted)
+	return active, deleted, err
+}
+
+// DirectorySyncState is where the /users/delta sync left off and what its last run did
+type DirectorySyncState struct {
+	DeltaLink   string    `json:"deltaLink,omitempty"` // Empty until a full sync completes
+	LastSynced  time.Time `json:"lastSynced"`
+	LastFull    time.Time `json:"lastFullSync"`
+	LastChanged int       `json:"lastChanged"` // Users added or updated by the last run
+	LastRemoved int       `json:"lastRemoved"` // Users soft-deleted by the last run
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// GetDirectorySyncState returns the state of the directory sync; zero if it never ran
+func (s *UserStore) GetDirectorySyncState(ctx context.Context) (DirectorySyncState, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM sync_state WHERE key = $1`, directorySyncKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return DirectorySyncState{}, nil
+	}
+	if err != nil {
+		return DirectorySyncState{}, err
+	}
+
+	var state DirectorySyncState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return DirectorySyncState{}, err
+	}
+	return state, nil
+}
+
+// SaveDirectorySyncState stores the state of the directory sync
+func (s *UserStore) SaveDirectorySyncState(ctx context.Context, state DirectorySyncState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO sync_state (key, value, last_updated)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, last_updated = CURRENT_TIMESTAMP
+	`, directorySyncKey, string(value))
+	return err
+}
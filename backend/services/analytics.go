@@ -0,0 +1,331 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backToBackGap is the longest break between two meetings that still counts as back-to-back
+const backToBackGap = 5 * time.Minute
+
+// MeetingAnalyticsInput is one user's calendar as read for meeting analytics
+type MeetingAnalyticsInput struct {
+	UserEmail string
+	Events    []models.Event
+	Location  *time.Location // Zone for working hours and week boundaries; defaults to UTC
+}
+
+// meetingTally accumulates the raw values behind models.MeetingStats so tallies can be added up
+type meetingTally struct {
+	meetings     int
+	minutes      float64
+	outside      float64
+	sized        int // Meetings whose attendees are visible
+	participants int // Participants across sized meetings
+	streaks      int
+	longest      int
+}
+
+func (t *meetingTally) add(other meetingTally) {
+	t.meetings += other.meetings
+	t.minutes += other.minutes
+	t.outside += other.outside
+	t.sized += other.sized
+	t.participants += other.participants
+	t.streaks += other.streaks
+	if other.longest > t.longest {
+		t.longest = other.longest
+	}
+}
+
+func (t meetingTally) stats() models.MeetingStats {
+	stats := models.MeetingStats{
+		MeetingCount:        t.meetings,
+		TotalHours:          roundHundredths(t.minutes / 60),
+		OutsideWorkingHours: roundHundredths(t.outside / 60),
+		BackToBackStreaks:   t.streaks,
+		LongestStreak:       t.longest,
+	}
+	if t.sized > 0 {
+		stats.AverageSize = roundHundredths(float64(t.participants) / float64(t.sized))
+	}
+	return stats
+}
+
+// userMeetingTally is the analytics of one user before it is rendered
+type userMeetingTally struct {
+	totals        meetingTally
+	weeks         map[string]*meetingTally
+	collaborators map[string]*models.Collaborator
+}
+
+// AnalyzeMeetings computes meeting analytics for each input over [start, end), and for the
+// team as a whole when there is more than one. Meetings are timed events that block their
+// owner's time; top limits the collaborators listed
+func AnalyzeMeetings(inputs []MeetingAnalyticsInput, start, end time.Time, top int) models.MeetingAnalyticsResponse {
+	response := models.MeetingAnalyticsResponse{
+		StartTime: start,
+		EndTime:   end,
+		Users:     make([]models.UserMeetingAnalytics, 0, len(inputs)),
+	}
+
+	team := userMeetingTally{
+		weeks:         map[string]*meetingTally{},
+		collaborators: map[string]*models.Collaborator{},
+	}
+	members := map[string]bool{}
+	for _, input := range inputs {
+		members[strings.ToLower(input.UserEmail)] = true
+	}
+
+	for _, input := range inputs {
+		loc := input.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		tally := tallyMeetings(input.UserEmail, input.Events, start, end, loc)
+		response.Users = append(response.Users, models.UserMeetingAnalytics{
+			UserEmail:        input.UserEmail,
+			TimeZone:         loc.String(),
+			Totals:           tally.totals.stats(),
+			Weekly:           weeklyStats(tally.weeks),
+			TopCollaborators: topCollaborators(tally.collaborators, nil, top),
+		})
+
+		team.totals.add(tally.totals)
+		for key, week := range tally.weeks {
+			if team.weeks[key] == nil {
+				team.weeks[key] = &meetingTally{}
+			}
+			team.weeks[key].add(*week)
+		}
+		for email, collaborator := range tally.collaborators {
+			merged := team.collaborators[email]
+			if merged == nil {
+				merged = &models.Collaborator{Email: collaborator.Email}
+				team.collaborators[email] = merged
+			}
+			merged.Meetings += collaborator.Meetings
+			merged.Hours += collaborator.Hours
+		}
+	}
+
+	if len(inputs) > 1 {
+		response.Team = &models.TeamMeetingAnalytics{
+			Totals:           team.totals.stats(),
+			Weekly:           weeklyStats(team.weeks),
+			TopCollaborators: topCollaborators(team.collaborators, members, top),
+		}
+	}
+	return response
+}
+
+// tallyMeetings tallies owner's meetings in [start, end) by the week, in loc, they start in
+func tallyMeetings(owner string, events []models.Event, start, end time.Time, loc *time.Location) userMeetingTally {
+	tally := userMeetingTally{
+		weeks:         map[string]*meetingTally{},
+		collaborators: map[string]*models.Collaborator{},
+	}
+	for week := weekStart(start, loc); week.Before(end); week = week.AddDate(0, 0, 7) {
+		tally.weeks[week.Format("2006-01-02")] = &meetingTally{}
+	}
+	week := func(t time.Time) *meetingTally {
+		key := weekStart(t, loc).Format("2006-01-02")
+		if tally.weeks[key] == nil {
+			tally.weeks[key] = &meetingTally{}
+		}
+		return tally.weeks[key]
+	}
+
+	meetings := analyticsMeetings(events, start, end)
+	busy := map[*meetingTally][]models.TimeSlot{}
+	for _, meeting := range meetings {
+		w := week(meeting.Start)
+		w.meetings++
+		busy[w] = append(busy[w], models.TimeSlot{Start: meeting.Start, End: meeting.End})
+
+		others, visible := meetingParticipants(meeting, owner)
+		if !visible {
+			continue
+		}
+		w.sized++
+		w.participants += len(others) + 1
+		hours := meeting.End.Sub(meeting.Start).Hours()
+		for _, email := range others {
+			collaborator := tally.collaborators[strings.ToLower(email)]
+			if collaborator == nil {
+				collaborator = &models.Collaborator{Email: email}
+				tally.collaborators[strings.ToLower(email)] = collaborator
+			}
+			collaborator.Meetings++
+			collaborator.Hours += hours
+		}
+	}
+
+	// Overlapping meetings count once towards hours, split by working hours in loc
+	for w, slots := range busy {
+		merged := mergeTimeSlots(slots)
+		standard := filterByWorkingHours(merged, loc.String()).Standard
+		w.minutes = slotMinutes(merged)
+		w.outside = w.minutes - slotMinutes(standard)
+	}
+
+	// Streaks are credited to the week of their first meeting
+	runLength := 0
+	var runFirst, runEnd time.Time
+	closeRun := func() {
+		if runLength < 2 {
+			return
+		}
+		w := week(runFirst)
+		w.streaks++
+		if runLength > w.longest {
+			w.longest = runLength
+		}
+	}
+	for i, meeting := range meetings {
+		if i > 0 && !meeting.Start.After(runEnd.Add(backToBackGap)) {
+			runLength++
+		} else {
+			closeRun()
+			runLength = 1
+			runFirst = meeting.Start
+			runEnd = meeting.End
+		}
+		if meeting.End.After(runEnd) {
+			runEnd = meeting.End
+		}
+	}
+	closeRun()
+
+	for _, w := range tally.weeks {
+		tally.totals.add(*w)
+	}
+	return tally
+}
+
+// analyticsMeetings returns the timed, time-blocking events in [start, end), clipped to it and
+// sorted by start
+func analyticsMeetings(events []models.Event, start, end time.Time) []models.Event {
+	meetings := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		if event.IsAllDay || !event.ShowAs.BlocksTime() {
+			continue
+		}
+		if event.Start.Before(start) {
+			event.Start = start
+		}
+		if event.End.After(end) {
+			event.End = end
+		}
+		if event.End.After(event.Start) {
+			meetings = append(meetings, event)
+		}
+	}
+	sort.SliceStable(meetings, func(i, j int) bool { return meetings[i].Start.Before(meetings[j].Start) })
+	return meetings
+}
+
+// meetingParticipants lists the organizer and attendees of event other than owner
+// Reports false when the attendees are hidden from the caller by the owner's sharing level
+func meetingParticipants(event models.Event, owner string) ([]string, bool) {
+	if len(event.Attendees) == 0 {
+		return nil, false
+	}
+
+	seen := map[string]bool{strings.ToLower(owner): true}
+	var others []string
+	for _, email := range append([]string{event.Organizer}, event.Attendees...) {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		others = append(others, email)
+	}
+	return others, true
+}
+
+// weeklyStats renders week tallies in date order
+func weeklyStats(weeks map[string]*meetingTally) []models.WeeklyMeetingStats {
+	keys := make([]string, 0, len(weeks))
+	for key := range weeks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stats := make([]models.WeeklyMeetingStats, 0, len(keys))
+	for _, key := range keys {
+		stats = append(stats, models.WeeklyMeetingStats{WeekStart: key, MeetingStats: weeks[key].stats()})
+	}
+	return stats
+}
+
+// topCollaborators returns up to top collaborators by shared meetings, then hours, leaving out exclude
+func topCollaborators(collaborators map[string]*models.Collaborator, exclude map[string]bool, top int) []models.Collaborator {
+	list := make([]models.Collaborator, 0, len(collaborators))
+	for key, collaborator := range collaborators {
+		if exclude[key] {
+			continue
+		}
+		c := *collaborator
+		c.Hours = roundHundredths(c.Hours)
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Meetings != list[j].Meetings {
+			return list[i].Meetings > list[j].Meetings
+		}
+		if list[i].Hours != list[j].Hours {
+			return list[i].Hours > list[j].Hours
+		}
+		return list[i].Email < list[j].Email
+	})
+	if top >= 0 && len(list) > top {
+		list = list[:top]
+	}
+	return list
+}
+
+// weekStart returns midnight on the Monday of t's week in loc
+func weekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// mergeTimeSlots sorts slots and joins those that overlap or touch
+func mergeTimeSlots(slots []models.TimeSlot) []models.TimeSlot {
+	sorted := append([]models.TimeSlot(nil), slots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []models.TimeSlot
+	for _, slot := range sorted {
+		if n := len(merged); n > 0 && !slot.Start.After(merged[n-1].End) {
+			if slot.End.After(merged[n-1].End) {
+				merged[n-1].End = slot.End
+			}
+			continue
+		}
+		merged = append(merged, slot)
+	}
+	return merged
+}
+
+// slotMinutes is the total length of slots in minutes
+func slotMinutes(slots []models.TimeSlot) float64 {
+	minutes := 0.0
+	for _, slot := range slots {
+		minutes += slot.End.Sub(slot.Start).Minutes()
+	}
+	return minutes
+}
+
+// roundHundredths rounds to two decimal places for display
+func roundHundredths(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,756 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/utils"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// calDAVTimeFormat is the UTC form of time-range bounds and of the times the client writes
+const calDAVTimeFormat = "20060102T150405Z"
+
+// calDAVCalendarQuery asks for the etag and iCalendar data of every event overlapping a time range
+const calDAVCalendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT"><C:time-range start="%s" end="%s"/></C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// calDAVFreeBusyQuery asks for the busy periods of a calendar within a time range
+const calDAVFreeBusyQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:free-busy-query xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <C:time-range start="%s" end="%s"/>
+</C:free-busy-query>`
+
+// CalDAVClient implements GraphClient against a CalDAV server (RFC 4791)
+// Each user's calendar collection is found by filling their email into a URL template. Event IDs
+// are the names of the calendar object resources; occurrences of recurring events get the start of
+// the occurrence appended and cannot be changed through the scheduler
+type CalDAVClient struct {
+	HTTPClient  *http.Client
+	CalendarURL string // Template of a user's calendar collection, with {email} or {user}
+	Username    string // Basic auth account with access to every user's calendar
+	Password    string
+	Config      *config.Config
+}
+
+// NewCalDAVClient creates a CalDAVClient for the calendars at calendarURL
+// Throttled requests are retried under the CALDAV_ retry settings and counted apart from Graph's
+func NewCalDAVClient(calendarURL, username, password string, cfg *config.Config) *CalDAVClient {
+	return &CalDAVClient{
+		HTTPClient:  &http.Client{Transport: utils.NewRetryTransport(nil, utils.RetryServiceCalDAV)},
+		CalendarURL: calendarURL,
+		Username:    username,
+		Password:    password,
+		Config:      cfg,
+	}
+}
+
+// NewCalDAVClientFromEnv creates a CalDAVClient from environment variables
+// Without CALDAV_CALENDAR_URL every call fails, as the Gmail sender does without GMAIL_EMAIL
+// - CALDAV_CALENDAR_URL: calendar collection of a user, with {email} or {user} (the part before @)
+// - CALDAV_USERNAME, CALDAV_PASSWORD: basic auth account allowed to read and write those calendars
+func NewCalDAVClientFromEnv(cfg *config.Config) *CalDAVClient {
+	calendarURL := os.Getenv("CALDAV_CALENDAR_URL")
+	if calendarURL == "" {
+		log.Println("WARNING: CALDAV_CALENDAR_URL not set")
+	}
+	return NewCalDAVClient(calendarURL, os.Getenv("CALDAV_USERNAME"), os.Getenv("CALDAV_PASSWORD"), cfg)
+}
+
+// GetCalendarView retrieves calendar events for a user within a time range
+func (c *CalDAVClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	events, truncated, err := c.GetUserEvents(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get calendar view: %v", err)
+	}
+	return events, truncated, nil
+}
+
+// GetUserEvents retrieves all events for a user within a time range with a calendar-query REPORT
+// Recurring events are expanded here rather than by the server; at most GRAPH_MAX_ITEMS events are
+// returned and the flag reports that the rest were dropped
+func (c *CalDAVClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	calendarURL, err := c.calendarURL(userEmail)
+	if err != nil {
+		return nil, false, err
+	}
+
+	body := fmt.Sprintf(calDAVCalendarQuery, startTime.UTC().Format(calDAVTimeFormat), endTime.UTC().Format(calDAVTimeFormat))
+	resp, err := c.do(ctx, "REPORT", calendarURL, strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user events for %s: %v", userEmail, err)
+	}
+	defer resp.Body.Close()
+
+	var multistatus calDAVMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return nil, false, fmt.Errorf("failed to parse calendar-query response: %v", err)
+	}
+
+	loc := c.userLocation(ctx, userEmail)
+	events := []models.Event{}
+	for _, response := range multistatus.Responses {
+		data := response.calendarData()
+		if data == "" {
+			continue
+		}
+		name := calDAVResourceName(response.Href)
+		occurrences, warnings, err := ParseICSEvents(strings.NewReader(data), startTime, endTime)
+		if err != nil {
+			log.Printf("Warning: Skipping calendar object %s of %s: %v", name, userEmail, err)
+			continue
+		}
+		for _, warning := range warnings {
+			log.Printf("Warning: Calendar object %s of %s: %s", name, userEmail, warning)
+		}
+
+		for _, occurrence := range occurrences {
+			event := occurrence.Event
+			event.ID = name
+			if !occurrence.RecurrenceID.IsZero() {
+				event.ID += "_" + occurrence.RecurrenceID.Format(calDAVTimeFormat)
+			}
+			event.ICalUID = occurrence.UID
+			if event.Attendees == nil {
+				event.Attendees = []string{}
+			}
+			if event.IsAllDay {
+				event = AnchorAllDay(event, loc)
+			} else {
+				event.Start, event.End = event.Start.UTC(), event.End.UTC()
+			}
+			// The server matches whole series; only occurrences overlapping the range are kept
+			if !event.Start.Before(endTime) || !event.End.After(startTime) {
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	if maxItems := GraphPagingFromEnv().MaxItems; len(events) > maxItems {
+		return events[:maxItems], true, nil
+	}
+	return events, false, nil
+}
+
+// GetSchedules reads the free/busy schedules of users with a free-busy-query REPORT on each calendar
+// Working hours are assumed to be 9am-6pm on weekdays in each user's own time zone
+func (c *CalDAVClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	interval = scheduleInterval(interval)
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		schedule := models.Schedule{
+			UserEmail:    email,
+			Items:        []models.ScheduleItem{},
+			WorkingHours: standardWorkingHours(c.userLocation(ctx, email)),
+		}
+
+		items, err := c.freeBusy(ctx, email, startTime, endTime)
+		var davErr *CalDAVError
+		switch {
+		case errors.As(err, &davErr):
+			// A calendar that cannot be read does not fail the others
+			schedule.Error = davErr.Error()
+		case err != nil:
+			return nil, fmt.Errorf("failed to get schedules: %v", err)
+		default:
+			schedule.Items = items
+			schedule.AvailabilityView = availabilityView(items, startTime, endTime, interval)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// freeBusy reads the busy periods of userEmail's calendar from its VFREEBUSY
+func (c *CalDAVClient) freeBusy(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.ScheduleItem, error) {
+	calendarURL, err := c.calendarURL(userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf(calDAVFreeBusyQuery, startTime.UTC().Format(calDAVTimeFormat), endTime.UTC().Format(calDAVTimeFormat))
+	resp, err := c.do(ctx, "REPORT", calendarURL, strings.NewReader(body), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	calendar, err := parseICalendar(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse free-busy-query response: %v", err)
+	}
+
+	items := []models.ScheduleItem{}
+	for _, vfreebusy := range calendar.Components("VFREEBUSY") {
+		for _, prop := range vfreebusy.All("FREEBUSY") {
+			status, busy := calDAVFreeBusyStatus(prop.Params["FBTYPE"])
+			if !busy {
+				continue
+			}
+			for _, value := range strings.Split(prop.Value, ",") {
+				start, end, err := parseICalPeriod(value)
+				if err != nil {
+					log.Printf("Warning: Skipping busy period of %s: %v", userEmail, err)
+					continue
+				}
+				items = append(items, models.ScheduleItem{Start: start, End: end, Status: status})
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Start.Before(items[j].Start) })
+	return items, nil
+}
+
+// FindMeetingTimes suggests times when the organizer and every attendee are free
+// CalDAV has no equivalent of findMeetingTimes, so the suggestions come from their free/busy schedules
+func (c *CalDAVClient) FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+	schedules, err := c.GetSchedules(ctx, append([]string{organizer}, attendees...), startTime, endTime, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find meeting times: %v", err)
+	}
+
+	busySlots := make(map[string][]models.TimeSlot)
+	for _, schedule := range schedules {
+		if schedule.Error != "" {
+			continue // Skip calendars that cannot be read, as the mock skips unknown users
+		}
+		busySlots[schedule.UserEmail] = ScheduleBusyIntervals(schedule)
+	}
+	return commonFreeSlots(busySlots, startTime, endTime, duration), nil
+}
+
+// CreateOnlineMeeting creates a calendar event with a meeting link
+func (c *CalDAVClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+	return c.CreateCalendarEvent(ctx, organizer, models.CreateMeetingRequest{
+		Subject:   subject,
+		Start:     start,
+		End:       end,
+		Attendees: attendees,
+		IsOnline:  true,
+	})
+}
+
+// CreateCalendarEvent PUTs a new iCalendar object into the organizer's calendar
+// CalDAV servers do not generally email attendees, so invitations go out through the invite sender.
+// Online meetings get a link under TEAMS_MEETING_BASE_URL, as in mock mode
+func (c *CalDAVClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	calendarURL, err := c.calendarURL(organizer)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	eventID := uuid.New().String()
+	onlineURL := ""
+	if event.IsOnline && c.Config != nil && c.Config.TeamsMeetingBaseURL != "" {
+		onlineURL = fmt.Sprintf("%s/%s", strings.TrimRight(c.Config.TeamsMeetingBaseURL, "/"), eventID)
+	}
+	sensitivity := event.Sensitivity
+	if sensitivity == "" {
+		sensitivity = models.SensitivityNormal
+	}
+
+	class := "PUBLIC"
+	if sensitivity == models.SensitivityPrivate || sensitivity == models.SensitivityConfidential {
+		class = strings.ToUpper(string(sensitivity))
+	}
+	vevent := &icalComponent{Name: "VEVENT", Properties: []icalProperty{
+		// Same UID as the emailed invite so clients can relate the two
+		{Name: "UID", Value: InviteUID(eventID)},
+		{Name: "DTSTAMP", Value: time.Now().UTC().Format(calDAVTimeFormat)},
+		{Name: "DTSTART", Value: event.Start.UTC().Format(calDAVTimeFormat)},
+		{Name: "DTEND", Value: event.End.UTC().Format(calDAVTimeFormat)},
+		{Name: "SUMMARY", Value: escapeICalText(event.Subject)},
+		{Name: "ORGANIZER", Value: "mailto:" + organizer},
+	}}
+	if event.Description != "" {
+		vevent.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(event.Description)})
+	}
+	if event.Location != "" {
+		vevent.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(event.Location)})
+	}
+	for _, email := range event.Attendees {
+		vevent.Properties = append(vevent.Properties, calDAVAttendee(email))
+	}
+	if onlineURL != "" {
+		vevent.Set(icalProperty{Name: "CONFERENCE", Params: map[string]string{"VALUE": "URI"}, Value: onlineURL})
+	}
+	vevent.Properties = append(vevent.Properties,
+		icalProperty{Name: "STATUS", Value: "CONFIRMED"},
+		icalProperty{Name: "SEQUENCE", Value: "0"},
+		icalProperty{Name: "CLASS", Value: class},
+		icalProperty{Name: "TRANSP", Value: "OPAQUE"},
+	)
+
+	// If-None-Match keeps an existing object from being overwritten
+	if _, err := c.put(ctx, calendarURL+calDAVObjectName(eventID), newCalDAVCalendar(vevent), map[string]string{"If-None-Match": "*"}); err != nil {
+		return models.Event{}, fmt.Errorf("failed to create calendar event: %v", err)
+	}
+
+	return models.Event{
+		ID:          eventID,
+		Subject:     event.Subject,
+		Start:       event.Start,
+		End:         event.End,
+		Organizer:   organizer,
+		Attendees:   event.Attendees,
+		Location:    event.Location,
+		OnlineURL:   onlineURL,
+		BodyPreview: event.Description,
+		IsOnline:    onlineURL != "",
+		Sensitivity: sensitivity,
+		ShowAs:      models.ShowAsBusy,
+		ICalUID:     InviteUID(eventID),
+	}, nil
+}
+
+// UpdateCalendarEvent applies a partial update to an event in the organizer's calendar and bumps its sequence
+// Only the changed properties are rewritten, so attendee responses and alarms are kept
+func (c *CalDAVClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	object, err := c.getOwnedEvent(ctx, organizer, eventID)
+	if err != nil {
+		return models.Event{}, err
+	}
+	vevent := object.master
+
+	if update.Subject != nil {
+		vevent.Set(icalProperty{Name: "SUMMARY", Value: escapeICalText(*update.Subject)})
+	}
+	if update.Start != nil {
+		vevent.Set(icalProperty{Name: "DTSTART", Value: update.Start.UTC().Format(calDAVTimeFormat)})
+	}
+	if update.End != nil {
+		vevent.Remove("DURATION")
+		vevent.Set(icalProperty{Name: "DTEND", Value: update.End.UTC().Format(calDAVTimeFormat)})
+	}
+	if update.Description != nil {
+		vevent.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(*update.Description)})
+	}
+	if update.Location != nil {
+		vevent.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(*update.Location)})
+	}
+	// Replace attendees only when a new list was provided, keeping the responses of those who stay
+	if update.Attendees != nil {
+		existing := map[string]icalProperty{}
+		for _, prop := range vevent.All("ATTENDEE") {
+			existing[icalAddress(prop.Value)] = prop
+		}
+		vevent.Remove("ATTENDEE")
+		for _, email := range update.Attendees {
+			prop, ok := existing[strings.ToLower(email)]
+			if !ok {
+				prop = calDAVAttendee(email)
+			}
+			vevent.Properties = append(vevent.Properties, prop)
+		}
+	}
+
+	sequence, _ := strconv.Atoi(vevent.Value("SEQUENCE"))
+	vevent.Set(icalProperty{Name: "SEQUENCE", Value: strconv.Itoa(sequence + 1)})
+	vevent.Set(icalProperty{Name: "DTSTAMP", Value: time.Now().UTC().Format(calDAVTimeFormat)})
+
+	event, err := object.event(organizer, c.userLocation(ctx, organizer))
+	if err != nil {
+		// The event was read fine before the changes, so they made it invalid
+		return models.Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if !event.End.After(event.Start) {
+		return models.Event{}, fmt.Errorf("%w: end time must be after start time", ErrInvalidEvent)
+	}
+
+	// If-Match refuses the update if the event changed since it was read
+	if _, err := c.put(ctx, object.url, object.calendar, map[string]string{"If-Match": object.etag}); err != nil {
+		return models.Event{}, wrapCalDAVEventError("update calendar event", err)
+	}
+	return event, nil
+}
+
+// CancelCalendarEvent deletes an event from the organizer's calendar and returns it
+func (c *CalDAVClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	object, err := c.getOwnedEvent(ctx, organizer, eventID)
+	if err != nil {
+		return models.Event{}, err
+	}
+	event, err := object.event(organizer, c.userLocation(ctx, organizer))
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, object.url, nil, map[string]string{"If-Match": object.etag})
+	if err != nil {
+		return models.Event{}, wrapCalDAVEventError("cancel calendar event", err)
+	}
+	resp.Body.Close()
+
+	// Cancellation notices must supersede the last update
+	event.Sequence++
+	return event, nil
+}
+
+// GetEventResponses reads the attendees' PARTSTAT from an event in the organizer's calendar
+// CalDAV does not record when attendees responded
+func (c *CalDAVClient) GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	object, err := c.getOwnedEvent(ctx, organizer, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.AttendeeResponse{}
+	for _, prop := range object.master.All("ATTENDEE") {
+		email := icalAddress(prop.Value)
+		if email == "" || strings.EqualFold(email, organizer) {
+			continue
+		}
+		responses = append(responses, models.AttendeeResponse{
+			Email:  email,
+			Status: calDAVResponseStatus(prop.Params["PARTSTAT"]),
+		})
+	}
+	return responses, nil
+}
+
+// GetAvailability checks availability for a user within a time range (UTC working hours)
+func (c *CalDAVClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return c.GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, "")
+}
+
+// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
+// Busy periods come from a free-busy-query, so calendars shared as free/busy only are enough
+func (c *CalDAVClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	items, err := c.freeBusy(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return models.AvailabilityResponse{}, fmt.Errorf("failed to get availability: %v", err)
+	}
+
+	events := make([]models.Event, 0, len(items))
+	for _, item := range items {
+		events = append(events, models.Event{Start: item.Start, End: item.End, ShowAs: item.Status})
+	}
+	return availabilityFromEvents(userEmail, events, startTime, endTime, timezone), nil
+}
+
+// calDAVObject is an event's calendar object resource as read for a change
+type calDAVObject struct {
+	url      string
+	etag     string
+	calendar *icalComponent
+	master   *icalComponent // The VEVENT without RECURRENCE-ID
+	name     string
+}
+
+// event converts the object's master VEVENT, as it will be after any changes made to it
+func (o *calDAVObject) event(organizer string, loc *time.Location) (models.Event, error) {
+	event, _, err := icsToEvent(o.master, newICalTimeZones(o.calendar))
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to read calendar event: %v", err)
+	}
+	event.ID = o.name
+	event.ICalUID = o.master.Value("UID")
+	if event.Organizer == "" {
+		event.Organizer = organizer
+	}
+	if event.Attendees == nil {
+		event.Attendees = []string{}
+	}
+	if event.IsAllDay {
+		event = AnchorAllDay(event, loc)
+	} else {
+		event.Start, event.End = event.Start.UTC(), event.End.UTC()
+	}
+	return event, nil
+}
+
+// getOwnedEvent loads an event from the organizer's calendar and checks that they organize it
+// Events without an ORGANIZER are the calendar owner's own
+func (c *CalDAVClient) getOwnedEvent(ctx context.Context, organizer, eventID string) (*calDAVObject, error) {
+	calendarURL, err := c.calendarURL(organizer)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &calDAVObject{url: calendarURL + calDAVObjectName(eventID), name: eventID}
+	resp, err := c.do(ctx, http.MethodGet, object.url, nil, nil)
+	if err != nil {
+		return nil, wrapCalDAVEventError("load calendar event", err)
+	}
+	defer resp.Body.Close()
+
+	object.etag = resp.Header.Get("ETag")
+	if object.calendar, err = parseICalendar(resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar event: %v", err)
+	}
+	for _, vevent := range object.calendar.Components("VEVENT") {
+		if _, ok := vevent.Get("RECURRENCE-ID"); !ok {
+			object.master = vevent
+			break
+		}
+	}
+	if object.master == nil {
+		return nil, ErrEventNotFound
+	}
+
+	if owner := icalAddress(object.master.Value("ORGANIZER")); owner != "" && !strings.EqualFold(owner, organizer) {
+		return nil, ErrNotOrganizer
+	}
+	return object, nil
+}
+
+// calendarURL fills userEmail into the calendar URL template; the result ends in a slash
+func (c *CalDAVClient) calendarURL(userEmail string) (string, error) {
+	if c.CalendarURL == "" {
+		return "", fmt.Errorf("CalDAV calendar URL not configured (CALDAV_CALENDAR_URL required)")
+	}
+	user, _, _ := strings.Cut(userEmail, "@")
+	calendarURL := strings.NewReplacer(
+		"{email}", url.PathEscape(strings.ToLower(userEmail)),
+		"{user}", url.PathEscape(strings.ToLower(user)),
+	).Replace(c.CalendarURL)
+	if !strings.HasSuffix(calendarURL, "/") {
+		calendarURL += "/"
+	}
+	return calendarURL, nil
+}
+
+// userLocation returns the time zone of userEmail's all-day events
+func (c *CalDAVClient) userLocation(ctx context.Context, userEmail string) *time.Location {
+	var db *sql.DB
+	if c.Config != nil {
+		db = c.Config.DB
+	}
+	return UserLocation(ctx, db, userEmail)
+}
+
+// put writes calendar to the resource at link
+func (c *CalDAVClient) put(ctx context.Context, link string, calendar *icalComponent, headers map[string]string) (*http.Response, error) {
+	var ics strings.Builder
+	writeICalComponent(&ics, calendar)
+
+	headers["Content-Type"] = "text/calendar; charset=utf-8"
+	resp, err := c.do(ctx, http.MethodPut, link, strings.NewReader(ics.String()), headers)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// do sends an authenticated request to the CalDAV server
+// Responses other than 2xx (207 Multi-Status included) are returned as a *CalDAVError
+func (c *CalDAVClient) do(ctx context.Context, method, link string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &CalDAVError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	return resp, nil
+}
+
+// CalDAVError is an error response of the CalDAV server
+type CalDAVError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *CalDAVError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("caldav server returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("caldav server returned %d: %s", e.StatusCode, e.Message)
+}
+
+// wrapCalDAVEventError maps missing events to ErrEventNotFound and responses still throttled
+// after retrying to ErrGraphBusy
+func wrapCalDAVEventError(action string, err error) error {
+	var davErr *CalDAVError
+	if errors.As(err, &davErr) {
+		switch davErr.StatusCode {
+		case http.StatusNotFound, http.StatusGone:
+			return ErrEventNotFound
+		case http.StatusPreconditionFailed:
+			return fmt.Errorf("failed to %s: the event was changed meanwhile, try again", action)
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return fmt.Errorf("failed to %s: %w", action, ErrGraphBusy)
+		}
+	}
+	return fmt.Errorf("failed to %s: %v", action, err)
+}
+
+// calDAVMultistatus is the body of a calendar-query response (RFC 4918 section 13)
+type calDAVMultistatus struct {
+	Responses []calDAVResponse `xml:"DAV: response"`
+}
+
+type calDAVResponse struct {
+	Href     string `xml:"DAV: href"`
+	Propstat []struct {
+		Status string `xml:"DAV: status"`
+		Prop   struct {
+			ETag         string `xml:"DAV: getetag"`
+			CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+		} `xml:"DAV: prop"`
+	} `xml:"DAV: propstat"`
+}
+
+// calendarData returns the iCalendar data of a response's successful propstat, or ""
+func (r calDAVResponse) calendarData() string {
+	for _, propstat := range r.Propstat {
+		if strings.Contains(propstat.Status, " 200 ") && propstat.Prop.CalendarData != "" {
+			return propstat.Prop.CalendarData
+		}
+	}
+	return ""
+}
+
+// calDAVResourceName returns the event ID of the calendar object resource at href
+func calDAVResourceName(href string) string {
+	name := path.Base(strings.TrimRight(href, "/"))
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.TrimSuffix(name, ".ics")
+}
+
+// calDAVObjectName is the resource name of the calendar object for eventID
+func calDAVObjectName(eventID string) string {
+	return url.PathEscape(eventID) + ".ics"
+}
+
+// newCalDAVCalendar wraps vevent in the VCALENDAR of a calendar object resource
+func newCalDAVCalendar(vevent *icalComponent) *icalComponent {
+	return &icalComponent{
+		Name: "VCALENDAR",
+		Properties: []icalProperty{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: "-//Gruve.ai//Smart Meeting Scheduler//EN"},
+			{Name: "CALSCALE", Value: "GREGORIAN"},
+		},
+		Children: []*icalComponent{vevent},
+	}
+}
+
+// calDAVAttendee is the ATTENDEE property of an invited attendee who has not responded yet
+func calDAVAttendee(email string) icalProperty {
+	return icalProperty{
+		Name:   "ATTENDEE",
+		Params: map[string]string{"CN": email, "PARTSTAT": "NEEDS-ACTION", "RSVP": "TRUE"},
+		Value:  "mailto:" + email,
+	}
+}
+
+// calDAVFreeBusyStatus maps a FREEBUSY FBTYPE to a ShowAs; busy is false for free time
+func calDAVFreeBusyStatus(fbtype string) (models.ShowAs, bool) {
+	switch strings.ToUpper(fbtype) {
+	case "FREE":
+		return models.ShowAsFree, false
+	case "BUSY-TENTATIVE":
+		return models.ShowAsTentative, true
+	case "BUSY-UNAVAILABLE":
+		return models.ShowAsOutOfOffice, true
+	default:
+		return models.ShowAsBusy, true
+	}
+}
+
+// calDAVResponseStatus maps an attendee's PARTSTAT to a ResponseStatus
+func calDAVResponseStatus(partstat string) models.ResponseStatus {
+	switch strings.ToUpper(partstat) {
+	case "ACCEPTED":
+		return models.ResponseAccepted
+	case "TENTATIVE":
+		return models.ResponseTentative
+	case "DECLINED":
+		return models.ResponseDeclined
+	default:
+		return models.ResponseNone
+	}
+}
+
+// parseICalPeriod parses a PERIOD value, "start/end" or "start/duration", in UTC
+func parseICalPeriod(value string) (time.Time, time.Time, error) {
+	startValue, endValue, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %q", value)
+	}
+	start, err := time.Parse(calDAVTimeFormat, startValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period start: %q", startValue)
+	}
+	// Date-times never contain the P of a duration
+	if strings.Contains(endValue, "P") {
+		duration, err := parseICalDuration(endValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return start, start.Add(duration), nil
+	}
+	end, err := time.Parse(calDAVTimeFormat, endValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period end: %q", endValue)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// standupICS is a weekly series on Mondays and Wednesdays at 9:00 Berlin time whose
+// Wednesday 4 March occurrence was moved to the afternoon
+const standupICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@gruve.ai\r\n" +
+	"DTSTAMP:20260201T000000Z\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260302T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20260302T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"ORGANIZER:mailto:alice@gruve.ai\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@gruve.ai\r\n" +
+	"DTSTAMP:20260201T000000Z\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20260304T090000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260304T140000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20260304T143000\r\n" +
+	"SUMMARY:Standup (afternoon)\r\n" +
+	"ORGANIZER:mailto:alice@gruve.ai\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+// fakeCalDAVServer is an in-memory CalDAV server with one calendar collection per user under /dav/
+// calendar-query returns every object, as servers match whole series; free-busy-query answers
+// the busy periods set for the calendar
+type fakeCalDAVServer struct {
+	mu       sync.Mutex
+	objects  map[string]string // iCalendar data by path
+	etags    map[string]int
+	freeBusy map[string]string // FREEBUSY lines by calendar path
+	queries  []string          // Time ranges asked for, as "start/end"
+}
+
+func (f *fakeCalDAVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, _ := r.BasicAuth(); user != "scheduler" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case "REPORT":
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Depth") != "1" {
+			http.Error(w, "Depth 1 required", http.StatusBadRequest)
+			return
+		}
+		f.queries = append(f.queries, timeRange(string(body)))
+		switch {
+		case strings.Contains(string(body), "calendar-query"):
+			f.calendarQuery(w, r.URL.Path)
+		case strings.Contains(string(body), "free-busy-query"):
+			w.Header().Set("Content-Type", "text/calendar")
+			fmt.Fprintf(w, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VFREEBUSY\r\n%sEND:VFREEBUSY\r\nEND:VCALENDAR\r\n", f.freeBusy[r.URL.Path])
+		default:
+			http.Error(w, "unsupported report", http.StatusBadRequest)
+		}
+	case http.MethodPut:
+		if r.Header.Get("Content-Type") != "text/calendar; charset=utf-8" {
+			http.Error(w, "calendar data required", http.StatusUnsupportedMediaType)
+			return
+		}
+		_, exists := f.objects[r.URL.Path]
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != f.etag(r.URL.Path)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+		f.etags[r.URL.Path]++
+		w.Header().Set("ETag", f.etag(r.URL.Path))
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", f.etag(r.URL.Path))
+		io.WriteString(w, data)
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != f.etag(r.URL.Path) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeCalDAVServer) etag(path string) string {
+	return `"` + strconv.Itoa(f.etags[path]) + `"`
+}
+
+func (f *fakeCalDAVServer) calendarQuery(w http.ResponseWriter, collection string) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?><D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`)
+	for path, data := range f.objects {
+		if !strings.HasPrefix(path, collection) {
+			continue
+		}
+		fmt.Fprintf(w, `<D:response><D:href>%s</D:href><D:propstat><D:prop><D:getetag>%s</D:getetag><C:calendar-data>%s</C:calendar-data></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response>`,
+			path, f.etag(path), strings.ReplaceAll(data, "&", "&amp;"))
+	}
+	io.WriteString(w, `</D:multistatus>`)
+}
+
+// timeRange extracts the time-range bounds of a REPORT body
+func timeRange(body string) string {
+	_, rest, ok := strings.Cut(body, `time-range start="`)
+	if !ok {
+		return ""
+	}
+	start, rest, _ := strings.Cut(rest, `"`)
+	_, rest, _ = strings.Cut(rest, `end="`)
+	end, _, _ := strings.Cut(rest, `"`)
+	return start + "/" + end
+}
+
+func newTestCalDAV(t *testing.T) (*fakeCalDAVServer, *CalDAVClient) {
+	t.Helper()
+	fake := &fakeCalDAVServer{
+		objects: map[string]string{"/dav/alice/standup.ics": standupICS},
+		etags:   map[string]int{"/dav/alice/standup.ics": 1},
+		freeBusy: map[string]string{
+			"/dav/bob/": "FREEBUSY;FBTYPE=BUSY:20260302T100000Z/20260302T110000Z,20260302T130000Z/PT30M\r\n" +
+				"FREEBUSY;FBTYPE=BUSY-TENTATIVE:20260303T090000Z/20260303T100000Z\r\n" +
+				"FREEBUSY;FBTYPE=FREE:20260303T120000Z/20260303T130000Z\r\n",
+		},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewCalDAVClient(server.URL+"/dav/{user}", "scheduler", "secret", nil)
+}
+
+func TestCalDAVClientGetUserEventsExpandsRecurrences(t *testing.T) {
+	fake, client := newTestCalDAV(t)
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	events, truncated, err := client.GetUserEvents(context.Background(), "alice@gruve.ai", start, end)
+	if err != nil {
+		t.Fatalf("GetUserEvents() error = %v", err)
+	}
+	if truncated {
+		t.Error("GetUserEvents() truncated")
+	}
+	if len(fake.queries) != 1 || fake.queries[0] != "20260302T000000Z/20260309T000000Z" {
+		t.Errorf("calendar-query time ranges = %v", fake.queries)
+	}
+
+	// Berlin is UTC+1 until the end of March
+	want := []struct {
+		id      string
+		subject string
+		start   time.Time
+	}{
+		{"standup_20260302T080000Z", "Standup", time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)},
+		{"standup_20260304T080000Z", "Standup (afternoon)", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		got := events[i]
+		if got.ID != w.id || got.Subject != w.subject || !got.Start.Equal(w.start) || !got.End.Equal(w.start.Add(30*time.Minute)) {
+			t.Errorf("event %d = %s %q %s-%s, want %s %q at %s", i, got.ID, got.Subject, got.Start, got.End, w.id, w.subject, w.start)
+		}
+		if got.ICalUID != "standup@gruve.ai" || got.Organizer != "alice@gruve.ai" {
+			t.Errorf("event %d uid %q organizer %q", i, got.ICalUID, got.Organizer)
+		}
+	}
+}
+
+func TestCalDAVClientGetSchedules(t *testing.T) {
+	fake, client := newTestCalDAV(t)
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+
+	schedules, err := client.GetSchedules(context.Background(), []string{"bob@gruve.ai", "carol@gruve.ai"}, start, end, 0)
+	if err != nil {
+		t.Fatalf("GetSchedules() error = %v", err)
+	}
+	if len(fake.queries) != 2 || fake.queries[0] != "20260302T000000Z/20260304T000000Z" {
+		t.Errorf("free-busy-query time ranges = %v", fake.queries)
+	}
+	if len(schedules) != 2 {
+		t.Fatalf("got %d schedules, want 2", len(schedules))
+	}
+
+	tests := []struct {
+		start  time.Time
+		end    time.Time
+		status models.ShowAs
+	}{
+		{time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC), models.ShowAsBusy},
+		{time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 13, 30, 0, 0, time.UTC), models.ShowAsBusy},
+		{time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC), models.ShowAsTentative},
+	}
+	bob := schedules[0]
+	if bob.Error != "" || len(bob.Items) != len(tests) {
+		t.Fatalf("bob's schedule = %+v, want %d busy items", bob, len(tests))
+	}
+	for i, tt := range tests {
+		item := bob.Items[i]
+		if !item.Start.Equal(tt.start) || !item.End.Equal(tt.end) || item.Status != tt.status {
+			t.Errorf("item %d = %s-%s %s, want %s-%s %s", i, item.Start, item.End, item.Status, tt.start, tt.end, tt.status)
+		}
+	}
+	// Carol's empty calendar is free rather than unavailable
+	if carol := schedules[1]; carol.Error != "" || len(carol.Items) != 0 {
+		t.Errorf("carol's schedule = %+v, want free", carol)
+	}
+}
+
+func TestCalDAVClientPutEvents(t *testing.T) {
+	fake, client := newTestCalDAV(t)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 5, 15, 0, 0, 0, time.UTC)
+
+	created, err := client.CreateCalendarEvent(ctx, "alice@gruve.ai", models.CreateMeetingRequest{
+		Subject:   "Review, part 1",
+		Start:     start,
+		End:       start.Add(time.Hour),
+		Attendees: []string{"bob@gruve.ai"},
+	})
+	if err != nil {
+		t.Fatalf("CreateCalendarEvent() error = %v", err)
+	}
+	path := "/dav/alice/" + created.ID + ".ics"
+	data := fake.objects[path]
+	for _, line := range []string{"SUMMARY:Review\\, part 1", "DTSTART:20260305T150000Z", "ORGANIZER:mailto:alice@gruve.ai", "SEQUENCE:0"} {
+		if !strings.Contains(data, line+"\r\n") {
+			t.Errorf("created object lacks %q:\n%s", line, data)
+		}
+	}
+
+	// Bob's response is kept when carol is added
+	fake.objects[path] = strings.Replace(data, "PARTSTAT=NEEDS-ACTION", "PARTSTAT=ACCEPTED", 1)
+	fake.etags[path]++
+	updated, err := client.UpdateCalendarEvent(ctx, "alice@gruve.ai", created.ID, models.UpdateMeetingRequest{Attendees: []string{"bob@gruve.ai", "carol@gruve.ai"}})
+	if err != nil {
+		t.Fatalf("UpdateCalendarEvent() error = %v", err)
+	}
+	if updated.Sequence != 1 || len(updated.Attendees) != 2 {
+		t.Errorf("updated = sequence %d attendees %v", updated.Sequence, updated.Attendees)
+	}
+	responses, err := client.GetEventResponses(ctx, "alice@gruve.ai", created.ID)
+	if err != nil {
+		t.Fatalf("GetEventResponses() error = %v", err)
+	}
+	wantResponses := map[string]models.ResponseStatus{"bob@gruve.ai": models.ResponseAccepted, "carol@gruve.ai": models.ResponseNone}
+	for _, response := range responses {
+		if wantResponses[response.Email] != response.Status {
+			t.Errorf("response of %s = %q, want %q", response.Email, response.Status, wantResponses[response.Email])
+		}
+	}
+
+	before := time.Date(2026, 3, 5, 14, 0, 0, 0, time.UTC)
+	if _, err := client.UpdateCalendarEvent(ctx, "alice@gruve.ai", created.ID, models.UpdateMeetingRequest{End: &before}); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("UpdateCalendarEvent() ending before the start error = %v, want ErrInvalidEvent", err)
+	}
+	if _, err := client.UpdateCalendarEvent(ctx, "bob@gruve.ai", created.ID, models.UpdateMeetingRequest{End: &before}); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("UpdateCalendarEvent() in another calendar error = %v, want ErrEventNotFound", err)
+	}
+
+	if _, err := client.CancelCalendarEvent(ctx, "alice@gruve.ai", created.ID, ""); err != nil {
+		t.Fatalf("CancelCalendarEvent() error = %v", err)
+	}
+	if _, ok := fake.objects[path]; ok {
+		t.Error("cancelled event still stored")
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Calendar providers selectable with CALENDAR_PROVIDER or bound to single users
+const (
+	CalendarProviderMicrosoft = "microsoft" // Microsoft Graph, or the local mock, as GRAPH_MODE selects
+	CalendarProviderGoogle    = "google"    // Google Calendar API v3
+	CalendarProviderCalDAV    = "caldav"    // A CalDAV server such as Nextcloud, Fastmail or Radicale
+	CalendarProviderLocal     = "local"     // The local Postgres calendar store, whatever GRAPH_MODE says
+)
+
+// IsCalendarProvider reports whether name is a known calendar provider
+func IsCalendarProvider(name string) bool {
+	switch name {
+	case CalendarProviderMicrosoft, CalendarProviderGoogle, CalendarProviderCalDAV, CalendarProviderLocal:
+		return true
+	}
+	return false
+}
+
+// CalendarProviderFromEnv reads CALENDAR_PROVIDER, defaulting to Microsoft
+func CalendarProviderFromEnv() string {
+	provider := strings.ToLower(os.Getenv("CALENDAR_PROVIDER"))
+	if provider == "" {
+		return CalendarProviderMicrosoft
+	}
+	if !IsCalendarProvider(provider) {
+		log.Printf("Warning: Unknown CALENDAR_PROVIDER %q, using %s", provider, CalendarProviderMicrosoft)
+		return CalendarProviderMicrosoft
+	}
+	return provider
+}
+
+// providerBindingsTTL bounds how long binding changes made through another instance take to be seen
+const providerBindingsTTL = time.Minute
+
+// calendarProviderCache keeps the stored provider bindings in memory, so routing a request does not
+// read the whole table
+type calendarProviderCache struct {
+	mu       sync.Mutex
+	db       *sql.DB
+	bindings map[string]string // Provider by lower-case email
+	loadedAt time.Time
+}
+
+var providerBindings calendarProviderCache
+
+// get returns the bindings of db, calling load when none are kept for it or they are older than
+// providerBindingsTTL. The map is shared and must not be modified
+func (c *calendarProviderCache) get(ctx context.Context, db *sql.DB, now time.Time, load func(context.Context) ([]models.CalendarProviderBinding, error)) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bindings != nil && c.db == db && now.Sub(c.loadedAt) < providerBindingsTTL {
+		return c.bindings, nil
+	}
+
+	stored, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bindings := make(map[string]string, len(stored))
+	for _, binding := range stored {
+		bindings[strings.ToLower(binding.UserEmail)] = binding.Provider
+	}
+	c.db, c.bindings, c.loadedAt = db, bindings, now
+	return bindings, nil
+}
+
+// invalidate drops the kept bindings
+func (c *calendarProviderCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bindings = nil
+}
+
+// CalendarProviderBindings returns the stored provider of every bound user, by lower-case email
+// The bindings are kept for a minute; changes made through this instance are seen at once
+func CalendarProviderBindings(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	return providerBindings.get(ctx, db, time.Now(), models.NewCalendarProviderStore(db).ListCalendarProviders)
+}
+
+// InvalidateCalendarProviderBindings drops the kept bindings after a user's binding changed
+func InvalidateCalendarProviderBindings() {
+	providerBindings.invalidate()
+}
+
+// UserCalendarProvider returns the provider of userEmail's calendar
+// Falls back to CalendarProviderFromEnv for users without a binding, without a database or when the lookup fails
+func UserCalendarProvider(ctx context.Context, db *sql.DB, userEmail string) string {
+	if db == nil || userEmail == "" {
+		return CalendarProviderFromEnv()
+	}
+
+	bindings, err := CalendarProviderBindings(ctx, db)
+	if err != nil {
+		log.Printf("Warning: Failed to load calendar provider for %s: %v", userEmail, err)
+		return CalendarProviderFromEnv()
+	}
+	provider, ok := bindings[strings.ToLower(userEmail)]
+	if !ok || !IsCalendarProvider(provider) {
+		return CalendarProviderFromEnv()
+	}
+	return provider
+}
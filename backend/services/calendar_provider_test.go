@@ -0,0 +1,89 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCalendarProviderCache(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	db, otherDB := &sql.DB{}, &sql.DB{}
+
+	tests := []struct {
+		name       string
+		prepare    func(c *calendarProviderCache)
+		db         *sql.DB
+		at         time.Time
+		loadErr    error
+		wantLoads  int
+		wantGoogle bool
+		wantErr    bool
+	}{
+		{name: "first read loads", db: db, at: now, wantLoads: 1, wantGoogle: true},
+		{
+			name: "kept bindings are reused",
+			prepare: func(c *calendarProviderCache) {
+				c.db, c.bindings, c.loadedAt = db, map[string]string{"bob@gruve.ai": "google"}, now
+			},
+			db:         db,
+			at:         now.Add(30 * time.Second),
+			wantGoogle: true,
+		},
+		{
+			name:       "expired bindings are reloaded",
+			prepare:    func(c *calendarProviderCache) { c.db, c.bindings, c.loadedAt = db, map[string]string{}, now },
+			db:         db,
+			at:         now.Add(providerBindingsTTL),
+			wantLoads:  1,
+			wantGoogle: true,
+		},
+		{
+			name: "invalidated bindings are reloaded",
+			prepare: func(c *calendarProviderCache) {
+				c.db, c.bindings, c.loadedAt = db, map[string]string{}, now
+				c.invalidate()
+			},
+			db:         db,
+			at:         now,
+			wantLoads:  1,
+			wantGoogle: true,
+		},
+		{
+			name:       "another database is loaded",
+			prepare:    func(c *calendarProviderCache) { c.db, c.bindings, c.loadedAt = otherDB, map[string]string{}, now },
+			db:         db,
+			at:         now,
+			wantLoads:  1,
+			wantGoogle: true,
+		},
+		{name: "load failure", db: db, at: now, loadErr: errors.New("connection refused"), wantLoads: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache calendarProviderCache
+			if tt.prepare != nil {
+				tt.prepare(&cache)
+			}
+			loads := 0
+			load := func(context.Context) ([]models.CalendarProviderBinding, error) {
+				loads++
+				return []models.CalendarProviderBinding{{UserEmail: "Bob@Gruve.ai", Provider: "google"}}, tt.loadErr
+			}
+
+			bindings, err := cache.get(context.Background(), tt.db, tt.at, load)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if loads != tt.wantLoads {
+				t.Errorf("loaded %d times, want %d", loads, tt.wantLoads)
+			}
+			if got := bindings["bob@gruve.ai"] == "google"; got != tt.wantGoogle {
+				t.Errorf("bindings = %v, want bob@gruve.ai on google: %v", bindings, tt.wantGoogle)
+			}
+		})
+	}
+}
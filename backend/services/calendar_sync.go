@@ -0,0 +1,410 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// CalendarSyncSettings controls the calendarView delta sync into the local event cache
+type CalendarSyncSettings struct {
+	Interval time.Duration // Time between sync runs
+	Past     time.Duration // How far back the synced window reaches
+	Future   time.Duration // How far ahead the synced window reaches
+	MaxAge   time.Duration // Oldest sync GetUserEvents still serves from the cache
+	Users    []string      // Calendars that opted in to the sync; no others are tracked
+}
+
+// CalendarSyncSettingsFromEnv reads the calendar sync settings
+// Reports false when CALENDAR_SYNC_INTERVAL is not set, which disables the sync and the cache
+// - CALENDAR_SYNC_INTERVAL: Go duration between sync runs, e.g. 5m
+// - CALENDAR_SYNC_USERS: comma-separated emails of the calendars to sync
+// - CALENDAR_SYNC_PAST_DAYS / CALENDAR_SYNC_FUTURE_DAYS: synced window (default 30 / 180)
+// - CALENDAR_SYNC_MAX_AGE: Go duration after which cached calendars are stale (default 3 intervals)
+func CalendarSyncSettingsFromEnv() (CalendarSyncSettings, bool, error) {
+	value := os.Getenv("CALENDAR_SYNC_INTERVAL")
+	if value == "" {
+		return CalendarSyncSettings{}, false, nil
+	}
+
+	var settings CalendarSyncSettings
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return settings, false, fmt.Errorf("invalid CALENDAR_SYNC_INTERVAL: %s", value)
+	}
+	settings.Interval = interval
+	settings.MaxAge = 3 * interval
+	if value := os.Getenv("CALENDAR_SYNC_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			return settings, false, fmt.Errorf("invalid CALENDAR_SYNC_MAX_AGE: %s", value)
+		}
+		settings.MaxAge = maxAge
+	}
+
+	for _, window := range []struct {
+		key      string
+		fallback int
+		target   *time.Duration
+	}{
+		{"CALENDAR_SYNC_PAST_DAYS", 30, &settings.Past},
+		{"CALENDAR_SYNC_FUTURE_DAYS", 180, &settings.Future},
+	} {
+		days := window.fallback
+		if value := os.Getenv(window.key); value != "" {
+			days, err = strconv.Atoi(value)
+			if err != nil || days < 1 {
+				return settings, false, fmt.Errorf("invalid %s: %s", window.key, value)
+			}
+		}
+		*window.target = time.Duration(days) * 24 * time.Hour
+	}
+
+	for _, email := range strings.Split(os.Getenv("CALENDAR_SYNC_USERS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			settings.Users = append(settings.Users, email)
+		}
+	}
+	return settings, true, nil
+}
+
+// calendarSyncLocks serializes syncs of the same calendar, keyed by lowercase email
+// Change notifications start syncs outside the worker's own schedule
+var calendarSyncLocks sync.Map
+
+// CalendarSyncWorker keeps a local copy of tracked users' Graph calendars current with
+// calendarView delta queries, so calendar reads do not need a Graph round-trip each time
+type CalendarSyncWorker struct {
+	Store    *models.EventCacheStore
+	Config   *config.Config // Supplies the application token and users' time zones
+	Settings CalendarSyncSettings
+}
+
+// NewCalendarSyncWorkerFromEnv creates a calendar sync worker from environment variables
+// Returns nil when CALENDAR_SYNC_INTERVAL is not set. Syncing reads other users' calendars with
+// the application token, so Calendars.Read application permission is required
+func NewCalendarSyncWorkerFromEnv(cfg *config.Config) (*CalendarSyncWorker, error) {
+	settings, enabled, err := CalendarSyncSettingsFromEnv()
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if os.Getenv("GRAPH_MODE") != "real" {
+		return nil, fmt.Errorf("calendar sync needs GRAPH_MODE=real")
+	}
+	if cfg.DB == nil {
+		return nil, fmt.Errorf("calendar sync needs a database")
+	}
+	return &CalendarSyncWorker{Store: models.NewEventCacheStore(cfg.DB), Config: cfg, Settings: settings}, nil
+}
+
+// Run syncs every tracked calendar every Interval until ctx is canceled
+func (w *CalendarSyncWorker) Run(ctx context.Context) {
+	if err := w.TrackConfiguredUsers(ctx); err != nil {
+		log.Printf("Warning: Failed to update tracked calendars: %v", err)
+	}
+
+	ticker := time.NewTicker(w.Settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.SyncOnce(ctx); err != nil {
+			log.Printf("Warning: Calendar sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TrackConfiguredUsers tracks exactly the calendars listed in the settings
+// Calendars tracked before that are no longer listed are untracked and their cached events dropped
+func (w *CalendarSyncWorker) TrackConfiguredUsers(ctx context.Context) error {
+	tracked, err := w.Store.TrackedUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	configured := map[string]bool{}
+	for _, email := range w.Settings.Users {
+		configured[strings.ToLower(email)] = true
+		if err := w.Store.TrackUser(ctx, email); err != nil {
+			log.Printf("Warning: Failed to track calendar of %s: %v", email, err)
+		}
+	}
+	for _, email := range tracked {
+		if configured[strings.ToLower(email)] {
+			continue
+		}
+		if err := w.Store.UntrackUser(ctx, email); err != nil {
+			log.Printf("Warning: Failed to untrack calendar of %s: %v", email, err)
+		}
+	}
+	return nil
+}
+
+// SyncOnce brings every tracked calendar up to date
+// A failure on one calendar is logged and does not stop the others
+func (w *CalendarSyncWorker) SyncOnce(ctx context.Context) error {
+	users, err := w.Store.TrackedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tracked calendars: %v", err)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	token, err := w.Config.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get application token: %v", err)
+	}
+	client := InitializeGraphClient(token)
+
+	for _, email := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := w.SyncUser(ctx, client, email); err != nil {
+			log.Printf("Warning: Failed to sync calendar of %s: %v", email, err)
+		}
+	}
+	return nil
+}
+
+// SyncUser applies the changes to userEmail's calendar since the last sync
+// Without a delta link, or when the window no longer reaches far enough ahead, the calendar
+// is read in full and cached events it no longer contains are dropped
+func (w *CalendarSyncWorker) SyncUser(ctx context.Context, client *msgraphsdk.GraphServiceClient, userEmail string) error {
+	lock, _ := calendarSyncLocks.LoadOrStore(strings.ToLower(userEmail), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	state, _, err := w.Store.GetSyncState(ctx, userEmail)
+	if err != nil {
+		log.Printf("Warning: Discarding unreadable sync state of %s: %v", userEmail, err)
+		state = models.CalendarSyncState{}
+	}
+
+	// Whole seconds survive the database round-trip, so events stored now are not dropped below
+	now := time.Now().UTC().Truncate(time.Second)
+	full := state.DeltaLink == "" || state.WindowEnd.Before(now.Add(w.Settings.Future/2))
+	if !full {
+		next, err := w.readDelta(ctx, client, userEmail, state.DeltaLink, now)
+		if err == nil {
+			state.DeltaLink = next
+			state.LastSynced = now
+			return w.Store.SaveSyncState(ctx, userEmail, state)
+		}
+		// Graph expires delta links it can no longer resume from
+		var odataErr *odataerrors.ODataError
+		if !errors.As(err, &odataErr) || odataErr.GetStatusCode() != http.StatusGone {
+			return err
+		}
+		log.Printf("Delta link of %s expired, resyncing", userEmail)
+	}
+
+	state = models.CalendarSyncState{
+		WindowStart: now.Add(-w.Settings.Past),
+		WindowEnd:   now.Add(w.Settings.Future),
+	}
+	next, err := w.readDelta(ctx, client, userEmail, "", now)
+	if err != nil {
+		return err
+	}
+	if err := w.Store.DeleteEventsSyncedBefore(ctx, userEmail, now); err != nil {
+		return fmt.Errorf("failed to drop stale events: %v", err)
+	}
+	state.DeltaLink = next
+	state.LastSynced = now
+	return w.Store.SaveSyncState(ctx, userEmail, state)
+}
+
+// readDelta reads a calendarView delta from deltaLink, or a new one over the settings' window
+// when deltaLink is empty, applying each page to the cache. Returns the next delta link
+func (w *CalendarSyncWorker) readDelta(ctx context.Context, client *msgraphsdk.GraphServiceClient, userEmail, deltaLink string, syncedAt time.Time) (string, error) {
+	headers := abstractions.NewRequestHeaders()
+	PreferTimeZone(headers, time.UTC)
+	headers.Add("Prefer", fmt.Sprintf("odata.maxpagesize=%d", GraphPagingFromEnv().PageSize))
+
+	builder := client.Users().ByUserId(userEmail).CalendarView().Delta()
+	config := &graphusers.ItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{Headers: headers}
+	if deltaLink != "" {
+		builder = builder.WithUrl(deltaLink)
+	} else {
+		start := syncedAt.Add(-w.Settings.Past).Format(time.RFC3339)
+		end := syncedAt.Add(w.Settings.Future).Format(time.RFC3339)
+		config.QueryParameters = &graphusers.ItemCalendarViewDeltaRequestBuilderGetQueryParameters{
+			StartDateTime: &start,
+			EndDateTime:   &end,
+		}
+	}
+
+	loc := UserLocation(ctx, w.Config.DB, userEmail)
+	for {
+		page, err := builder.GetAsDeltaGetResponse(ctx, config)
+		if err != nil {
+			return "", err
+		}
+		if err := w.applyDeltaPage(ctx, userEmail, page.GetValue(), loc, syncedAt); err != nil {
+			return "", err
+		}
+
+		if link := page.GetOdataNextLink(); link != nil && *link != "" {
+			builder = builder.WithUrl(*link)
+			config = &graphusers.ItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{Headers: headers}
+			continue
+		}
+		if link := page.GetOdataDeltaLink(); link != nil && *link != "" {
+			return *link, nil
+		}
+		return "", fmt.Errorf("delta response has neither a next nor a delta link")
+	}
+}
+
+// applyDeltaPage upserts the changed events of a delta page and deletes the removed ones
+func (w *CalendarSyncWorker) applyDeltaPage(ctx context.Context, userEmail string, items []graphmodels.Eventable, loc *time.Location, syncedAt time.Time) error {
+	var changed []models.Event
+	var removed []string
+	for _, item := range items {
+		if item.GetId() == nil {
+			continue
+		}
+		if _, ok := item.GetAdditionalData()["@removed"]; ok {
+			removed = append(removed, *item.GetId())
+			continue
+		}
+		event, err := ConvertGraphEvent(item, time.UTC, loc)
+		if err != nil {
+			log.Printf("Warning: Skipping event %s of %s: %v", graphEventID(item), userEmail, err)
+			continue
+		}
+		changed = append(changed, event)
+	}
+
+	if err := w.Store.DeleteEvents(ctx, userEmail, removed); err != nil {
+		return fmt.Errorf("failed to delete removed events: %v", err)
+	}
+	if err := w.Store.UpsertEvents(ctx, userEmail, changed, syncedAt); err != nil {
+		return fmt.Errorf("failed to store events: %v", err)
+	}
+	return nil
+}
+
+// RefreshCalendar syncs userEmail's cached calendar now, after Graph reported a change to it
+// Does nothing when the calendar sync is disabled
+func RefreshCalendar(ctx context.Context, cfg *config.Config, userEmail string) error {
+	worker, err := NewCalendarSyncWorkerFromEnv(cfg)
+	if err != nil || worker == nil {
+		return err
+	}
+	token, err := cfg.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get application token: %v", err)
+	}
+	return worker.SyncUser(ctx, InitializeGraphClient(token), userEmail)
+}
+
+// InvalidateSyncedCalendars marks the cached calendars of the given users stale, e.g. after a
+// meeting of theirs was changed through the scheduler, so reads go to Graph until the next sync
+func InvalidateSyncedCalendars(ctx context.Context, cfg *config.Config, userEmails ...string) {
+	if cfg == nil || cfg.DB == nil {
+		return
+	}
+	if _, enabled, err := CalendarSyncSettingsFromEnv(); err != nil || !enabled {
+		return
+	}
+
+	store := models.NewEventCacheStore(cfg.DB)
+	for _, email := range userEmails {
+		if err := store.InvalidateCalendar(ctx, email); err != nil {
+			log.Printf("Warning: Failed to invalidate cached calendar of %s: %v", email, err)
+		}
+	}
+}
+
+// calendarAccessCache remembers whether a token may read a calendar, so that serving cached
+// events does not ask Graph on every read
+type calendarAccessCache struct {
+	mu      sync.Mutex
+	entries map[string]calendarAccess // By token scope and lowercase email
+}
+
+type calendarAccess struct {
+	allowed   bool
+	checkedAt time.Time
+}
+
+var sharedCalendarAccess = &calendarAccessCache{entries: map[string]calendarAccess{}}
+
+// allowed returns the decision for scope and userEmail checked within ttl, or calls check and
+// remembers its answer when check reports it final. A ttl of 0 always calls check
+func (a *calendarAccessCache) allowed(scope, userEmail string, ttl time.Duration, check func() (allowed, final bool)) bool {
+	key := scope + "|" + strings.ToLower(userEmail)
+	a.mu.Lock()
+	entry, ok := a.entries[key]
+	a.mu.Unlock()
+	if ok && time.Since(entry.checkedAt) < ttl {
+		return entry.allowed
+	}
+
+	allowed, final := check()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	for k, e := range a.entries {
+		if now.Sub(e.checkedAt) >= ttl {
+			delete(a.entries, k)
+		}
+	}
+	if final && ttl > 0 {
+		a.entries[key] = calendarAccess{allowed: allowed, checkedAt: now}
+	}
+	return allowed
+}
+
+// cachedUserEvents returns userEmail's events in [startTime, endTime) from the event cache
+// Reports false when the sync is disabled, the calendar is not tracked, or the cached calendar
+// is stale or does not cover the range
+func cachedUserEvents(ctx context.Context, cfg *config.Config, userEmail string, startTime, endTime time.Time) ([]models.Event, bool) {
+	if cfg == nil || cfg.DB == nil || userEmail == "" {
+		return nil, false
+	}
+	settings, enabled, err := CalendarSyncSettingsFromEnv()
+	if err != nil || !enabled {
+		return nil, false
+	}
+
+	store := models.NewEventCacheStore(cfg.DB)
+	state, tracked, err := store.GetSyncState(ctx, userEmail)
+	if err != nil {
+		log.Printf("Warning: Failed to read sync state of %s: %v", userEmail, err)
+		return nil, false
+	}
+	if !tracked || !state.Covers(startTime, endTime) || time.Since(state.LastSynced) > settings.MaxAge {
+		return nil, false
+	}
+
+	events, err := store.GetEvents(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		log.Printf("Warning: Failed to read cached events of %s: %v", userEmail, err)
+		return nil, false
+	}
+	return events, true
+}
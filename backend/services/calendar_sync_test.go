@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarAccessCache(t *testing.T) {
+	type read struct {
+		scope, email      string
+		answer, final     bool // What Graph would answer if asked
+		want, wantChecked bool
+	}
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		reads []read
+	}{
+		{
+			name: "decision is reused for the same token and calendar",
+			ttl:  time.Minute,
+			reads: []read{
+				{"token:a", "bob@gruve.ai", true, true, true, true},
+				{"token:a", "Bob@gruve.ai", false, true, true, false},
+			},
+		},
+		{
+			name: "denial is reused",
+			ttl:  time.Minute,
+			reads: []read{
+				{"token:a", "bob@gruve.ai", false, true, false, true},
+				{"token:a", "bob@gruve.ai", true, true, false, false},
+			},
+		},
+		{
+			name: "other token or calendar is checked",
+			ttl:  time.Minute,
+			reads: []read{
+				{"token:a", "bob@gruve.ai", true, true, true, true},
+				{"token:b", "bob@gruve.ai", false, true, false, true},
+				{"token:a", "carol@gruve.ai", false, true, false, true},
+			},
+		},
+		{
+			name: "failed check is not remembered",
+			ttl:  time.Minute,
+			reads: []read{
+				{"token:a", "bob@gruve.ai", false, false, false, true},
+				{"token:a", "bob@gruve.ai", true, true, true, true},
+			},
+		},
+		{
+			name: "no ttl always checks",
+			reads: []read{
+				{"token:a", "bob@gruve.ai", true, true, true, true},
+				{"token:a", "bob@gruve.ai", false, true, false, true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &calendarAccessCache{entries: map[string]calendarAccess{}}
+			for i, r := range tt.reads {
+				checked := false
+				got := cache.allowed(r.scope, r.email, tt.ttl, func() (bool, bool) {
+					checked = true
+					return r.answer, r.final
+				})
+				if got != r.want || checked != r.wantChecked {
+					t.Errorf("read %d of %s by %s = %v, checked %v, want %v, checked %v", i, r.email, r.scope, got, checked, r.want, r.wantChecked)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarAccessCacheExpires(t *testing.T) {
+	cache := &calendarAccessCache{entries: map[string]calendarAccess{
+		"token:a|bob@gruve.ai": {allowed: true, checkedAt: time.Now().Add(-2 * time.Minute)},
+	}}
+	checks := 0
+	check := func() (bool, bool) {
+		checks++
+		return false, true
+	}
+
+	if cache.allowed("token:a", "bob@gruve.ai", time.Minute, check) || checks != 1 {
+		t.Errorf("expired decision was reused; checks = %d", checks)
+	}
+	if cache.allowed("token:a", "bob@gruve.ai", time.Minute, check) || checks != 1 {
+		t.Errorf("fresh decision was not reused; checks = %d", checks)
+	}
+}
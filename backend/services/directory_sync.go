@@ -0,0 +1,240 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// directoryDeltaSelect is the user properties requested from /users/delta
+const directoryDeltaSelect = "id,displayName,mail,userPrincipalName"
+
+// errDeltaExpired is returned when Graph can no longer resume from a delta link
+var errDeltaExpired = errors.New("delta link expired")
+
+// DirectorySyncWorker keeps the users table in step with the Azure AD directory using /users/delta
+type DirectorySyncWorker struct {
+	Store     *models.UserStore
+	Config    *config.Config // Supplies the application token and Graph base URL
+	Interval  time.Duration  // polling interval used by Run
+	TimeZones bool           // Read each changed user's mailbox time zone
+	Client    *http.Client   // Calls Graph only, so its retries are counted with Graph's
+}
+
+// NewDirectorySyncWorkerFromEnv creates a directory sync worker from environment variables
+// Returns nil when DIRECTORY_SYNC_INTERVAL is not set
+// - DIRECTORY_SYNC_INTERVAL: Go duration between syncs, e.g. 1h
+// - DIRECTORY_SYNC_TIME_ZONES: "false" skips reading mailbox time zones (MailboxSettings.Read)
+// Needs the User.Read.All application permission
+func NewDirectorySyncWorkerFromEnv(cfg *config.Config) (*DirectorySyncWorker, error) {
+	value := os.Getenv("DIRECTORY_SYNC_INTERVAL")
+	if value == "" {
+		return nil, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid DIRECTORY_SYNC_INTERVAL: %s", value)
+	}
+	if cfg.DB == nil {
+		return nil, fmt.Errorf("directory sync needs a database")
+	}
+
+	return &DirectorySyncWorker{
+		Store:     models.NewUserStore(cfg.DB),
+		Config:    cfg,
+		Interval:  interval,
+		TimeZones: os.Getenv("DIRECTORY_SYNC_TIME_ZONES") != "false",
+		Client:    &http.Client{Timeout: 30 * time.Second, Transport: utils.NewGraphRetryTransport(nil)},
+	}, nil
+}
+
+// Run syncs the directory every Interval until ctx is canceled
+func (w *DirectorySyncWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.SyncOnce(ctx); err != nil {
+			log.Printf("Warning: Directory sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce applies the directory changes since the last sync, or reads the whole directory
+// when there is no delta link yet. A full read soft-deletes users the directory no longer has
+func (w *DirectorySyncWorker) SyncOnce(ctx context.Context) error {
+	state, err := w.Store.GetDirectorySyncState(ctx)
+	if err != nil {
+		log.Printf("Warning: Discarding unreadable directory sync state: %v", err)
+		state = models.DirectorySyncState{}
+	}
+
+	token, err := w.Config.GetAccessToken()
+	if err != nil {
+		return w.fail(ctx, state, fmt.Errorf("failed to get application token: %v", err))
+	}
+
+	// Whole seconds survive the database round-trip, so users stored now are not dropped below
+	started := time.Now().UTC().Truncate(time.Second)
+	full := state.DeltaLink == ""
+	changed, removed, next, err := w.readDelta(ctx, token, state.DeltaLink, started)
+	if errors.Is(err, errDeltaExpired) {
+		log.Printf("Directory delta link expired, resyncing")
+		full = true
+		changed, removed, next, err = w.readDelta(ctx, token, "", started)
+	}
+	if err != nil {
+		return w.fail(ctx, state, err)
+	}
+
+	if full {
+		gone, err := w.Store.SoftDeleteUsersSyncedBefore(ctx, started)
+		if err != nil {
+			return w.fail(ctx, state, fmt.Errorf("failed to remove users missing from the directory: %v", err))
+		}
+		removed += gone
+		state.LastFull = started
+	}
+
+	state.DeltaLink = next
+	state.LastSynced = started
+	state.LastChanged = changed
+	state.LastRemoved = removed
+	state.LastError = ""
+	if err := w.Store.SaveDirectorySyncState(ctx, state); err != nil {
+		return fmt.Errorf("failed to save directory sync state: %v", err)
+	}
+	log.Printf("Directory sync: %d users changed, %d removed", changed, removed)
+	return nil
+}
+
+// fail records err in the sync state, keeping the delta link so the next run resumes, and returns it
+func (w *DirectorySyncWorker) fail(ctx context.Context, state models.DirectorySyncState, err error) error {
+	state.LastError = err.Error()
+	if saveErr := w.Store.SaveDirectorySyncState(ctx, state); saveErr != nil {
+		log.Printf("Warning: Failed to save directory sync state: %v", saveErr)
+	}
+	return err
+}
+
+// readDelta reads /users/delta from deltaLink, or from the start when it is empty, applying
+// each page to the users table. Returns the users changed and removed and the next delta link
+func (w *DirectorySyncWorker) readDelta(ctx context.Context, token, deltaLink string, syncedAt time.Time) (int, int, string, error) {
+	link := deltaLink
+	if link == "" {
+		link = strings.TrimRight(w.Config.GraphAPIBase, "/") + "/users/delta?$select=" + url.QueryEscape(directoryDeltaSelect)
+	}
+
+	changed, removed := 0, 0
+	timeZones := w.TimeZones
+	for {
+		var page struct {
+			Value     []models.MSUser `json:"value"`
+			NextLink  string          `json:"@odata.nextLink"`
+			DeltaLink string          `json:"@odata.deltaLink"`
+		}
+		status, err := w.getJSON(ctx, token, link, &page)
+		if status == http.StatusGone && deltaLink != "" {
+			return changed, removed, "", errDeltaExpired
+		}
+		if err != nil {
+			return changed, removed, "", fmt.Errorf("failed to read /users/delta: %v", err)
+		}
+
+		var updates []models.MSUser
+		var gone []string
+		for _, user := range page.Value {
+			if user.IsRemoved {
+				gone = append(gone, user.ID)
+				continue
+			}
+			if timeZones {
+				user.TimeZone, timeZones = w.mailboxTimeZone(ctx, token, user.ID)
+			}
+			updates = append(updates, user)
+		}
+
+		stored, err := w.Store.ApplyDirectoryChanges(ctx, updates, syncedAt)
+		if err != nil {
+			return changed, removed, "", fmt.Errorf("failed to store users: %v", err)
+		}
+		deleted, err := w.Store.SoftDeleteUsers(ctx, gone)
+		if err != nil {
+			return changed, removed, "", fmt.Errorf("failed to remove users: %v", err)
+		}
+		changed += stored
+		removed += deleted
+
+		switch {
+		case page.NextLink != "":
+			link = page.NextLink
+		case page.DeltaLink != "":
+			return changed, removed, page.DeltaLink, nil
+		default:
+			return changed, removed, "", fmt.Errorf("delta response has neither a next nor a delta link")
+		}
+	}
+}
+
+// mailboxTimeZone reads the IANA time zone of a user's mailbox, or "" when there is none
+// Reports false once the application lacks permission, so the rest of the run skips the lookups
+func (w *DirectorySyncWorker) mailboxTimeZone(ctx context.Context, token, userID string) (string, bool) {
+	var settings struct {
+		TimeZone string `json:"timeZone"`
+	}
+	link := fmt.Sprintf("%s/users/%s/mailboxSettings?$select=timeZone", strings.TrimRight(w.Config.GraphAPIBase, "/"), url.PathEscape(userID))
+	status, err := w.getJSON(ctx, token, link, &settings)
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		log.Printf("Warning: Not allowed to read mailbox time zones, skipping them: %v", err)
+		return "", false
+	}
+	if err != nil || settings.TimeZone == "" {
+		return "", true
+	}
+
+	name, err := NormalizeTimeZone(settings.TimeZone)
+	if err != nil {
+		log.Printf("Warning: Ignoring time zone of user %s: %v", userID, err)
+		return "", true
+	}
+	return name, true
+}
+
+// getJSON sends an authenticated GET to Graph and decodes a 200 response into out
+func (w *DirectorySyncWorker) getJSON(ctx context.Context, token, link string, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("Graph API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to parse response: %v", err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// UserLocation returns the time zone used to place a user's all-day events
+// Reads users.timezone, then DEFAULT_TIMEZONE (IANA names or Windows IDs), and falls back to UTC
+func UserLocation(ctx context.Context, db *sql.DB, email string) *time.Location {
+	if db != nil && email != "" {
+		name, err := models.NewUserStore(db).GetTimeZone(ctx, email)
+		if err != nil {
+			log.Printf("Warning: Failed to load time zone for %s: %v", email, err)
+		} else if name != "" {
+			if loc, err := LoadTimeZone(name); err == nil {
+				return loc
+			}
+			log.Printf("Warning: Unknown time zone %q for %s", name, email)
+		}
+	}
+
+	if loc, err := LoadTimeZone(os.Getenv("DEFAULT_TIMEZONE")); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// AnchorAllDay moves an all-day event's dates to midnight in loc
+// The calendar dates of Start and End are kept; only the zone they are read in changes
+func AnchorAllDay(event models.Event, loc *time.Location) models.Event {
+	if !event.IsAllDay {
+		return event
+	}
+	event.Start = time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, loc)
+	event.End = time.Date(event.End.Year(), event.End.Month(), event.End.Day(), 0, 0, 0, 0, loc)
+	if !event.End.After(event.Start) {
+		event.End = event.Start.AddDate(0, 0, 1)
+	}
+	return event
+}
+
+// GraphEventTimes returns the start and end of a Graph event and whether it is all-day
+// Timed events are read in their own zone, or preferred when Graph omits it; all-day events
+// carry dates without a meaningful zone and are anchored to midnight in loc
+func GraphEventTimes(item graphmodels.Eventable, preferred, loc *time.Location) (time.Time, time.Time, bool, error) {
+	allDay := item.GetIsAllDay() != nil && *item.GetIsAllDay()
+	if !allDay {
+		start, err := ParseGraphDateTime(item.GetStart(), preferred)
+		if err != nil {
+			return time.Time{}, time.Time{}, false, fmt.Errorf("invalid start: %v", err)
+		}
+		end, err := ParseGraphDateTime(item.GetEnd(), preferred)
+		if err != nil {
+			return time.Time{}, time.Time{}, false, fmt.Errorf("invalid end: %v", err)
+		}
+		return start, end, false, nil
+	}
+
+	start, err := parseGraphDate(item.GetStart())
+	if err != nil {
+		return time.Time{}, time.Time{}, true, fmt.Errorf("invalid start: %v", err)
+	}
+	end, err := parseGraphDate(item.GetEnd())
+	if err != nil {
+		return time.Time{}, time.Time{}, true, fmt.Errorf("invalid end: %v", err)
+	}
+	event := AnchorAllDay(models.Event{Start: start, End: end, IsAllDay: true}, loc)
+	return event.Start, event.End, true, nil
+}
+
+// ConvertGraphEvent converts a Graph event to a models.Event, placing its times as GraphEventTimes does
+// Properties left out of the request's $select are left empty
+func ConvertGraphEvent(item graphmodels.Eventable, preferred, loc *time.Location) (models.Event, error) {
+	if item.GetId() == nil {
+		return models.Event{}, fmt.Errorf("missing id")
+	}
+	start, end, allDay, err := GraphEventTimes(item, preferred, loc)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	event := models.Event{
+		ID:          *item.GetId(),
+		Start:       start,
+		End:         end,
+		Attendees:   []string{},
+		Sensitivity: ConvertGraphSensitivity(item.GetSensitivity()),
+		IsAllDay:    allDay,
+		ShowAs:      ConvertGraphShowAs(item.GetShowAs()),
+	}
+	if item.GetSubject() != nil {
+		event.Subject = *item.GetSubject()
+	}
+	if item.GetOrganizer() != nil && item.GetOrganizer().GetEmailAddress() != nil && item.GetOrganizer().GetEmailAddress().GetAddress() != nil {
+		event.Organizer = *item.GetOrganizer().GetEmailAddress().GetAddress()
+	}
+	for _, att := range item.GetAttendees() {
+		if att.GetEmailAddress() != nil && att.GetEmailAddress().GetAddress() != nil {
+			event.Attendees = append(event.Attendees, *att.GetEmailAddress().GetAddress())
+		}
+	}
+	if item.GetOnlineMeeting() != nil && item.GetOnlineMeeting().GetJoinUrl() != nil {
+		event.OnlineURL = *item.GetOnlineMeeting().GetJoinUrl()
+		event.IsOnline = true
+	}
+	if item.GetLocation() != nil && item.GetLocation().GetDisplayName() != nil {
+		event.Location = *item.GetLocation().GetDisplayName()
+	}
+	if item.GetBodyPreview() != nil {
+		event.BodyPreview = *item.GetBodyPreview()
+	}
+	if item.GetICalUId() != nil {
+		event.ICalUID = *item.GetICalUId()
+	}
+	return event, nil
+}
+
+// ConvertGraphShowAs maps a Graph free/busy status to a models.ShowAs
+func ConvertGraphShowAs(status *graphmodels.FreeBusyStatus) models.ShowAs {
+	if status == nil {
+		return models.ShowAsBusy
+	}
+	switch *status {
+	case graphmodels.FREE_FREEBUSYSTATUS:
+		return models.ShowAsFree
+	case graphmodels.TENTATIVE_FREEBUSYSTATUS:
+		return models.ShowAsTentative
+	case graphmodels.OOF_FREEBUSYSTATUS:
+		return models.ShowAsOutOfOffice
+	case graphmodels.WORKINGELSEWHERE_FREEBUSYSTATUS:
+		return models.ShowAsWorkingElsewhere
+	case graphmodels.UNKNOWN_FREEBUSYSTATUS:
+		return models.ShowAsUnknown
+	default:
+		return models.ShowAsBusy
+	}
+}
+
+// clipTimeSlots trims slots to the range [start, end) and drops those outside it
+// Multi-day events otherwise count busy time outside the requested range
+func clipTimeSlots(slots []models.TimeSlot, start, end time.Time) []models.TimeSlot {
+	clipped := make([]models.TimeSlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Start.Before(start) {
+			slot.Start = start
+		}
+		if slot.End.After(end) {
+			slot.End = end
+		}
+		if slot.End.After(slot.Start) {
+			clipped = append(clipped, slot)
+		}
+	}
+	return clipped
+}
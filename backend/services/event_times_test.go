@@ -0,0 +1,133 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"testing"
+	"time"
+
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestConvertGraphEventAllDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	graphEvent := func(allDay bool, start, end, zone string) graphmodels.Eventable {
+		id := "evt-1"
+		item := graphmodels.NewEvent()
+		item.SetId(&id)
+		item.SetIsAllDay(&allDay)
+		for _, set := range []struct {
+			value  string
+			setter func(graphmodels.DateTimeTimeZoneable)
+		}{{start, item.SetStart}, {end, item.SetEnd}} {
+			dt := graphmodels.NewDateTimeTimeZone()
+			dt.SetDateTime(&set.value)
+			dt.SetTimeZone(&zone)
+			set.setter(dt)
+		}
+		return item
+	}
+
+	tests := []struct {
+		name       string
+		item       graphmodels.Eventable
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantAllDay bool
+	}{
+		{
+			name:       "all-day event starts at the owner's midnight",
+			item:       graphEvent(true, "2026-03-02T00:00:00.0000000", "2026-03-03T00:00:00.0000000", "UTC"),
+			wantStart:  time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			wantEnd:    time.Date(2026, 3, 3, 0, 0, 0, 0, newYork),
+			wantAllDay: true,
+		},
+		{
+			name:       "multi-day all-day event keeps its dates",
+			item:       graphEvent(true, "2026-03-02T00:00:00.0000000", "2026-03-05T00:00:00.0000000", "Pacific Standard Time"),
+			wantStart:  time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			wantEnd:    time.Date(2026, 3, 5, 0, 0, 0, 0, newYork),
+			wantAllDay: true,
+		},
+		{
+			name:       "all-day event without length lasts a day",
+			item:       graphEvent(true, "2026-03-02T00:00:00.0000000", "2026-03-02T00:00:00.0000000", "UTC"),
+			wantStart:  time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			wantEnd:    time.Date(2026, 3, 3, 0, 0, 0, 0, newYork),
+			wantAllDay: true,
+		},
+		{
+			name:      "timed multi-day event is read in its own zone",
+			item:      graphEvent(false, "2026-03-02T22:00:00.0000000", "2026-03-04T02:00:00.0000000", "UTC"),
+			wantStart: time.Date(2026, 3, 2, 22, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertGraphEvent(tt.item, time.UTC, newYork)
+			if err != nil {
+				t.Fatalf("ConvertGraphEvent() error = %v", err)
+			}
+			if !got.Start.Equal(tt.wantStart) || !got.End.Equal(tt.wantEnd) || got.IsAllDay != tt.wantAllDay {
+				t.Errorf("ConvertGraphEvent() = %v-%v all-day %v, want %v-%v all-day %v",
+					got.Start, got.End, got.IsAllDay, tt.wantStart, tt.wantEnd, tt.wantAllDay)
+			}
+			if got.ShowAs != models.ShowAsBusy {
+				t.Errorf("ConvertGraphEvent() show-as = %q, want busy when Graph omits it", got.ShowAs)
+			}
+		})
+	}
+}
+
+func TestAvailabilityFromEventsShowAs(t *testing.T) {
+	day := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	event := func(startHour, endHour int, showAs models.ShowAs) models.Event {
+		return models.Event{Start: day.Add(time.Duration(startHour) * time.Hour), End: day.Add(time.Duration(endHour) * time.Hour), ShowAs: showAs}
+	}
+
+	tests := []struct {
+		name     string
+		events   []models.Event
+		wantBusy int // Minutes
+	}{
+		{"busy", []models.Event{event(10, 11, models.ShowAsBusy)}, 60},
+		{"tentative blocks time", []models.Event{event(10, 11, models.ShowAsTentative)}, 60},
+		{"out of office blocks time", []models.Event{event(10, 12, models.ShowAsOutOfOffice)}, 120},
+		{"free leaves time open", []models.Event{event(10, 11, models.ShowAsFree)}, 0},
+		{"working elsewhere leaves time open", []models.Event{event(10, 11, models.ShowAsWorkingElsewhere)}, 0},
+		{"unset counts as busy", []models.Event{event(10, 11, "")}, 60},
+		{"multi-day event counts only the requested day", []models.Event{event(-24, 48, models.ShowAsBusy)}, 24 * 60},
+		{"free all-day event leaves the day open", []models.Event{{Start: day, End: day.AddDate(0, 0, 1), IsAllDay: true, ShowAs: models.ShowAsFree}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := availabilityFromEvents("bob@gruve.ai", tt.events, day, day.AddDate(0, 0, 1), "UTC")
+			if got.TotalBusyTime != tt.wantBusy {
+				t.Errorf("busy time = %d minutes (%v), want %d", got.TotalBusyTime, got.BusySlots, tt.wantBusy)
+			}
+		})
+	}
+}
+
+func TestScheduleFromEventsAllDay(t *testing.T) {
+	day := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	events := []models.Event{
+		{Start: day.AddDate(0, 0, -1), End: day.AddDate(0, 0, 2), IsAllDay: true, ShowAs: models.ShowAsOutOfOffice},
+		{Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour), ShowAs: models.ShowAsFree},
+		{Start: day.AddDate(0, 0, 3), End: day.AddDate(0, 0, 4), IsAllDay: true, ShowAs: models.ShowAsBusy},
+	}
+
+	schedule := scheduleFromEvents("bob@gruve.ai", events, day.Add(8*time.Hour), day.Add(12*time.Hour), time.Hour)
+	if schedule.AvailabilityView != "3333" {
+		t.Errorf("availability view = %q, want out of office throughout", schedule.AvailabilityView)
+	}
+	if len(schedule.Items) != 2 {
+		t.Errorf("items = %+v, want the all-day and free events in range", schedule.Items)
+	}
+	if busy := ScheduleBusyIntervals(schedule); len(busy) != 1 || !busy[0].Start.Equal(day.AddDate(0, 0, -1)) {
+		t.Errorf("busy intervals = %v, want only the out of office days", busy)
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FederatedGraphClient routes each user's calendar to the provider they are bound to
+// Reads go to the provider of the user read; meetings are written by the provider of their organizer
+// and reach attendees on other providers as invitations. Users without a binding stay on Default
+type FederatedGraphClient struct {
+	Default  string                            // Provider of users without a binding
+	Bindings map[string]string                 // Provider of each bound user, by lower-case email
+	Backend  func(provider string) GraphClient // Creates the client of a provider
+
+	mu       sync.Mutex
+	backends map[string]GraphClient
+}
+
+// NewFederatedGraphClient creates a client routing users by their stored provider bindings
+// When no user is bound to a provider other than defaultProvider, the default provider's client is
+// returned unchanged
+func NewFederatedGraphClient(ctx context.Context, db *sql.DB, defaultProvider string, backend func(provider string) GraphClient) GraphClient {
+	bindings := map[string]string{}
+	if db != nil {
+		stored, err := CalendarProviderBindings(ctx, db)
+		if err != nil {
+			log.Printf("Warning: Failed to load calendar provider bindings: %v", err)
+		}
+		for email, provider := range stored {
+			if provider != defaultProvider && IsCalendarProvider(provider) {
+				bindings[email] = provider
+			}
+		}
+	}
+	if len(bindings) == 0 {
+		return backend(defaultProvider)
+	}
+
+	return &FederatedGraphClient{
+		Default:  defaultProvider,
+		Bindings: bindings,
+		Backend:  backend,
+		backends: map[string]GraphClient{},
+	}
+}
+
+// Provider returns the provider of userEmail's calendar
+func (f *FederatedGraphClient) Provider(userEmail string) string {
+	if provider, ok := f.Bindings[strings.ToLower(userEmail)]; ok {
+		return provider
+	}
+	return f.Default
+}
+
+// ClientFor returns the client of userEmail's provider, creating it on first use
+func (f *FederatedGraphClient) ClientFor(userEmail string) GraphClient {
+	provider := f.Provider(userEmail)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client, ok := f.backends[provider]
+	if !ok {
+		client = f.Backend(provider)
+		f.backends[provider] = client
+	}
+	return client
+}
+
+// CalendarBackend returns the client that actually serves userEmail's calendar behind client,
+// looking through privacy masking, federation and the free/busy cache
+func CalendarBackend(client GraphClient, userEmail string) GraphClient {
+	if private, ok := client.(*PrivacyGraphClient); ok {
+		client = private.GraphClient
+	}
+	if federated, ok := client.(*FederatedGraphClient); ok {
+		client = federated.ClientFor(userEmail)
+	}
+	if cached, ok := client.(*CachingGraphClient); ok {
+		client = cached.GraphClient
+	}
+	return client
+}
+
+// GetCalendarView retrieves calendar events for a user within a time range from their provider
+func (f *FederatedGraphClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	return f.ClientFor(userEmail).GetCalendarView(ctx, userEmail, startTime, endTime)
+}
+
+// GetUserEvents retrieves all events for a user within a time range from their provider
+func (f *FederatedGraphClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	return f.ClientFor(userEmail).GetUserEvents(ctx, userEmail, startTime, endTime)
+}
+
+// GetSchedules reads each provider's users' schedules from that provider, in parallel, and merges
+// them in the order of userEmails. A provider that fails marks the schedules of its users unavailable
+func (f *FederatedGraphClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	groups := map[string][]string{}
+	var providers []string
+	for _, email := range userEmails {
+		provider := f.Provider(email)
+		if _, ok := groups[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		groups[provider] = append(groups[provider], email)
+	}
+	if len(providers) == 1 {
+		return f.ClientFor(userEmails[0]).GetSchedules(ctx, userEmails, startTime, endTime, interval)
+	}
+
+	byEmail := map[string]models.Schedule{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, provider := range providers {
+		emails := groups[provider]
+		client := f.ClientFor(emails[0])
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			schedules, err := client.GetSchedules(ctx, emails, startTime, endTime, interval)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Warning: Failed to read %d schedules from %s: %v", len(emails), provider, err)
+				for _, email := range emails {
+					byEmail[strings.ToLower(email)] = models.Schedule{UserEmail: email, Items: []models.ScheduleItem{}, Error: err.Error()}
+				}
+				return
+			}
+			for _, schedule := range schedules {
+				byEmail[strings.ToLower(schedule.UserEmail)] = schedule
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		schedule, ok := byEmail[strings.ToLower(email)]
+		if !ok {
+			schedule = models.Schedule{UserEmail: email, Items: []models.ScheduleItem{}, Error: "schedule unavailable"}
+		}
+		merged = append(merged, schedule)
+	}
+	return merged, nil
+}
+
+// FindMeetingTimes suggests times when the organizer and every attendee are free
+// When everyone is on one provider it answers; otherwise the suggestions come from the merged
+// free/busy schedules, skipping calendars that cannot be read
+func (f *FederatedGraphClient) FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+	participants := append([]string{organizer}, attendees...)
+	provider := f.Provider(organizer)
+	mixed := false
+	for _, email := range attendees {
+		if f.Provider(email) != provider {
+			mixed = true
+			break
+		}
+	}
+	if !mixed {
+		return f.ClientFor(organizer).FindMeetingTimes(ctx, organizer, attendees, duration, startTime, endTime)
+	}
+
+	schedules, err := f.GetSchedules(ctx, participants, startTime, endTime, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	busySlots := make(map[string][]models.TimeSlot)
+	for _, schedule := range schedules {
+		if schedule.Error != "" {
+			log.Printf("Warning: Finding meeting times without the calendar of %s: %s", schedule.UserEmail, schedule.Error)
+			continue
+		}
+		busySlots[schedule.UserEmail] = ScheduleBusyIntervals(schedule)
+	}
+	return commonFreeSlots(busySlots, startTime, endTime, duration), nil
+}
+
+// CreateOnlineMeeting creates an online meeting with the organizer's provider
+func (f *FederatedGraphClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+	return f.ClientFor(organizer).CreateOnlineMeeting(ctx, organizer, start, end, subject, attendees)
+}
+
+// CreateCalendarEvent creates a calendar event with the organizer's provider
+func (f *FederatedGraphClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+	return f.ClientFor(organizer).CreateCalendarEvent(ctx, organizer, event)
+}
+
+// UpdateCalendarEvent updates an event with the organizer's provider
+func (f *FederatedGraphClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	return f.ClientFor(organizer).UpdateCalendarEvent(ctx, organizer, eventID, update)
+}
+
+// CancelCalendarEvent cancels an event with the organizer's provider
+func (f *FederatedGraphClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	return f.ClientFor(organizer).CancelCalendarEvent(ctx, organizer, eventID, comment)
+}
+
+// GetEventResponses reads attendee responses from the organizer's provider
+func (f *FederatedGraphClient) GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error) {
+	return f.ClientFor(organizer).GetEventResponses(ctx, organizer, eventID)
+}
+
+// GetAvailability checks availability for a user within a time range with their provider
+func (f *FederatedGraphClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return f.ClientFor(userEmail).GetAvailability(ctx, userEmail, startTime, endTime)
+}
+
+// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
+func (f *FederatedGraphClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+	return f.ClientFor(userEmail).GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, timezone)
+}
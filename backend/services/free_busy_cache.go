@@ -0,0 +1,509 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of cached calendar reads, named after the GraphClient method that made them
+const (
+	freeBusyKindCalendarView = "calendarView"
+	freeBusyKindEvents       = "events"
+	freeBusyKindSchedule     = "schedule" // Free/busy items only
+)
+
+// freeBusyKinds lists every kind of cached read
+var freeBusyKinds = []string{freeBusyKindCalendarView, freeBusyKindEvents, freeBusyKindSchedule}
+
+// maxFreeBusyEntries bounds how many windows the memory cache keeps per user and kind
+const maxFreeBusyEntries = 16
+
+// FreeBusyCache stores calendar reads per user, scope and window for a limited time
+type FreeBusyCache interface {
+	// Entries returns the unexpired entries of userEmail's calendar of the given kind read with scope
+	Entries(ctx context.Context, userEmail, kind, scope string) ([]models.FreeBusyEntry, error)
+
+	// Put stores entry in place of replaced; it is dropped when the user's calendar was
+	// invalidated after entry.CachedAt
+	Put(ctx context.Context, entry models.FreeBusyEntry, replaced []models.FreeBusyEntry) error
+
+	// Invalidate drops everything cached for the given users, in every scope
+	Invalidate(ctx context.Context, userEmails []string) error
+}
+
+// TokenScope is the cache scope of reads made with a user's delegated accessToken, which only
+// callers with the same token may reuse
+func TokenScope(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return "token:" + hex.EncodeToString(sum[:16])
+}
+
+var (
+	sharedFreeBusyCache     FreeBusyCache
+	sharedFreeBusyCacheOnce sync.Once
+)
+
+// FreeBusyCacheFromEnv returns the process-wide free/busy cache, or nil when it is disabled
+// - FREE_BUSY_CACHE_TTL: Go duration cached reads are reused for (default 2m, 0 disables)
+// - FREE_BUSY_CACHE_BACKEND: "memory" (default) or "postgres" to share the cache between instances
+func FreeBusyCacheFromEnv(cfg *config.Config) FreeBusyCache {
+	sharedFreeBusyCacheOnce.Do(func() {
+		cache, err := newFreeBusyCacheFromEnv(cfg)
+		if err != nil {
+			log.Printf("Warning: Free/busy cache disabled: %v", err)
+			return
+		}
+		sharedFreeBusyCache = cache
+	})
+	return sharedFreeBusyCache
+}
+
+func newFreeBusyCacheFromEnv(cfg *config.Config) (FreeBusyCache, error) {
+	value := getEnvOrDefault("FREE_BUSY_CACHE_TTL", "2m")
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return nil, fmt.Errorf("invalid FREE_BUSY_CACHE_TTL: %s", value)
+	}
+	if ttl == 0 {
+		return nil, nil
+	}
+
+	switch backend := getEnvOrDefault("FREE_BUSY_CACHE_BACKEND", "memory"); backend {
+	case "memory":
+		return newMemoryFreeBusyCache(ttl), nil
+	case "postgres":
+		if cfg == nil || cfg.DB == nil {
+			return nil, fmt.Errorf("FREE_BUSY_CACHE_BACKEND=postgres needs a database")
+		}
+		return &postgresFreeBusyCache{Store: models.NewFreeBusyCacheStore(cfg.DB), TTL: ttl}, nil
+	default:
+		return nil, fmt.Errorf("unknown FREE_BUSY_CACHE_BACKEND: %s", backend)
+	}
+}
+
+// InvalidateFreeBusy drops the cached calendars of the given users, e.g. after Graph reported a change
+func InvalidateFreeBusy(ctx context.Context, cfg *config.Config, userEmails ...string) {
+	cache := FreeBusyCacheFromEnv(cfg)
+	if cache == nil || len(userEmails) == 0 {
+		return
+	}
+	if err := cache.Invalidate(ctx, userEmails); err != nil {
+		log.Printf("Warning: Failed to invalidate cached calendars of %v: %v", userEmails, err)
+	}
+}
+
+// CachingGraphClient reuses recent calendar reads of the wrapped client
+// A read whose window lies inside a cached one is answered from the cache; one that overlaps a
+// cached window only fetches the uncovered part. Reads are only reused within Scope, since what
+// the wrapped client may see depends on its credentials. Meetings created, updated or cancelled
+// through it invalidate the calendars of the organizer and every attendee
+type CachingGraphClient struct {
+	GraphClient
+	Cache FreeBusyCache
+	Scope string // "" when the wrapped client's credentials are the same for every caller
+}
+
+// WithFreeBusyCache wraps client in a CachingGraphClient sharing reads within scope, or returns it
+// unchanged when the cache is disabled
+func WithFreeBusyCache(client GraphClient, cfg *config.Config, scope string) GraphClient {
+	cache := FreeBusyCacheFromEnv(cfg)
+	if cache == nil {
+		return client
+	}
+	return &CachingGraphClient{GraphClient: client, Cache: cache, Scope: scope}
+}
+
+// GetCalendarView retrieves calendar events for a user within a time range, from the cache when possible
+// Truncated reads are passed through without being cached
+func (c *CachingGraphClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	return c.read(ctx, freeBusyKindCalendarView, userEmail, startTime, endTime, func(start, end time.Time) ([]models.Event, bool, error) {
+		return c.GraphClient.GetCalendarView(ctx, userEmail, start, end)
+	})
+}
+
+// GetUserEvents retrieves all events for a user within a time range, from the cache when possible
+// Truncated reads are passed through without being cached
+func (c *CachingGraphClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	return c.read(ctx, freeBusyKindEvents, userEmail, startTime, endTime, func(start, end time.Time) ([]models.Event, bool, error) {
+		return c.GraphClient.GetUserEvents(ctx, userEmail, start, end)
+	})
+}
+
+// GetSchedules reads free/busy schedules, answering each user whose schedule or calendar is
+// cached for the whole window from the cache and asking the wrapped client for the rest
+// Schedules answered from the cache carry no working hours
+func (c *CachingGraphClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	started := time.Now()
+	byEmail := make(map[string]models.Schedule, len(userEmails))
+	var missing []string
+	for _, email := range userEmails {
+		if events, ok := c.cachedWindow(ctx, email, startTime, endTime); ok {
+			byEmail[strings.ToLower(email)] = scheduleFromEvents(email, events, startTime, endTime, interval)
+			continue
+		}
+		missing = append(missing, email)
+	}
+
+	if len(missing) > 0 {
+		schedules, err := c.GraphClient.GetSchedules(ctx, missing, startTime, endTime, interval)
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range schedules {
+			byEmail[strings.ToLower(schedule.UserEmail)] = schedule
+			if schedule.Error == "" {
+				c.put(ctx, models.FreeBusyEntry{
+					UserEmail:   strings.ToLower(schedule.UserEmail),
+					Kind:        freeBusyKindSchedule,
+					Scope:       c.Scope,
+					WindowStart: startTime,
+					WindowEnd:   endTime,
+					Events:      scheduleEvents(schedule),
+					CachedAt:    started,
+				}, nil)
+			}
+		}
+	}
+
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		schedule, ok := byEmail[strings.ToLower(email)]
+		if !ok {
+			schedule = models.Schedule{UserEmail: email, Items: []models.ScheduleItem{}, Error: "schedule unavailable"}
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// cachedWindow returns userEmail's cached events or schedule items overlapping [start, end)
+// from any kind of entry covering the whole window
+func (c *CachingGraphClient) cachedWindow(ctx context.Context, userEmail string, start, end time.Time) ([]models.Event, bool) {
+	for _, kind := range freeBusyKinds {
+		entries, err := c.Cache.Entries(ctx, userEmail, kind, c.Scope)
+		if err != nil {
+			log.Printf("Warning: Failed to read cached calendar of %s: %v", userEmail, err)
+			return nil, false
+		}
+		for _, entry := range entries {
+			if entry.Covers(start, end) {
+				return eventsInWindow(entry.Events, start, end), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// GetAvailability checks availability for a user within a time range (UTC working hours)
+func (c *CachingGraphClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return c.GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, "")
+}
+
+// GetAvailabilityWithTimezone checks availability from the cached calendar view
+func (c *CachingGraphClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+	events, truncated, err := c.GetCalendarView(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return models.AvailabilityResponse{}, err
+	}
+	availability := availabilityFromEvents(userEmail, events, startTime, endTime, timezone)
+	availability.Truncated = truncated
+	return availability, nil
+}
+
+// CreateOnlineMeeting creates a Teams meeting and invalidates the participants' cached calendars
+func (c *CachingGraphClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+	event, err := c.GraphClient.CreateOnlineMeeting(ctx, organizer, start, end, subject, attendees)
+	if err == nil {
+		c.invalidate(ctx, organizer, attendees, event.Attendees)
+	}
+	return event, err
+}
+
+// CreateCalendarEvent creates a calendar event and invalidates the participants' cached calendars
+func (c *CachingGraphClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+	created, err := c.GraphClient.CreateCalendarEvent(ctx, organizer, event)
+	if err == nil {
+		c.invalidate(ctx, organizer, event.Attendees, created.Attendees)
+	}
+	return created, err
+}
+
+// UpdateCalendarEvent updates an event and invalidates the cached calendars of its current
+// attendees and of those the update removed, as far as the cache knew them
+func (c *CachingGraphClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	previous := c.cachedAttendees(ctx, organizer, eventID)
+	event, err := c.GraphClient.UpdateCalendarEvent(ctx, organizer, eventID, update)
+	if err == nil {
+		c.invalidate(ctx, organizer, previous, update.Attendees, event.Attendees)
+	}
+	return event, err
+}
+
+// CancelCalendarEvent cancels an event and invalidates the participants' cached calendars
+func (c *CachingGraphClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	previous := c.cachedAttendees(ctx, organizer, eventID)
+	event, err := c.GraphClient.CancelCalendarEvent(ctx, organizer, eventID, comment)
+	if err == nil {
+		c.invalidate(ctx, organizer, previous, event.Attendees)
+	}
+	return event, err
+}
+
+// read answers a read of [start, end) from the cache, calling fetch for whatever it does not hold
+// An entry extended by a fetch counts as cached when that fetch started
+func (c *CachingGraphClient) read(ctx context.Context, kind, userEmail string, start, end time.Time, fetch func(start, end time.Time) ([]models.Event, bool, error)) ([]models.Event, bool, error) {
+	started := time.Now()
+	entries, err := c.Cache.Entries(ctx, userEmail, kind, c.Scope)
+	if err != nil {
+		log.Printf("Warning: Failed to read cached calendar of %s: %v", userEmail, err)
+		return fetch(start, end)
+	}
+
+	for _, entry := range entries {
+		if entry.Covers(start, end) {
+			return eventsInWindow(entry.Events, start, end), false, nil
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.Overlaps(start, end) {
+			continue
+		}
+
+		// Fetch only the parts of the window the entry does not cover
+		merged := entry
+		merged.Events = append([]models.Event{}, entry.Events...)
+		merged.CachedAt = started
+		truncated := false
+		for _, gap := range []models.TimeSlot{{Start: start, End: entry.WindowStart}, {Start: entry.WindowEnd, End: end}} {
+			if !gap.Start.Before(gap.End) {
+				continue
+			}
+			events, cut, err := fetch(gap.Start, gap.End)
+			if err != nil {
+				return nil, false, err
+			}
+			truncated = truncated || cut
+			merged.Events = mergeEvents(merged.Events, events)
+		}
+		if start.Before(merged.WindowStart) {
+			merged.WindowStart = start
+		}
+		if end.After(merged.WindowEnd) {
+			merged.WindowEnd = end
+		}
+		if !truncated {
+			c.put(ctx, merged, []models.FreeBusyEntry{entry})
+		}
+		return eventsInWindow(merged.Events, start, end), truncated, nil
+	}
+
+	events, truncated, err := fetch(start, end)
+	if err == nil && !truncated {
+		c.put(ctx, models.FreeBusyEntry{
+			UserEmail:   strings.ToLower(userEmail),
+			Kind:        kind,
+			Scope:       c.Scope,
+			WindowStart: start,
+			WindowEnd:   end,
+			Events:      events,
+			CachedAt:    started,
+		}, nil)
+	}
+	return events, truncated, err
+}
+
+func (c *CachingGraphClient) put(ctx context.Context, entry models.FreeBusyEntry, replaced []models.FreeBusyEntry) {
+	if err := c.Cache.Put(ctx, entry, replaced); err != nil {
+		log.Printf("Warning: Failed to cache calendar of %s: %v", entry.UserEmail, err)
+	}
+}
+
+// cachedAttendees returns the attendees the organizer's cached calendar lists for eventID
+func (c *CachingGraphClient) cachedAttendees(ctx context.Context, organizer, eventID string) []string {
+	for _, kind := range []string{freeBusyKindEvents, freeBusyKindCalendarView} {
+		entries, err := c.Cache.Entries(ctx, organizer, kind, c.Scope)
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			for _, event := range entry.Events {
+				if event.ID == eventID {
+					return event.Attendees
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// invalidate drops the cached calendars of the organizer and every listed attendee
+func (c *CachingGraphClient) invalidate(ctx context.Context, organizer string, attendeeLists ...[]string) {
+	users := []string{organizer}
+	for _, attendees := range attendeeLists {
+		users = append(users, attendees...)
+	}
+	if err := c.Cache.Invalidate(ctx, users); err != nil {
+		log.Printf("Warning: Failed to invalidate cached calendars of %v: %v", users, err)
+	}
+}
+
+// eventsInWindow returns the events overlapping [start, end) in a new slice
+func eventsInWindow(events []models.Event, start, end time.Time) []models.Event {
+	var inWindow []models.Event
+	for _, event := range events {
+		if event.Start.Before(end) && event.End.After(start) {
+			inWindow = append(inWindow, event)
+		}
+	}
+	return inWindow
+}
+
+// mergeEvents appends the events of more that are not already in events
+// Events spanning the boundary between two reads are returned by both
+func mergeEvents(events, more []models.Event) []models.Event {
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		seen[eventMergeKey(event)] = true
+	}
+	for _, event := range more {
+		if key := eventMergeKey(event); !seen[key] {
+			seen[key] = true
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func eventMergeKey(event models.Event) string {
+	if event.ID != "" {
+		return event.ID
+	}
+	return event.Start.UTC().Format(time.RFC3339) + "|" + event.Subject
+}
+
+// memoryFreeBusyCache keeps cached reads in this process
+type memoryFreeBusyCache struct {
+	ttl         time.Duration
+	mu          sync.Mutex
+	entries     map[string]map[string][]models.FreeBusyEntry // By lowercase email, then kind and scope
+	invalidated map[string]time.Time                         // Last invalidation per lowercase email
+}
+
+func newMemoryFreeBusyCache(ttl time.Duration) *memoryFreeBusyCache {
+	return &memoryFreeBusyCache{
+		ttl:         ttl,
+		entries:     map[string]map[string][]models.FreeBusyEntry{},
+		invalidated: map[string]time.Time{},
+	}
+}
+
+func (m *memoryFreeBusyCache) Entries(ctx context.Context, userEmail, kind, scope string) ([]models.FreeBusyEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, key := strings.ToLower(userEmail), freeBusyCacheKey(kind, scope)
+	fresh := m.unexpired(m.entries[user][key])
+	if m.entries[user] != nil {
+		m.entries[user][key] = fresh
+	}
+	return append([]models.FreeBusyEntry{}, fresh...), nil
+}
+
+func (m *memoryFreeBusyCache) Put(ctx context.Context, entry models.FreeBusyEntry, replaced []models.FreeBusyEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := strings.ToLower(entry.UserEmail)
+	if !entry.CachedAt.After(m.invalidated[user]) {
+		return nil
+	}
+
+	key := freeBusyCacheKey(entry.Kind, entry.Scope)
+	var kept []models.FreeBusyEntry
+	for _, existing := range m.unexpired(m.entries[user][key]) {
+		if !sameFreeBusyWindow(existing, entry) && !containsFreeBusyWindow(replaced, existing) {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, entry)
+	if len(kept) > maxFreeBusyEntries {
+		kept = kept[len(kept)-maxFreeBusyEntries:]
+	}
+	if m.entries[user] == nil {
+		m.entries[user] = map[string][]models.FreeBusyEntry{}
+	}
+	m.entries[user][key] = kept
+	return nil
+}
+
+func (m *memoryFreeBusyCache) Invalidate(ctx context.Context, userEmails []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for email, at := range m.invalidated {
+		if now.Sub(at) > m.ttl {
+			delete(m.invalidated, email)
+		}
+	}
+	for _, email := range userEmails {
+		email = strings.ToLower(email)
+		m.invalidated[email] = now
+		delete(m.entries, email)
+	}
+	return nil
+}
+
+func (m *memoryFreeBusyCache) unexpired(entries []models.FreeBusyEntry) []models.FreeBusyEntry {
+	var fresh []models.FreeBusyEntry
+	for _, entry := range entries {
+		if time.Since(entry.CachedAt) < m.ttl {
+			fresh = append(fresh, entry)
+		}
+	}
+	return fresh
+}
+
+func freeBusyCacheKey(kind, scope string) string {
+	return kind + "|" + scope
+}
+
+func sameFreeBusyWindow(a, b models.FreeBusyEntry) bool {
+	return a.WindowStart.Equal(b.WindowStart) && a.WindowEnd.Equal(b.WindowEnd)
+}
+
+func containsFreeBusyWindow(entries []models.FreeBusyEntry, entry models.FreeBusyEntry) bool {
+	for _, e := range entries {
+		if sameFreeBusyWindow(e, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresFreeBusyCache keeps cached reads in free_busy_cache, shared by every instance
+type postgresFreeBusyCache struct {
+	Store *models.FreeBusyCacheStore
+	TTL   time.Duration
+}
+
+func (p *postgresFreeBusyCache) Entries(ctx context.Context, userEmail, kind, scope string) ([]models.FreeBusyEntry, error) {
+	return p.Store.GetEntries(ctx, userEmail, kind, scope, time.Now().Add(-p.TTL))
+}
+
+func (p *postgresFreeBusyCache) Put(ctx context.Context, entry models.FreeBusyEntry, replaced []models.FreeBusyEntry) error {
+	return p.Store.SaveEntry(ctx, entry, replaced, time.Now().Add(-p.TTL))
+}
+
+func (p *postgresFreeBusyCache) Invalidate(ctx context.Context, userEmails []string) error {
+	return p.Store.InvalidateUsers(ctx, userEmails, time.Now())
+}
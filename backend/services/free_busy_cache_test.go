@@ -0,0 +1,207 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scheduleClient answers GetSchedules from fixed items and records who it was asked about
+type scheduleClient struct {
+	GraphClient
+	items map[string][]models.ScheduleItem
+	asked [][]string
+}
+
+func (s *scheduleClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	s.asked = append(s.asked, userEmails)
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		items, ok := s.items[strings.ToLower(email)]
+		if !ok {
+			schedules = append(schedules, models.Schedule{UserEmail: email, Items: []models.ScheduleItem{}, Error: "mailbox not found"})
+			continue
+		}
+		schedules = append(schedules, models.Schedule{UserEmail: email, Items: items})
+	}
+	return schedules, nil
+}
+
+func TestCachingGraphClientGetSchedules(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	busy := models.ScheduleItem{Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour), Status: models.ShowAsBusy}
+
+	tests := []struct {
+		name      string
+		cached    []models.FreeBusyEntry
+		users     []string
+		start     time.Time
+		end       time.Time
+		wantAsked [][]string
+		wantItems []int // Items per returned schedule, -1 for an error
+	}{
+		{
+			name:      "nothing cached",
+			users:     []string{"alice@gruve.ai", "bob@gruve.ai"},
+			start:     day,
+			end:       day.Add(24 * time.Hour),
+			wantAsked: [][]string{{"alice@gruve.ai", "bob@gruve.ai"}},
+			wantItems: []int{1, 0},
+		},
+		{
+			name: "calendar cached for one user",
+			cached: []models.FreeBusyEntry{{
+				UserEmail: "alice@gruve.ai", Kind: freeBusyKindEvents, WindowStart: day, WindowEnd: day.Add(48 * time.Hour),
+				Events: []models.Event{{ID: "1", Subject: "Standup", Start: busy.Start, End: busy.End, ShowAs: models.ShowAsBusy}},
+			}},
+			users:     []string{"alice@gruve.ai", "bob@gruve.ai"},
+			start:     day,
+			end:       day.Add(24 * time.Hour),
+			wantAsked: [][]string{{"bob@gruve.ai"}},
+			wantItems: []int{1, 0},
+		},
+		{
+			name: "cached window too short",
+			cached: []models.FreeBusyEntry{{
+				UserEmail: "alice@gruve.ai", Kind: freeBusyKindSchedule, WindowStart: day, WindowEnd: day.Add(12 * time.Hour),
+			}},
+			users:     []string{"alice@gruve.ai"},
+			start:     day,
+			end:       day.Add(24 * time.Hour),
+			wantAsked: [][]string{{"alice@gruve.ai"}},
+			wantItems: []int{1},
+		},
+		{
+			name:      "unknown user is reported, not cached",
+			users:     []string{"nobody@gruve.ai"},
+			start:     day,
+			end:       day.Add(24 * time.Hour),
+			wantAsked: [][]string{{"nobody@gruve.ai"}, {"nobody@gruve.ai"}},
+			wantItems: []int{-1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &scheduleClient{items: map[string][]models.ScheduleItem{
+				"alice@gruve.ai": {busy},
+				"bob@gruve.ai":   {},
+			}}
+			cache := newMemoryFreeBusyCache(time.Minute)
+			for _, entry := range tt.cached {
+				entry.CachedAt = time.Now()
+				cache.Put(context.Background(), entry, nil)
+			}
+			client := &CachingGraphClient{GraphClient: backend, Cache: cache}
+
+			// The second read of the same window must not reach the backend for cacheable schedules
+			var schedules []models.Schedule
+			for i := 0; i < 2; i++ {
+				var err error
+				schedules, err = client.GetSchedules(context.Background(), tt.users, tt.start, tt.end, 0)
+				if err != nil {
+					t.Fatalf("GetSchedules() error = %v", err)
+				}
+			}
+
+			if len(backend.asked) != len(tt.wantAsked) {
+				t.Fatalf("backend asked %v, want %v", backend.asked, tt.wantAsked)
+			}
+			for i := range tt.wantAsked {
+				if strings.Join(backend.asked[i], ",") != strings.Join(tt.wantAsked[i], ",") {
+					t.Errorf("backend call %d asked %v, want %v", i, backend.asked[i], tt.wantAsked[i])
+				}
+			}
+			if len(schedules) != len(tt.users) {
+				t.Fatalf("got %d schedules, want %d", len(schedules), len(tt.users))
+			}
+			for i, schedule := range schedules {
+				if schedule.UserEmail != tt.users[i] {
+					t.Errorf("schedule %d is for %s, want %s", i, schedule.UserEmail, tt.users[i])
+				}
+				got := len(schedule.Items)
+				if schedule.Error != "" {
+					got = -1
+				}
+				if got != tt.wantItems[i] {
+					t.Errorf("schedule of %s has %d items, want %d", schedule.UserEmail, got, tt.wantItems[i])
+				}
+			}
+		})
+	}
+}
+
+// eventsClient answers GetUserEvents with the events visible to its caller and counts the reads
+type eventsClient struct {
+	GraphClient
+	events []models.Event
+	reads  int
+}
+
+func (e *eventsClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	e.reads++
+	return eventsInWindow(e.events, startTime, endTime), false, nil
+}
+
+func TestCachingGraphClientScopes(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	private := models.Event{ID: "1", Subject: "Interview", Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour)}
+	cache := newMemoryFreeBusyCache(time.Minute)
+
+	tests := []struct {
+		name      string
+		scope     string
+		visible   []models.Event
+		wantReads int
+		wantCount int
+	}{
+		{"delegate who may read the calendar", TokenScope("delegate-token"), []models.Event{private}, 1, 1},
+		{"same token reuses the read", TokenScope("delegate-token"), nil, 0, 1},
+		{"other token reads for itself", TokenScope("colleague-token"), nil, 1, 0},
+		{"deployment credentials", "", nil, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &eventsClient{events: tt.visible}
+			client := &CachingGraphClient{GraphClient: backend, Cache: cache, Scope: tt.scope}
+
+			events, _, err := client.GetUserEvents(context.Background(), "alice@gruve.ai", day, day.Add(24*time.Hour))
+			if err != nil {
+				t.Fatalf("GetUserEvents() error = %v", err)
+			}
+			if backend.reads != tt.wantReads || len(events) != tt.wantCount {
+				t.Errorf("read %d times and got %d events, want %d reads and %d events", backend.reads, len(events), tt.wantReads, tt.wantCount)
+			}
+		})
+	}
+
+	// Invalidation drops the calendar in every scope
+	if err := cache.Invalidate(context.Background(), []string{"Alice@gruve.ai"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := cache.Entries(context.Background(), "alice@gruve.ai", freeBusyKindEvents, TokenScope("delegate-token")); len(entries) != 0 {
+		t.Errorf("%d entries left after invalidation", len(entries))
+	}
+}
+
+func TestCachingGraphClientMergeRefreshesCachedAt(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	cache := newMemoryFreeBusyCache(time.Minute)
+	old := time.Now().Add(-50 * time.Second)
+	cache.Put(context.Background(), models.FreeBusyEntry{UserEmail: "alice@gruve.ai", Kind: freeBusyKindEvents, WindowStart: day, WindowEnd: day.Add(24 * time.Hour), CachedAt: old}, nil)
+	client := &CachingGraphClient{GraphClient: &eventsClient{}, Cache: cache}
+
+	before := time.Now()
+	if _, _, err := client.GetUserEvents(context.Background(), "alice@gruve.ai", day, day.Add(48*time.Hour)); err != nil {
+		t.Fatalf("GetUserEvents() error = %v", err)
+	}
+
+	entries, _ := cache.Entries(context.Background(), "alice@gruve.ai", freeBusyKindEvents, "")
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the merged one", len(entries))
+	}
+	if !entries[0].WindowEnd.Equal(day.Add(48*time.Hour)) || entries[0].CachedAt.Before(before) {
+		t.Errorf("merged entry covers until %s cached at %s, want until %s cached after %s", entries[0].WindowEnd, entries[0].CachedAt, day.Add(48*time.Hour), before)
+	}
+}
@@ -0,0 +1,859 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/utils"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"golang.org/x/oauth2/jwt"
+)
+
+// defaultGoogleCalendarAPIBase is the root of the Google Calendar API v3
+const defaultGoogleCalendarAPIBase = "https://www.googleapis.com/calendar/v3"
+
+// maxFreeBusyCalendars is how many calendars one freeBusy query may ask for
+const maxFreeBusyCalendars = 50
+
+// GoogleCalendarClient implements GraphClient using the Google Calendar API v3
+// Users' calendars are addressed by their email, which is the ID of their primary calendar;
+// meetings are written to the primary calendar of their organizer, so Google makes that user
+// the organizer
+type GoogleCalendarClient struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Config     *config.Config
+	// Impersonate returns the tokens of a user under domain-wide delegation; requests about a
+	// user's calendar are then sent as that user. Without it every request is sent with
+	// HTTPClient's account, which needs write access to the organizers' calendars
+	Impersonate func(userEmail string) oauth2.TokenSource
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewGoogleCalendarClient creates a GoogleCalendarClient that authorizes requests with tokens
+// Throttled requests are retried under the GOOGLE_ retry settings and counted apart from Graph's
+func NewGoogleCalendarClient(tokens oauth2.TokenSource, baseURL string, cfg *config.Config) *GoogleCalendarClient {
+	return &GoogleCalendarClient{
+		HTTPClient: &http.Client{Transport: &oauth2.Transport{Source: tokens, Base: utils.NewRetryTransport(nil, utils.RetryServiceGoogle)}},
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Config:     cfg,
+	}
+}
+
+// NewDelegatedGoogleCalendarClient creates a GoogleCalendarClient that sends requests about each
+// user's calendar as that user, with the tokens impersonate returns for them
+func NewDelegatedGoogleCalendarClient(impersonate func(userEmail string) oauth2.TokenSource, baseURL string, cfg *config.Config) *GoogleCalendarClient {
+	client := NewGoogleCalendarClient(missingTokenSource{}, baseURL, cfg)
+	client.Impersonate = impersonate
+	return client
+}
+
+// NewGoogleCalendarClientFromEnv creates a GoogleCalendarClient from environment variables
+// Without credentials every call fails, as the Gmail sender does without GMAIL_EMAIL
+// - GOOGLE_CALENDAR_API_BASE: API root (default https://www.googleapis.com/calendar/v3)
+// - GOOGLE_SERVICE_ACCOUNT_FILE: JSON key of a service account with domain-wide delegation, which acts as each user
+// - GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET, GOOGLE_REFRESH_TOKEN: OAuth client and an account with write access to every organizer's calendar
+// - GOOGLE_TOKEN_URL: OAuth token endpoint (default Google's)
+// - GOOGLE_ACCESS_TOKEN: fixed access token used instead of the refresh token, e.g. against a stand-in
+func NewGoogleCalendarClientFromEnv(cfg *config.Config) *GoogleCalendarClient {
+	baseURL := getEnvOrDefault("GOOGLE_CALENDAR_API_BASE", defaultGoogleCalendarAPIBase)
+
+	if accessToken := os.Getenv("GOOGLE_ACCESS_TOKEN"); accessToken != "" {
+		return NewGoogleCalendarClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}), baseURL, cfg)
+	}
+
+	endpoint := endpoints.Google
+	endpoint.TokenURL = getEnvOrDefault("GOOGLE_TOKEN_URL", endpoint.TokenURL)
+
+	if keyFile := os.Getenv("GOOGLE_SERVICE_ACCOUNT_FILE"); keyFile != "" {
+		impersonate, err := serviceAccountImpersonation(keyFile, endpoint.TokenURL)
+		if err != nil {
+			log.Printf("WARNING: Failed to load Google service account key: %v", err)
+			return NewGoogleCalendarClient(missingTokenSource{}, baseURL, cfg)
+		}
+		return NewDelegatedGoogleCalendarClient(impersonate, baseURL, cfg)
+	}
+
+	clientID := os.Getenv("GOOGLE_CLIENT_ID")
+	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
+	refreshToken := os.Getenv("GOOGLE_REFRESH_TOKEN")
+	if clientID == "" || clientSecret == "" || refreshToken == "" {
+		log.Println("WARNING: Google Calendar credentials not set (GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET and GOOGLE_REFRESH_TOKEN)")
+		return NewGoogleCalendarClient(missingTokenSource{}, baseURL, cfg)
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint:     endpoint,
+		Scopes:       []string{"https://www.googleapis.com/auth/calendar"},
+	}
+	tokens := oauthConfig.TokenSource(context.Background(), &oauth2.Token{RefreshToken: refreshToken})
+	return NewGoogleCalendarClient(tokens, baseURL, cfg)
+}
+
+// serviceAccountImpersonation reads a service account's JSON key and returns the tokens it gets
+// for each user through domain-wide delegation
+func serviceAccountImpersonation(keyFile, defaultTokenURL string) (func(userEmail string) oauth2.TokenSource, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	var key struct {
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %v", keyFile, err)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("key file %s has no client_email or private_key", keyFile)
+	}
+	tokenURL := key.TokenURI
+	if tokenURL == "" || os.Getenv("GOOGLE_TOKEN_URL") != "" {
+		tokenURL = defaultTokenURL
+	}
+
+	return func(userEmail string) oauth2.TokenSource {
+		delegated := &jwt.Config{
+			Email:        key.ClientEmail,
+			PrivateKey:   []byte(key.PrivateKey),
+			PrivateKeyID: key.PrivateKeyID,
+			Subject:      userEmail,
+			Scopes:       []string{"https://www.googleapis.com/auth/calendar"},
+			TokenURL:     tokenURL,
+		}
+		return delegated.TokenSource(context.Background())
+	}, nil
+}
+
+// missingTokenSource fails every request of a client configured without credentials
+type missingTokenSource struct{}
+
+func (missingTokenSource) Token() (*oauth2.Token, error) {
+	return nil, fmt.Errorf("google calendar credentials not configured (GOOGLE_ACCESS_TOKEN or GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET and GOOGLE_REFRESH_TOKEN required)")
+}
+
+// GetCalendarView retrieves calendar events for a user within a time range
+func (g *GoogleCalendarClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	items, truncated, err := g.readEvents(ctx, userEmail, startTime, endTime, GraphPagingFromEnv())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get calendar view: %v", err)
+	}
+	return g.convertEvents(ctx, userEmail, items, startTime, endTime), truncated, nil
+}
+
+// GetUserEvents retrieves all events for a user within a time range
+// Every page is read up to GRAPH_MAX_ITEMS events; the returned flag reports that the rest were dropped
+func (g *GoogleCalendarClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	items, truncated, err := g.readEvents(ctx, userEmail, startTime, endTime, GraphPagingFromEnv())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user events for %s: %v", userEmail, err)
+	}
+	return g.convertEvents(ctx, userEmail, items, startTime, endTime), truncated, nil
+}
+
+// readEvents lists the single events of calendarID overlapping [startTime, endTime), following
+// nextPageToken until paging.MaxItems events
+func (g *GoogleCalendarClient) readEvents(ctx context.Context, calendarID string, startTime, endTime time.Time, paging GraphPaging) ([]googleEvent, bool, error) {
+	query := url.Values{
+		"timeMin":      {startTime.Format(time.RFC3339)},
+		"timeMax":      {endTime.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {strconv.Itoa(int(paging.PageSize))},
+	}
+
+	var items []googleEvent
+	for {
+		var page struct {
+			Items         []googleEvent `json:"items"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+		if err := g.do(ctx, calendarID, http.MethodGet, googleEventsPath(calendarID), query, nil, &page); err != nil {
+			return nil, false, err
+		}
+		for _, item := range page.Items {
+			if len(items) >= paging.MaxItems {
+				return items, true, nil
+			}
+			items = append(items, item)
+		}
+		if page.NextPageToken == "" {
+			return items, false, nil
+		}
+		if len(items) >= paging.MaxItems {
+			return items, true, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// convertEvents converts the events of userEmail's calendar that overlap [startTime, endTime)
+func (g *GoogleCalendarClient) convertEvents(ctx context.Context, userEmail string, items []googleEvent, startTime, endTime time.Time) []models.Event {
+	loc := g.userLocation(ctx, userEmail)
+	events := []models.Event{}
+	for _, item := range items {
+		if item.Status == "cancelled" {
+			continue
+		}
+		event, err := convertGoogleEvent(item, loc)
+		if err != nil {
+			log.Printf("Warning: Skipping event %s of %s: %v", item.ID, userEmail, err)
+			continue
+		}
+		// All-day events are re-anchored to the user's zone and may fall outside the range
+		if !event.Start.Before(endTime) || !event.End.After(startTime) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// GetSchedules reads the free/busy schedules of users with the freeBusy query
+// Google reports busy periods only, so every item is busy and working hours are assumed to be
+// 9am-6pm on weekdays in each user's own time zone
+func (g *GoogleCalendarClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	interval = scheduleInterval(interval)
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for len(userEmails) > 0 {
+		batch := userEmails
+		if len(batch) > maxFreeBusyCalendars {
+			batch = batch[:maxFreeBusyCalendars]
+		}
+		userEmails = userEmails[len(batch):]
+
+		request := googleFreeBusyRequest{
+			TimeMin: startTime.Format(time.RFC3339),
+			TimeMax: endTime.Format(time.RFC3339),
+		}
+		for _, email := range batch {
+			request.Items = append(request.Items, googleCalendarID{ID: email})
+		}
+		var response googleFreeBusyResponse
+		// Free/busy is asked as the first user of the batch, who sees what the domain shares
+		if err := g.do(ctx, batch[0], http.MethodPost, "/freeBusy", nil, request, &response); err != nil {
+			return nil, fmt.Errorf("failed to get schedules: %v", err)
+		}
+
+		for _, email := range batch {
+			schedules = append(schedules, convertGoogleFreeBusy(email, response.Calendars, startTime, endTime, interval, g.userLocation(ctx, email)))
+		}
+	}
+	return schedules, nil
+}
+
+// convertGoogleFreeBusy builds email's schedule from a freeBusy response
+func convertGoogleFreeBusy(email string, calendars map[string]googleFreeBusyCalendar, startTime, endTime time.Time, interval time.Duration, loc *time.Location) models.Schedule {
+	schedule := models.Schedule{
+		UserEmail:    email,
+		Items:        []models.ScheduleItem{},
+		WorkingHours: standardWorkingHours(loc),
+	}
+
+	calendar, ok := calendars[email]
+	if !ok {
+		for id, c := range calendars {
+			if strings.EqualFold(id, email) {
+				calendar, ok = c, true
+				break
+			}
+		}
+	}
+	if !ok {
+		schedule.Error = "schedule unavailable"
+		return schedule
+	}
+	if len(calendar.Errors) > 0 {
+		schedule.Error = calendar.Errors[0].Reason
+		return schedule
+	}
+
+	for _, period := range calendar.Busy {
+		start, err := time.Parse(time.RFC3339, period.Start)
+		if err != nil {
+			log.Printf("Warning: Skipping busy period of %s: invalid start %q", email, period.Start)
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, period.End)
+		if err != nil {
+			log.Printf("Warning: Skipping busy period of %s: invalid end %q", email, period.End)
+			continue
+		}
+		schedule.Items = append(schedule.Items, models.ScheduleItem{Start: start.UTC(), End: end.UTC(), Status: models.ShowAsBusy})
+	}
+	schedule.AvailabilityView = availabilityView(schedule.Items, startTime, endTime, interval)
+	return schedule
+}
+
+// FindMeetingTimes suggests times when the organizer and every attendee are free
+// Google has no equivalent of findMeetingTimes, so the suggestions come from their free/busy schedules
+func (g *GoogleCalendarClient) FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+	schedules, err := g.GetSchedules(ctx, append([]string{organizer}, attendees...), startTime, endTime, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find meeting times: %v", err)
+	}
+
+	busySlots := make(map[string][]models.TimeSlot)
+	for _, schedule := range schedules {
+		if schedule.Error != "" {
+			continue // Skip calendars that cannot be read, as the mock skips unknown users
+		}
+		busySlots[schedule.UserEmail] = ScheduleBusyIntervals(schedule)
+	}
+	return commonFreeSlots(busySlots, startTime, endTime, duration), nil
+}
+
+// CreateOnlineMeeting creates a calendar event with a Google Meet link
+func (g *GoogleCalendarClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+	return g.CreateCalendarEvent(ctx, organizer, models.CreateMeetingRequest{
+		Subject:   subject,
+		Start:     start,
+		End:       end,
+		Attendees: attendees,
+		IsOnline:  true,
+	})
+}
+
+// CreateCalendarEvent creates an event in the organizer's primary calendar, which makes them its organizer
+// Online meetings get a Google Meet link; Google emails the invitations to attendees
+func (g *GoogleCalendarClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	loc := g.userLocation(ctx, organizer)
+	body := googleEvent{
+		Summary:     event.Subject,
+		Description: event.Description,
+		Location:    event.Location,
+		Start:       newGoogleEventTime(event.Start, loc),
+		End:         newGoogleEventTime(event.End, loc),
+		Visibility:  googleVisibility(event.Sensitivity),
+	}
+	for _, email := range event.Attendees {
+		body.Attendees = append(body.Attendees, googleAttendee{Email: email})
+	}
+
+	query := url.Values{"sendUpdates": {"all"}}
+	if event.IsOnline {
+		body.ConferenceData = &googleConferenceData{
+			CreateRequest: &googleCreateConferenceRequest{
+				RequestID:             uuid.New().String(),
+				ConferenceSolutionKey: googleConferenceSolutionKey{Type: "hangoutsMeet"},
+			},
+		}
+		query.Set("conferenceDataVersion", "1")
+	}
+
+	var created googleEvent
+	if err := g.do(ctx, organizer, http.MethodPost, googleEventsPath(organizer), query, body, &created); err != nil {
+		return models.Event{}, fmt.Errorf("failed to create calendar event: %v", err)
+	}
+
+	result, err := convertGoogleEvent(created, loc)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to read created calendar event: %v", err)
+	}
+	if event.Sensitivity != "" {
+		result.Sensitivity = event.Sensitivity
+	}
+	return result, nil
+}
+
+// UpdateCalendarEvent patches an event in the organizer's calendar that they organize
+// Google emails the update to attendees
+func (g *GoogleCalendarClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	if _, err := g.getOwnedEvent(ctx, organizer, eventID); err != nil {
+		return models.Event{}, err
+	}
+
+	// A map keeps explicitly cleared fields, such as an emptied attendee list, in the patch
+	loc := g.userLocation(ctx, organizer)
+	patch := map[string]interface{}{}
+	if update.Subject != nil {
+		patch["summary"] = *update.Subject
+	}
+	if update.Start != nil {
+		patch["start"] = newGoogleEventTime(*update.Start, loc)
+	}
+	if update.End != nil {
+		patch["end"] = newGoogleEventTime(*update.End, loc)
+	}
+	if update.Description != nil {
+		patch["description"] = *update.Description
+	}
+	if update.Location != nil {
+		patch["location"] = *update.Location
+	}
+	if update.Attendees != nil {
+		attendees := []googleAttendee{}
+		for _, email := range update.Attendees {
+			attendees = append(attendees, googleAttendee{Email: email})
+		}
+		patch["attendees"] = attendees
+	}
+
+	var updated googleEvent
+	err := g.do(ctx, organizer, http.MethodPatch, googleEventsPath(organizer)+"/"+url.PathEscape(eventID), url.Values{"sendUpdates": {"all"}}, patch, &updated)
+	if err != nil {
+		return models.Event{}, wrapGoogleEventError("update calendar event", err)
+	}
+
+	event, err := convertGoogleEvent(updated, loc)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to read updated calendar event: %v", err)
+	}
+	return event, nil
+}
+
+// CancelCalendarEvent deletes an event in the organizer's calendar that they organize and returns it
+// Google emails the cancellation to attendees; it has no place for the comment, which is dropped
+func (g *GoogleCalendarClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	existing, err := g.getOwnedEvent(ctx, organizer, eventID)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	err = g.do(ctx, organizer, http.MethodDelete, googleEventsPath(organizer)+"/"+url.PathEscape(eventID), url.Values{"sendUpdates": {"all"}}, nil, nil)
+	if err != nil {
+		return models.Event{}, wrapGoogleEventError("cancel calendar event", err)
+	}
+
+	event, err := convertGoogleEvent(existing, g.userLocation(ctx, organizer))
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to read cancelled calendar event: %v", err)
+	}
+	// Cancellation notices must supersede the last update
+	event.Sequence++
+	return event, nil
+}
+
+// GetEventResponses reads attendee response statuses from an event in the organizer's calendar that they organize
+// Google does not say when attendees responded
+func (g *GoogleCalendarClient) GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	item, err := g.getOwnedEvent(ctx, organizer, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.AttendeeResponse{}
+	for _, attendee := range item.Attendees {
+		if attendee.Email == "" || attendee.Organizer {
+			continue
+		}
+		responses = append(responses, models.AttendeeResponse{
+			Email:  strings.ToLower(attendee.Email),
+			Status: convertGoogleResponseStatus(attendee.ResponseStatus),
+		})
+	}
+	return responses, nil
+}
+
+// getOwnedEvent loads an event from the organizer's primary calendar and checks that they organize it
+// An attendee's copy of someone else's meeting names its real organizer and is refused
+func (g *GoogleCalendarClient) getOwnedEvent(ctx context.Context, organizer string, eventID string) (googleEvent, error) {
+	var item googleEvent
+	if err := g.do(ctx, organizer, http.MethodGet, googleEventsPath(organizer)+"/"+url.PathEscape(eventID), nil, nil, &item); err != nil {
+		return googleEvent{}, wrapGoogleEventError("load calendar event", err)
+	}
+	if item.Status == "cancelled" {
+		return googleEvent{}, ErrEventNotFound
+	}
+	if item.Organizer == nil || !strings.EqualFold(item.Organizer.Email, organizer) {
+		return googleEvent{}, ErrNotOrganizer
+	}
+	return item, nil
+}
+
+// GetAvailability checks availability for a user within a time range (UTC working hours)
+func (g *GoogleCalendarClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return g.GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, "")
+}
+
+// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
+func (g *GoogleCalendarClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+	events, truncated, err := g.GetCalendarView(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return models.AvailabilityResponse{}, err
+	}
+	availability := availabilityFromEvents(userEmail, events, startTime, endTime, timezone)
+	availability.Truncated = truncated
+	return availability, nil
+}
+
+// userLocation returns the time zone of userEmail's all-day events and of the meetings they create
+func (g *GoogleCalendarClient) userLocation(ctx context.Context, userEmail string) *time.Location {
+	var db *sql.DB
+	if g.Config != nil {
+		db = g.Config.DB
+	}
+	return UserLocation(ctx, db, userEmail)
+}
+
+// httpClient returns the client requests about userEmail's calendar are sent with
+func (g *GoogleCalendarClient) httpClient(userEmail string) *http.Client {
+	if g.Impersonate == nil {
+		return g.HTTPClient
+	}
+
+	key := strings.ToLower(userEmail)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	client, ok := g.clients[key]
+	if !ok {
+		client = &http.Client{Transport: &oauth2.Transport{Source: g.Impersonate(key), Base: utils.NewRetryTransport(nil, utils.RetryServiceGoogle)}}
+		if g.clients == nil {
+			g.clients = map[string]*http.Client{}
+		}
+		g.clients[key] = client
+	}
+	return client
+}
+
+// do sends a Calendar API request about userEmail's calendar with body encoded as JSON, and
+// decodes the response into out
+// Responses other than 2xx are returned as a *GoogleAPIError
+func (g *GoogleCalendarClient) do(ctx context.Context, userEmail, method, path string, query url.Values, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := g.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient(userEmail).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newGoogleAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// GoogleAPIError is an error response of the Google Calendar API
+type GoogleAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GoogleAPIError) Error() string {
+	return fmt.Sprintf("google calendar returned %d: %s", e.StatusCode, e.Message)
+}
+
+// newGoogleAPIError reads the error message from a failed response
+func newGoogleAPIError(resp *http.Response) *GoogleAPIError {
+	apiErr := &GoogleAPIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err == nil && body.Error.Message != "" {
+		apiErr.Message = body.Error.Message
+	}
+	return apiErr
+}
+
+// wrapGoogleEventError maps missing and deleted events to ErrEventNotFound, rejected changes
+// to ErrInvalidEvent and responses still throttled after retrying to ErrGraphBusy
+func wrapGoogleEventError(action string, err error) error {
+	var apiErr *GoogleAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound, http.StatusGone:
+			return ErrEventNotFound
+		case http.StatusBadRequest:
+			return fmt.Errorf("failed to %s: %w: %v", action, ErrInvalidEvent, err)
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return fmt.Errorf("failed to %s: %w", action, ErrGraphBusy)
+		}
+	}
+	return fmt.Errorf("failed to %s: %v", action, err)
+}
+
+// googleEventsPath is the events collection of a calendar
+func googleEventsPath(calendarID string) string {
+	return "/calendars/" + url.PathEscape(calendarID) + "/events"
+}
+
+// googleEvent is the subset of a Calendar API event resource the scheduler reads and writes
+type googleEvent struct {
+	ID             string                `json:"id,omitempty"`
+	Status         string                `json:"status,omitempty"` // confirmed, tentative or cancelled
+	Summary        string                `json:"summary,omitempty"`
+	Description    string                `json:"description,omitempty"`
+	Location       string                `json:"location,omitempty"`
+	Start          *googleEventTime      `json:"start,omitempty"`
+	End            *googleEventTime      `json:"end,omitempty"`
+	Organizer      *googleAttendee       `json:"organizer,omitempty"`
+	Attendees      []googleAttendee      `json:"attendees,omitempty"`
+	HangoutLink    string                `json:"hangoutLink,omitempty"`
+	ConferenceData *googleConferenceData `json:"conferenceData,omitempty"`
+	Transparency   string                `json:"transparency,omitempty"` // transparent events leave the time free
+	Visibility     string                `json:"visibility,omitempty"`
+	EventType      string                `json:"eventType,omitempty"` // default, outOfOffice, focusTime or workingLocation
+	ICalUID        string                `json:"iCalUID,omitempty"`
+	Sequence       int                   `json:"sequence,omitempty"`
+}
+
+// googleEventTime is either a date, for all-day events, or a date-time
+type googleEventTime struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"dateTime,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type googleAttendee struct {
+	Email          string `json:"email"`
+	ResponseStatus string `json:"responseStatus,omitempty"` // needsAction, declined, tentative or accepted
+	Organizer      bool   `json:"organizer,omitempty"`
+	Self           bool   `json:"self,omitempty"` // Set for the account the token belongs to
+}
+
+type googleConferenceData struct {
+	CreateRequest *googleCreateConferenceRequest `json:"createRequest,omitempty"`
+	EntryPoints   []googleEntryPoint             `json:"entryPoints,omitempty"`
+}
+
+type googleCreateConferenceRequest struct {
+	RequestID             string                      `json:"requestId"`
+	ConferenceSolutionKey googleConferenceSolutionKey `json:"conferenceSolutionKey"`
+}
+
+type googleConferenceSolutionKey struct {
+	Type string `json:"type"`
+}
+
+type googleEntryPoint struct {
+	EntryPointType string `json:"entryPointType"`
+	URI            string `json:"uri"`
+}
+
+type googleFreeBusyRequest struct {
+	TimeMin string             `json:"timeMin"`
+	TimeMax string             `json:"timeMax"`
+	Items   []googleCalendarID `json:"items"`
+}
+
+type googleCalendarID struct {
+	ID string `json:"id"`
+}
+
+type googleFreeBusyResponse struct {
+	Calendars map[string]googleFreeBusyCalendar `json:"calendars"`
+}
+
+type googleFreeBusyCalendar struct {
+	Busy []struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"busy"`
+	Errors []struct {
+		Domain string `json:"domain"`
+		Reason string `json:"reason"`
+	} `json:"errors"`
+}
+
+// newGoogleEventTime writes t as a date-time in loc
+func newGoogleEventTime(t time.Time, loc *time.Location) *googleEventTime {
+	eventTime := &googleEventTime{DateTime: t.In(loc).Format(time.RFC3339)}
+	if name := loc.String(); name != "Local" {
+		eventTime.TimeZone = name
+	}
+	return eventTime
+}
+
+// convertGoogleEvent converts a Calendar API event to a models.Event
+// All-day events carry dates without a zone and are anchored to midnight in loc
+func convertGoogleEvent(item googleEvent, loc *time.Location) (models.Event, error) {
+	if item.ID == "" {
+		return models.Event{}, fmt.Errorf("missing id")
+	}
+	if item.Start == nil || item.End == nil {
+		return models.Event{}, fmt.Errorf("missing start or end")
+	}
+
+	event := models.Event{
+		ID:          item.ID,
+		Subject:     item.Summary,
+		Attendees:   []string{},
+		Location:    item.Location,
+		BodyPreview: item.Description,
+		Sequence:    item.Sequence,
+		Sensitivity: convertGoogleVisibility(item.Visibility),
+		ShowAs:      convertGoogleShowAs(item),
+		ICalUID:     item.ICalUID,
+	}
+
+	if item.Start.Date != "" {
+		start, err := time.Parse("2006-01-02", item.Start.Date)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("invalid start: %v", err)
+		}
+		end, err := time.Parse("2006-01-02", item.End.Date)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("invalid end: %v", err)
+		}
+		event.Start, event.End, event.IsAllDay = start, end, true
+		event = AnchorAllDay(event, loc)
+	} else {
+		start, err := time.Parse(time.RFC3339, item.Start.DateTime)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("invalid start: %v", err)
+		}
+		end, err := time.Parse(time.RFC3339, item.End.DateTime)
+		if err != nil {
+			return models.Event{}, fmt.Errorf("invalid end: %v", err)
+		}
+		event.Start, event.End = start.UTC(), end.UTC()
+	}
+
+	if item.Organizer != nil {
+		event.Organizer = item.Organizer.Email
+	}
+	for _, attendee := range item.Attendees {
+		if attendee.Email != "" && !attendee.Organizer {
+			event.Attendees = append(event.Attendees, attendee.Email)
+		}
+	}
+
+	event.OnlineURL = item.HangoutLink
+	if event.OnlineURL == "" && item.ConferenceData != nil {
+		for _, entry := range item.ConferenceData.EntryPoints {
+			if entry.EntryPointType == "video" {
+				event.OnlineURL = entry.URI
+				break
+			}
+		}
+	}
+	event.IsOnline = event.OnlineURL != ""
+	return event, nil
+}
+
+// convertGoogleShowAs derives the free/busy status an event puts on the calendar it was read from
+// Events the calendar's owner declined leave the time free
+func convertGoogleShowAs(item googleEvent) models.ShowAs {
+	switch item.EventType {
+	case "outOfOffice":
+		return models.ShowAsOutOfOffice
+	case "workingLocation":
+		return models.ShowAsWorkingElsewhere
+	}
+	if item.Transparency == "transparent" {
+		return models.ShowAsFree
+	}
+	for _, attendee := range item.Attendees {
+		if !attendee.Self {
+			continue
+		}
+		switch attendee.ResponseStatus {
+		case "declined":
+			return models.ShowAsFree
+		case "tentative":
+			return models.ShowAsTentative
+		}
+	}
+	if item.Status == "tentative" {
+		return models.ShowAsTentative
+	}
+	return models.ShowAsBusy
+}
+
+// convertGoogleVisibility maps an event's visibility to a models.Sensitivity
+func convertGoogleVisibility(visibility string) models.Sensitivity {
+	switch visibility {
+	case "private":
+		return models.SensitivityPrivate
+	case "confidential":
+		return models.SensitivityConfidential
+	default:
+		return models.SensitivityNormal
+	}
+}
+
+// googleVisibility maps a models.Sensitivity to an event visibility; normal keeps the calendar's default
+func googleVisibility(sensitivity models.Sensitivity) string {
+	switch sensitivity {
+	case models.SensitivityPrivate:
+		return "private"
+	case models.SensitivityConfidential:
+		return "confidential"
+	default:
+		return ""
+	}
+}
+
+// convertGoogleResponseStatus maps an attendee's Google response to a ResponseStatus
+func convertGoogleResponseStatus(status string) models.ResponseStatus {
+	switch status {
+	case "accepted":
+		return models.ResponseAccepted
+	case "tentative":
+		return models.ResponseTentative
+	case "declined":
+		return models.ResponseDeclined
+	default:
+		return models.ResponseNone
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeGoogleCalendar is a stand-in of the Calendar API events collections
+// Events are organized by the calendar they were inserted into and appear in their attendees'
+// calendars too; every request must carry the token expected for the calendar it addresses
+type fakeGoogleCalendar struct {
+	token func(calendarID string) string
+
+	mu     sync.Mutex
+	events map[string]googleEvent
+	nextID int
+}
+
+func (f *fakeGoogleCalendar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/calendars/"), "/")
+	if len(parts) < 2 || parts[1] != "events" {
+		http.NotFound(w, r)
+		return
+	}
+	calendarID := parts[0]
+	if got := r.Header.Get("Authorization"); got != "Bearer "+f.token(calendarID) {
+		http.Error(w, `{"error":{"message":"forbidden"}}`, http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		var event googleEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		event.ID = "evt" + strconv.Itoa(f.nextID)
+		event.Status = "confirmed"
+		event.Organizer = &googleAttendee{Email: calendarID}
+		f.events[event.ID] = event
+		f.write(w, calendarID, event)
+		return
+	}
+
+	event, ok := f.events[parts[2]]
+	if !ok || !inGoogleCalendar(event, calendarID) {
+		http.Error(w, `{"error":{"message":"not found"}}`, http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		f.write(w, calendarID, event)
+	case http.MethodPatch:
+		var patch googleEvent
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusBadRequest)
+			return
+		}
+		if patch.Summary != "" {
+			event.Summary = patch.Summary
+		}
+		event.Sequence++
+		f.events[event.ID] = event
+		f.write(w, calendarID, event)
+	case http.MethodDelete:
+		delete(f.events, event.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// write answers with event as seen from calendarID, which sets self on its owner
+func (f *fakeGoogleCalendar) write(w http.ResponseWriter, calendarID string, event googleEvent) {
+	organizer := *event.Organizer
+	organizer.Self = organizer.Email == f.token(calendarID)
+	event.Organizer = &organizer
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+func inGoogleCalendar(event googleEvent, calendarID string) bool {
+	if strings.EqualFold(event.Organizer.Email, calendarID) {
+		return true
+	}
+	for _, attendee := range event.Attendees {
+		if strings.EqualFold(attendee.Email, calendarID) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGoogleCalendarClientOrganizer(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		token  func(calendarID string) string
+		client func(baseURL string) *GoogleCalendarClient
+	}{
+		{
+			name:  "domain-wide delegation",
+			token: func(calendarID string) string { return calendarID },
+			client: func(baseURL string) *GoogleCalendarClient {
+				return NewDelegatedGoogleCalendarClient(func(userEmail string) oauth2.TokenSource {
+					return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: userEmail})
+				}, baseURL, nil)
+			},
+		},
+		{
+			name:  "account with access to the organizers' calendars",
+			token: func(string) string { return "scheduler@gruve.ai" },
+			client: func(baseURL string) *GoogleCalendarClient {
+				return NewGoogleCalendarClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "scheduler@gruve.ai"}), baseURL, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(&fakeGoogleCalendar{token: tt.token, events: map[string]googleEvent{}})
+			defer server.Close()
+			client := tt.client(server.URL)
+			ctx := context.Background()
+
+			created, err := client.CreateCalendarEvent(ctx, "alice@gruve.ai", models.CreateMeetingRequest{
+				Subject:   "Planning",
+				Start:     start,
+				End:       start.Add(time.Hour),
+				Attendees: []string{"bob@gruve.ai"},
+			})
+			if err != nil {
+				t.Fatalf("CreateCalendarEvent() error = %v", err)
+			}
+			if created.Organizer != "alice@gruve.ai" {
+				t.Errorf("created organizer = %q, want alice@gruve.ai", created.Organizer)
+			}
+
+			// An attendee's copy names alice as organizer
+			subject := "Hijacked"
+			if _, err := client.UpdateCalendarEvent(ctx, "bob@gruve.ai", created.ID, models.UpdateMeetingRequest{Subject: &subject}); !errors.Is(err, ErrNotOrganizer) {
+				t.Errorf("UpdateCalendarEvent() by an attendee error = %v, want ErrNotOrganizer", err)
+			}
+			if _, err := client.UpdateCalendarEvent(ctx, "carol@gruve.ai", created.ID, models.UpdateMeetingRequest{Subject: &subject}); !errors.Is(err, ErrEventNotFound) {
+				t.Errorf("UpdateCalendarEvent() by a stranger error = %v, want ErrEventNotFound", err)
+			}
+
+			subject = "Planning (moved)"
+			updated, err := client.UpdateCalendarEvent(ctx, "alice@gruve.ai", created.ID, models.UpdateMeetingRequest{Subject: &subject})
+			if err != nil {
+				t.Fatalf("UpdateCalendarEvent() error = %v", err)
+			}
+			if updated.Subject != subject || updated.Organizer != "alice@gruve.ai" {
+				t.Errorf("updated = %q by %q, want %q by alice@gruve.ai", updated.Subject, updated.Organizer, subject)
+			}
+
+			responses, err := client.GetEventResponses(ctx, "alice@gruve.ai", created.ID)
+			if err != nil {
+				t.Fatalf("GetEventResponses() error = %v", err)
+			}
+			if len(responses) != 1 || responses[0].Email != "bob@gruve.ai" {
+				t.Errorf("responses = %+v, want bob@gruve.ai", responses)
+			}
+
+			cancelled, err := client.CancelCalendarEvent(ctx, "alice@gruve.ai", created.ID, "")
+			if err != nil {
+				t.Fatalf("CancelCalendarEvent() error = %v", err)
+			}
+			if cancelled.Organizer != "alice@gruve.ai" || cancelled.Sequence != updated.Sequence+1 {
+				t.Errorf("cancelled = organizer %q sequence %d", cancelled.Organizer, cancelled.Sequence)
+			}
+			if _, err := client.GetEventResponses(ctx, "alice@gruve.ai", created.ID); !errors.Is(err, ErrEventNotFound) {
+				t.Errorf("GetEventResponses() after cancel error = %v, want ErrEventNotFound", err)
+			}
+		})
+	}
+}
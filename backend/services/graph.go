@@ -3,13 +3,22 @@ package services
 import (
 	"Smart-Meeting-Scheduler/config"
 	"Smart-Meeting-Scheduler/models"
+	"Smart-Meeting-Scheduler/utils"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
+	khttp "github.com/microsoft/kiota-http-go"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
 )
 
@@ -31,9 +40,21 @@ func NewGraphAPIClient(accessToken string, cfg *config.Config) *GraphAPIClient {
 }
 
 // InitializeGraphClient initializes the Microsoft Graph SDK client
+// Throttled and transiently failing requests are retried by utils.RetryTransport,
+// which replaces the SDK's own retry handler
 func InitializeGraphClient(accessToken string) *msgraphsdk.GraphServiceClient {
 	authProvider := &TokenAuthProvider{AccessToken: accessToken}
-	adapter, err := msgraphsdk.NewGraphRequestAdapter(authProvider)
+	options := msgraphsdk.GetDefaultClientOptions()
+	var middlewares []khttp.Middleware
+	for _, middleware := range msgraphcore.GetDefaultMiddlewaresWithOptions(&options) {
+		if _, isRetry := middleware.(*khttp.RetryHandler); !isRetry {
+			middlewares = append(middlewares, middleware)
+		}
+	}
+	httpClient := khttp.GetDefaultClient(middlewares...)
+	httpClient.Transport = khttp.NewCustomTransportWithParentTransport(utils.NewGraphRetryTransport(khttp.GetDefaultTransport()), middlewares...)
+
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(authProvider, nil, nil, httpClient)
 	if err != nil {
 		panic(fmt.Sprintf("Error creating adapter: %v", err))
 	}
@@ -51,70 +72,69 @@ func (t *TokenAuthProvider) AuthenticateRequest(ctx context.Context, request *ab
 }
 
 // GetCalendarView retrieves calendar events for a user within a time range
-func (c *GraphAPIClient) GetCalendarView(userEmail string, startTime, endTime time.Time) ([]models.Event, error) {
+func (c *GraphAPIClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
 	requestStartDateTime := startTime.Format(time.RFC3339)
 	requestEndDateTime := endTime.Format(time.RFC3339)
 
 	requestParams := &graphusers.ItemCalendarViewRequestBuilderGetQueryParameters{
 		StartDateTime: &requestStartDateTime,
 		EndDateTime:   &requestEndDateTime,
+		Select:        []string{"subject", "organizer", "attendees", "start", "end", "isAllDay", "showAs", "onlineMeeting", "sensitivity"},
 	}
+	paging := GraphPagingFromEnv()
+	requestParams.Top = &paging.PageSize
+	headers := abstractions.NewRequestHeaders()
+	PreferTimeZone(headers, time.UTC)
 	config := &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+		Headers:         headers,
 		QueryParameters: requestParams,
 	}
 
-	calendarView, err := c.Client.Users().ByUserId(userEmail).CalendarView().Get(context.Background(), config)
+	items, truncated, err := ReadCalendarView(ctx, c.Client.Users().ByUserId(userEmail).CalendarView(), config, paging)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get calendar view: %v", err)
+		return nil, false, fmt.Errorf("failed to get calendar view: %v", err)
 	}
 
+	loc := c.userLocation(ctx, userEmail)
 	var events []models.Event
-	for _, item := range calendarView.GetValue() {
-		start, _ := time.Parse(time.RFC3339, *item.GetStart().GetDateTime())
-		end, _ := time.Parse(time.RFC3339, *item.GetEnd().GetDateTime())
-
-		organizer := ""
-		if item.GetOrganizer() != nil && item.GetOrganizer().GetEmailAddress() != nil {
-			organizer = *item.GetOrganizer().GetEmailAddress().GetAddress()
-		}
-
-		attendees := []string{}
-		for _, att := range item.GetAttendees() {
-			if att.GetEmailAddress() != nil && att.GetEmailAddress().GetAddress() != nil {
-				attendees = append(attendees, *att.GetEmailAddress().GetAddress())
-			}
-		}
-
-		onlineURL := ""
-		if item.GetOnlineMeeting() != nil && item.GetOnlineMeeting().GetJoinUrl() != nil {
-			onlineURL = *item.GetOnlineMeeting().GetJoinUrl()
+	for _, item := range items {
+		event, err := ConvertGraphEvent(item, time.UTC, loc)
+		if err != nil {
+			log.Printf("Warning: Skipping event %s of %s: %v", graphEventID(item), userEmail, err)
+			continue
 		}
-
-		events = append(events, models.Event{
-			ID:        *item.GetId(),
-			Subject:   *item.GetSubject(),
-			Start:     start,
-			End:       end,
-			Organizer: organizer,
-			Attendees: attendees,
-			OnlineURL: onlineURL,
-			IsOnline:  onlineURL != "",
-		})
+		events = append(events, event)
 	}
-	return events, nil
+	return events, truncated, nil
 }
 
 // GetUserEvents retrieves all events for a user within a time range
 // Uses calendarView endpoint with startDateTime and endDateTime for proper time range filtering
+// Every page is read up to GRAPH_MAX_ITEMS events; the returned flag reports that the rest were dropped
 //
 // IMPORTANT PERMISSIONS NOTE:
 // - With Delegated permissions (user tokens): Can only access own calendar (/me) or shared calendars
 // - With Application permissions: Can access any user's calendar using client credentials token
 //
 // This function tries to use Application permissions (client credentials) when accessing other users' calendars
-func (c *GraphAPIClient) GetUserEvents(userEmail string, startTime, endTime time.Time) ([]models.Event, error) {
+// Calendars kept current by the calendar sync worker are read from the local event cache instead,
+// but only for callers whose own token may read the calendar; the cache is synced with the
+// application token
+func (c *GraphAPIClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	paging := GraphPagingFromEnv()
+	if events, ok := cachedUserEvents(ctx, c.Config, userEmail, startTime, endTime); ok && c.canReadCalendar(ctx, userEmail) {
+		if len(events) > paging.MaxItems {
+			return events[:paging.MaxItems], true, nil
+		}
+		return events, false, nil
+	}
+
 	headers := abstractions.NewRequestHeaders()
-	headers.Add("Prefer", "outlook.timezone=\"UTC\"")
+	PreferTimeZone(headers, time.UTC)
 
 	// Format times as RFC3339 strings for Graph API
 	startDateTime := startTime.Format(time.RFC3339)
@@ -123,7 +143,8 @@ func (c *GraphAPIClient) GetUserEvents(userEmail string, startTime, endTime time
 	params := &graphusers.ItemCalendarViewRequestBuilderGetQueryParameters{
 		StartDateTime: &startDateTime,
 		EndDateTime:   &endDateTime,
-		Select:        []string{"subject", "bodyPreview", "organizer", "attendees", "start", "end", "location", "onlineMeeting"},
+		Select:        []string{"subject", "bodyPreview", "organizer", "attendees", "start", "end", "isAllDay", "showAs", "location", "onlineMeeting", "sensitivity"},
+		Top:           &paging.PageSize,
 	}
 	config := &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
 		Headers:         headers,
@@ -131,87 +152,89 @@ func (c *GraphAPIClient) GetUserEvents(userEmail string, startTime, endTime time
 	}
 
 	// Try with current token (delegated/user token) first
-	eventsResp, err := c.Client.Users().ByUserId(userEmail).CalendarView().Get(context.Background(), config)
+	items, truncated, err := ReadCalendarView(ctx, c.Client.Users().ByUserId(userEmail).CalendarView(), config, paging)
 	if err != nil {
 		// If it fails, try using Application permissions (client credentials token)
 		// This allows accessing any user's calendar if Application permissions are granted
 		if c.Config != nil {
 			appToken, appErr := c.Config.GetAccessToken()
 			if appErr == nil && appToken != "" {
-				// Create a new client with application token
+				// Create a new client with application token; it also reads the remaining pages
 				appClient := InitializeGraphClient(appToken)
-				eventsResp, err = appClient.Users().ByUserId(userEmail).CalendarView().Get(context.Background(), config)
-				if err == nil {
-					// Success with application token
-				} else {
-					return nil, fmt.Errorf("failed to get user events for %s with both delegated and application tokens: %v. Ensure Application permissions (Calendars.Read) are granted and admin consent is provided", userEmail, err)
+				items, truncated, err = ReadCalendarView(ctx, appClient.Users().ByUserId(userEmail).CalendarView(), config, paging)
+				if err != nil {
+					return nil, false, fmt.Errorf("failed to get user events for %s with both delegated and application tokens: %v. Ensure Application permissions (Calendars.Read) are granted and admin consent is provided", userEmail, err)
 				}
 			} else {
-				return nil, fmt.Errorf("failed to get user events for %s: %v. Also failed to get application token: %v. With delegated permissions, you can only access your own calendar (/me) or calendars shared with you. To access other users' calendars, ensure Application permissions (Calendars.Read) are granted", userEmail, err, appErr)
+				return nil, false, fmt.Errorf("failed to get user events for %s: %v. Also failed to get application token: %v. With delegated permissions, you can only access your own calendar (/me) or calendars shared with you. To access other users' calendars, ensure Application permissions (Calendars.Read) are granted", userEmail, err, appErr)
 			}
 		} else {
-			return nil, fmt.Errorf("failed to get user events for %s: %v. With delegated permissions, you can only access your own calendar (/me) or calendars shared with you. To access other users' calendars, you need Application permissions (Calendars.Read) with admin consent", userEmail, err)
+			return nil, false, fmt.Errorf("failed to get user events for %s: %v. With delegated permissions, you can only access your own calendar (/me) or calendars shared with you. To access other users' calendars, you need Application permissions (Calendars.Read) with admin consent", userEmail, err)
 		}
 	}
 
+	loc := c.userLocation(ctx, userEmail)
 	var events []models.Event
-	for _, item := range eventsResp.GetValue() {
-		start, _ := time.Parse(time.RFC3339, *item.GetStart().GetDateTime())
-		end, _ := time.Parse(time.RFC3339, *item.GetEnd().GetDateTime())
-
-		// Filter by time range
-		if start.Before(startTime) || end.After(endTime) {
+	for _, item := range items {
+		event, err := ConvertGraphEvent(item, time.UTC, loc)
+		if err != nil {
+			log.Printf("Warning: Skipping event %s of %s: %v", graphEventID(item), userEmail, err)
 			continue
 		}
 
-		organizer := ""
-		if item.GetOrganizer() != nil && item.GetOrganizer().GetEmailAddress() != nil {
-			organizer = *item.GetOrganizer().GetEmailAddress().GetAddress()
+		// Filter by time range; multi-day events only need to overlap it
+		if !event.Start.Before(endTime) || !event.End.After(startTime) {
+			continue
 		}
+		events = append(events, event)
+	}
 
-		attendees := []string{}
-		for _, att := range item.GetAttendees() {
-			if att.GetEmailAddress() != nil && att.GetEmailAddress().GetAddress() != nil {
-				attendees = append(attendees, *att.GetEmailAddress().GetAddress())
-			}
-		}
+	return events, truncated, nil
+}
 
-		onlineURL := ""
-		if item.GetOnlineMeeting() != nil && item.GetOnlineMeeting().GetJoinUrl() != nil {
-			onlineURL = *item.GetOnlineMeeting().GetJoinUrl()
+// canReadCalendar reports whether the client's own token may read userEmail's calendar
+// Graph is asked once per token and calendar; the answer is reused for as long as cached
+// events are served (CALENDAR_SYNC_MAX_AGE), so cache hits do not each cost a Graph call
+func (c *GraphAPIClient) canReadCalendar(ctx context.Context, userEmail string) bool {
+	settings, _, _ := CalendarSyncSettingsFromEnv()
+	return sharedCalendarAccess.allowed(TokenScope(c.AccessToken), userEmail, settings.MaxAge, func() (bool, bool) {
+		config := &graphusers.ItemCalendarRequestBuilderGetRequestConfiguration{
+			QueryParameters: &graphusers.ItemCalendarRequestBuilderGetQueryParameters{Select: []string{"id"}},
 		}
-
-		location := ""
-		if item.GetLocation() != nil && item.GetLocation().GetDisplayName() != nil {
-			location = *item.GetLocation().GetDisplayName()
+		if _, err := c.Client.Users().ByUserId(userEmail).Calendar().Get(ctx, config); err != nil {
+			log.Printf("Not serving cached calendar of %s: the caller cannot read it: %v", userEmail, err)
+			// Only a refusal from Graph is remembered, not a timeout or a cancelled request
+			var odataErr *odataerrors.ODataError
+			return false, errors.As(err, &odataErr)
 		}
+		return true, true
+	})
+}
 
-		bodyPreview := ""
-		if item.GetBodyPreview() != nil {
-			bodyPreview = *item.GetBodyPreview()
-		}
+// ReadCalendarView reads a calendar view following @odata.nextLink until paging.MaxItems events
+// config's headers are sent with every page
+func ReadCalendarView(ctx context.Context, builder *graphusers.ItemCalendarViewRequestBuilder, config *graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration, paging GraphPaging) ([]graphmodels.Eventable, bool, error) {
+	first, err := builder.Get(ctx, config)
+	if err != nil {
+		return nil, false, err
+	}
 
-		events = append(events, models.Event{
-			ID:          *item.GetId(),
-			Subject:     *item.GetSubject(),
-			Start:       start,
-			End:         end,
-			Organizer:   organizer,
-			Attendees:   attendees,
-			OnlineURL:   onlineURL,
-			Location:    location,
-			BodyPreview: bodyPreview,
-			IsOnline:    onlineURL != "",
+	next := func(nextLink string) (graphmodels.EventCollectionResponseable, error) {
+		return builder.WithUrl(nextLink).Get(ctx, &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+			Headers: config.Headers,
 		})
 	}
-
-	return events, nil
+	return CollectGraphPages[graphmodels.Eventable](first, next, paging.MaxItems)
 }
 
 // FindMeetingTimes finds available meeting times for a group of attendees
-func (c *GraphAPIClient) FindMeetingTimes(organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+// The search window is sent and suggestions are returned in the organizer's time zone
+func (c *GraphAPIClient) FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	loc := c.organizerLocation(ctx, organizer, organizer)
 	headers := abstractions.NewRequestHeaders()
-	headers.Add("Prefer", "outlook.timezone=\"Pacific Standard Time\"")
+	PreferTimeZone(headers, loc)
 
 	config := &graphusers.ItemFindMeetingTimesRequestBuilderPostRequestConfiguration{
 		Headers: headers,
@@ -233,32 +256,33 @@ func (c *GraphAPIClient) FindMeetingTimes(organizer string, attendees []string,
 	body.SetMaxCandidates(&maxCandidates)
 
 	ts := graphmodels.NewTimeSlot()
-	start := graphmodels.NewDateTimeTimeZone()
-	startStr := startTime.Format("2006-01-02T15:04:05")
-	start.SetDateTime(&startStr)
-	tz := "Pacific Standard Time"
-	start.SetTimeZone(&tz)
-	ts.SetStart(start)
-
-	end := graphmodels.NewDateTimeTimeZone()
-	endStr := endTime.Format("2006-01-02T15:04:05")
-	end.SetDateTime(&endStr)
-	end.SetTimeZone(&tz)
-	ts.SetEnd(end)
+	ts.SetStart(NewGraphDateTime(startTime, loc))
+	ts.SetEnd(NewGraphDateTime(endTime, loc))
 
 	tc := graphmodels.NewTimeConstraint()
 	tc.SetTimeSlots([]graphmodels.TimeSlotable{ts})
 	body.SetTimeConstraint(tc)
 
-	resp, err := c.Client.Users().ByUserId(organizer).FindMeetingTimes().Post(context.Background(), body, config)
+	resp, err := c.Client.Users().ByUserId(organizer).FindMeetingTimes().Post(ctx, body, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find meeting times: %v", err)
 	}
 
 	var suggestions []models.MeetingSuggestion
 	for _, s := range resp.GetMeetingTimeSuggestions() {
-		start, _ := time.Parse(time.RFC3339, *s.GetMeetingTimeSlot().GetStart().GetDateTime())
-		end, _ := time.Parse(time.RFC3339, *s.GetMeetingTimeSlot().GetEnd().GetDateTime())
+		if s.GetMeetingTimeSlot() == nil {
+			continue
+		}
+		start, err := ParseGraphDateTime(s.GetMeetingTimeSlot().GetStart(), loc)
+		if err != nil {
+			log.Printf("Warning: Skipping meeting time suggestion: invalid start: %v", err)
+			continue
+		}
+		end, err := ParseGraphDateTime(s.GetMeetingTimeSlot().GetEnd(), loc)
+		if err != nil {
+			log.Printf("Warning: Skipping meeting time suggestion: invalid end: %v", err)
+			continue
+		}
 
 		confidence := 0.0
 		if s.GetConfidence() != nil {
@@ -274,14 +298,131 @@ func (c *GraphAPIClient) FindMeetingTimes(organizer string, attendees []string,
 	return suggestions, nil
 }
 
+// GetSchedules reads the free/busy schedules of users with /me/calendar/getSchedule
+// It needs only free/busy access to their calendars and asks for up to 20 users per request.
+// Calendars kept current by the calendar sync worker are answered from the local event cache,
+// reduced to free/busy, without asking Graph
+func (c *GraphAPIClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	minutes := int32(scheduleInterval(interval) / time.Minute)
+	headers := abstractions.NewRequestHeaders()
+	PreferTimeZone(headers, time.UTC)
+	config := &graphusers.ItemCalendarGetScheduleRequestBuilderPostRequestConfiguration{
+		Headers: headers,
+	}
+
+	byEmail := make(map[string]models.Schedule, len(userEmails))
+	var live []string
+	for _, email := range userEmails {
+		if events, ok := cachedUserEvents(ctx, c.Config, email, startTime, endTime); ok {
+			schedule := scheduleFromEvents(email, events, startTime, endTime, interval)
+			schedule.WorkingHours = standardWorkingHours(c.userLocation(ctx, email))
+			byEmail[strings.ToLower(email)] = schedule
+			continue
+		}
+		live = append(live, email)
+	}
+
+	for len(live) > 0 {
+		batch := live
+		if len(batch) > maxScheduleUsers {
+			batch = batch[:maxScheduleUsers]
+		}
+		live = live[len(batch):]
+
+		body := graphusers.NewItemCalendarGetSchedulePostRequestBody()
+		body.SetSchedules(batch)
+		body.SetStartTime(NewGraphDateTime(startTime, time.UTC))
+		body.SetEndTime(NewGraphDateTime(endTime, time.UTC))
+		body.SetAvailabilityViewInterval(&minutes)
+
+		resp, err := c.Client.Me().Calendar().GetSchedule().PostAsGetSchedulePostResponse(ctx, body, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get schedules: %v", err)
+		}
+		for _, info := range resp.GetValue() {
+			schedule := convertGraphSchedule(info)
+			byEmail[strings.ToLower(schedule.UserEmail)] = schedule
+		}
+	}
+
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		schedule, ok := byEmail[strings.ToLower(email)]
+		if !ok {
+			schedule = models.Schedule{UserEmail: email, Items: []models.ScheduleItem{}, Error: "schedule unavailable"}
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// convertGraphSchedule converts one user's getSchedule result
+func convertGraphSchedule(info graphmodels.ScheduleInformationable) models.Schedule {
+	schedule := models.Schedule{Items: []models.ScheduleItem{}}
+	if info.GetScheduleId() != nil {
+		schedule.UserEmail = *info.GetScheduleId()
+	}
+	if info.GetAvailabilityView() != nil {
+		schedule.AvailabilityView = *info.GetAvailabilityView()
+	}
+	if info.GetError() != nil {
+		schedule.Error = "schedule unavailable"
+		if info.GetError().GetMessage() != nil {
+			schedule.Error = *info.GetError().GetMessage()
+		}
+	}
+
+	for _, item := range info.GetScheduleItems() {
+		start, err := ParseGraphDateTime(item.GetStart(), time.UTC)
+		if err != nil {
+			log.Printf("Warning: Skipping schedule item of %s: %v", schedule.UserEmail, err)
+			continue
+		}
+		end, err := ParseGraphDateTime(item.GetEnd(), time.UTC)
+		if err != nil {
+			log.Printf("Warning: Skipping schedule item of %s: %v", schedule.UserEmail, err)
+			continue
+		}
+		schedule.Items = append(schedule.Items, models.ScheduleItem{
+			Start:  start,
+			End:    end,
+			Status: ConvertGraphShowAs(item.GetStatus()),
+		})
+	}
+
+	if hours := info.GetWorkingHours(); hours != nil && hours.GetStartTime() != nil && hours.GetEndTime() != nil {
+		working := &models.WorkingHours{
+			StartTime: hours.GetStartTime().String()[:5],
+			EndTime:   hours.GetEndTime().String()[:5],
+		}
+		for _, day := range hours.GetDaysOfWeek() {
+			working.DaysOfWeek = append(working.DaysOfWeek, day.String())
+		}
+		if hours.GetTimeZone() != nil && hours.GetTimeZone().GetName() != nil {
+			name, err := NormalizeTimeZone(*hours.GetTimeZone().GetName())
+			if err != nil {
+				log.Printf("Warning: Ignoring working hours time zone of %s: %v", schedule.UserEmail, err)
+				name = "UTC"
+			}
+			working.TimeZone = name
+		}
+		schedule.WorkingHours = working
+	}
+	return schedule
+}
+
 // CreateOnlineMeeting creates a new online meeting (Teams meeting)
-func (c *GraphAPIClient) CreateOnlineMeeting(organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+func (c *GraphAPIClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
 	body := graphmodels.NewOnlineMeeting()
 	body.SetStartDateTime(&start)
 	body.SetEndDateTime(&end)
 	body.SetSubject(&subject)
 
-	resp, err := c.Client.Users().ByUserId(organizer).OnlineMeetings().Post(context.Background(), body, nil)
+	resp, err := c.Client.Users().ByUserId(organizer).OnlineMeetings().Post(ctx, body, nil)
 	if err != nil {
 		return models.Event{}, fmt.Errorf("failed to create online meeting: %v", err)
 	}
@@ -299,24 +440,16 @@ func (c *GraphAPIClient) CreateOnlineMeeting(organizer string, start time.Time,
 }
 
 // CreateCalendarEvent creates a regular calendar event
-func (c *GraphAPIClient) CreateCalendarEvent(organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+func (c *GraphAPIClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
 	body := graphmodels.NewEvent()
 	body.SetSubject(&event.Subject)
 
-	// Set start time
-	start := graphmodels.NewDateTimeTimeZone()
-	startStr := event.Start.Format(time.RFC3339)
-	start.SetDateTime(&startStr)
-	tz := "Pacific Standard Time"
-	start.SetTimeZone(&tz)
-	body.SetStart(start)
-
-	// Set end time
-	end := graphmodels.NewDateTimeTimeZone()
-	endStr := event.End.Format(time.RFC3339)
-	end.SetDateTime(&endStr)
-	end.SetTimeZone(&tz)
-	body.SetEnd(end)
+	// Set start and end as wall-clock times in the organizer's time zone
+	loc := c.organizerLocation(ctx, "", organizer)
+	body.SetStart(NewGraphDateTime(event.Start, loc))
+	body.SetEnd(NewGraphDateTime(event.End, loc))
 
 	// Set attendees
 	var attendeeObjs []graphmodels.Attendeeable
@@ -345,6 +478,11 @@ func (c *GraphAPIClient) CreateCalendarEvent(organizer string, event models.Crea
 		body.SetLocation(location)
 	}
 
+	if event.Sensitivity != "" && event.Sensitivity != models.SensitivityNormal {
+		sensitivity := graphSensitivity(event.Sensitivity)
+		body.SetSensitivity(&sensitivity)
+	}
+
 	// Set online meeting if requested
 	if event.IsOnline {
 		isOnline := true
@@ -356,7 +494,7 @@ func (c *GraphAPIClient) CreateCalendarEvent(organizer string, event models.Crea
 	// Use /me/events endpoint for authenticated user's calendar
 	// The access token is scoped to the authenticated user, so /me/events is most reliable
 	// The organizer parameter is metadata - the event is created in the authenticated user's calendar
-	resp, err := c.Client.Me().Events().Post(context.Background(), body, nil)
+	resp, err := c.Client.Me().Events().Post(ctx, body, nil)
 	if err != nil {
 		return models.Event{}, fmt.Errorf("failed to create calendar event: %v", err)
 	}
@@ -366,45 +504,310 @@ func (c *GraphAPIClient) CreateCalendarEvent(organizer string, event models.Crea
 		onlineURL = *resp.GetOnlineMeeting().GetJoinUrl()
 	}
 
+	icalUID := ""
+	if resp.GetICalUId() != nil {
+		icalUID = *resp.GetICalUId()
+	}
+
+	InvalidateSyncedCalendars(ctx, c.Config, append([]string{organizer}, event.Attendees...)...)
+
 	return models.Event{
-		ID:        *resp.GetId(),
-		Subject:   event.Subject,
-		Start:     event.Start,
-		End:       event.End,
-		Organizer: organizer,
-		Attendees: event.Attendees,
-		Location:  event.Location,
-		OnlineURL: onlineURL,
-		IsOnline:  event.IsOnline,
+		ID:          *resp.GetId(),
+		Subject:     event.Subject,
+		Start:       event.Start,
+		End:         event.End,
+		Organizer:   organizer,
+		Attendees:   event.Attendees,
+		Location:    event.Location,
+		OnlineURL:   onlineURL,
+		IsOnline:    event.IsOnline,
+		Sensitivity: event.Sensitivity,
+		ICalUID:     icalUID,
 	}, nil
 }
 
+// UpdateCalendarEvent patches an event in the authenticated user's calendar
+// Graph sends update notices to attendees automatically
+func (c *GraphAPIClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	body := graphmodels.NewEvent()
+
+	if update.Subject != nil {
+		body.SetSubject(update.Subject)
+	}
+	loc := c.organizerLocation(ctx, "", organizer)
+	if update.Start != nil {
+		body.SetStart(NewGraphDateTime(*update.Start, loc))
+	}
+	if update.End != nil {
+		body.SetEnd(NewGraphDateTime(*update.End, loc))
+	}
+	if update.Description != nil {
+		eventBody := graphmodels.NewItemBody()
+		contentType := graphmodels.TEXT_BODYTYPE
+		eventBody.SetContentType(&contentType)
+		eventBody.SetContent(update.Description)
+		body.SetBody(eventBody)
+	}
+	if update.Location != nil {
+		location := graphmodels.NewLocation()
+		location.SetDisplayName(update.Location)
+		body.SetLocation(location)
+	}
+	if update.Attendees != nil {
+		attendeeObjs := []graphmodels.Attendeeable{}
+		for _, email := range update.Attendees {
+			attendee := graphmodels.NewAttendee()
+			emailAddr := graphmodels.NewEmailAddress()
+			emailAddr.SetAddress(&email)
+			attendee.SetEmailAddress(emailAddr)
+			attendeeObjs = append(attendeeObjs, attendee)
+		}
+		body.SetAttendees(attendeeObjs)
+	}
+
+	headers := abstractions.NewRequestHeaders()
+	PreferTimeZone(headers, time.UTC)
+	config := &graphusers.ItemEventsEventItemRequestBuilderPatchRequestConfiguration{
+		Headers: headers,
+	}
+
+	resp, err := c.Client.Me().Events().ByEventId(eventID).Patch(ctx, body, config)
+	if err != nil {
+		return models.Event{}, wrapGraphEventError("update calendar event", err)
+	}
+
+	event := models.Event{
+		ID:        eventID,
+		Organizer: organizer,
+		Attendees: []string{},
+	}
+	if resp.GetSubject() != nil {
+		event.Subject = *resp.GetSubject()
+	}
+	if event.Start, event.End, event.IsAllDay, err = GraphEventTimes(resp, time.UTC, c.userLocation(ctx, organizer)); err != nil {
+		log.Printf("Warning: Failed to read times of updated event %s: %v", eventID, err)
+	}
+	event.ShowAs = ConvertGraphShowAs(resp.GetShowAs())
+	for _, att := range resp.GetAttendees() {
+		if att.GetEmailAddress() != nil && att.GetEmailAddress().GetAddress() != nil {
+			event.Attendees = append(event.Attendees, *att.GetEmailAddress().GetAddress())
+		}
+	}
+	if resp.GetLocation() != nil && resp.GetLocation().GetDisplayName() != nil {
+		event.Location = *resp.GetLocation().GetDisplayName()
+	}
+	if resp.GetBodyPreview() != nil {
+		event.BodyPreview = *resp.GetBodyPreview()
+	}
+	if resp.GetOnlineMeeting() != nil && resp.GetOnlineMeeting().GetJoinUrl() != nil {
+		event.OnlineURL = *resp.GetOnlineMeeting().GetJoinUrl()
+		event.IsOnline = true
+	}
+	InvalidateSyncedCalendars(ctx, c.Config, append([]string{organizer}, event.Attendees...)...)
+
+	return event, nil
+}
+
+// CancelCalendarEvent cancels an event in the authenticated user's calendar
+// Graph removes it from the organizer's calendar and sends cancellation notices to attendees
+func (c *GraphAPIClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	eventBuilder := c.Client.Me().Events().ByEventId(eventID)
+
+	// Load the event first so callers know who was notified
+	existing, err := eventBuilder.Get(ctx, nil)
+	if err != nil {
+		return models.Event{}, wrapGraphEventError("load calendar event", err)
+	}
+
+	body := graphusers.NewItemEventsItemCancelPostRequestBody()
+	if comment != "" {
+		body.SetComment(&comment)
+	}
+	if err := eventBuilder.Cancel().Post(ctx, body, nil); err != nil {
+		return models.Event{}, wrapGraphEventError("cancel calendar event", err)
+	}
+
+	event := models.Event{
+		ID:        eventID,
+		Organizer: organizer,
+		Attendees: []string{},
+	}
+	if existing.GetSubject() != nil {
+		event.Subject = *existing.GetSubject()
+	}
+	if event.Start, event.End, event.IsAllDay, err = GraphEventTimes(existing, time.UTC, c.userLocation(ctx, organizer)); err != nil {
+		log.Printf("Warning: Failed to read times of cancelled event %s: %v", eventID, err)
+	}
+	event.ShowAs = ConvertGraphShowAs(existing.GetShowAs())
+	for _, att := range existing.GetAttendees() {
+		if att.GetEmailAddress() != nil && att.GetEmailAddress().GetAddress() != nil {
+			event.Attendees = append(event.Attendees, *att.GetEmailAddress().GetAddress())
+		}
+	}
+	InvalidateSyncedCalendars(ctx, c.Config, append([]string{organizer}, event.Attendees...)...)
+
+	return event, nil
+}
+
+// GetEventResponses reads attendee response statuses from an event in the authenticated user's calendar
+func (c *GraphAPIClient) GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	config := &graphusers.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &graphusers.ItemEventsEventItemRequestBuilderGetQueryParameters{
+			Select: []string{"attendees"},
+		},
+	}
+
+	item, err := c.Client.Me().Events().ByEventId(eventID).Get(ctx, config)
+	if err != nil {
+		return nil, wrapGraphEventError("get event responses", err)
+	}
+
+	responses := []models.AttendeeResponse{}
+	for _, att := range item.GetAttendees() {
+		if att.GetEmailAddress() == nil || att.GetEmailAddress().GetAddress() == nil {
+			continue
+		}
+
+		response := models.AttendeeResponse{
+			Email:  strings.ToLower(*att.GetEmailAddress().GetAddress()),
+			Status: models.ResponseNone,
+		}
+		if status := att.GetStatus(); status != nil {
+			if status.GetResponse() != nil {
+				response.Status = convertGraphResponseType(*status.GetResponse())
+			}
+			// Graph reports 0001-01-01 for attendees who have not responded
+			if t := status.GetTime(); t != nil && !t.IsZero() && t.Year() > 1 {
+				respondedAt := *t
+				response.RespondedAt = &respondedAt
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// convertGraphResponseType maps a Graph attendee response to a ResponseStatus
+func convertGraphResponseType(response graphmodels.ResponseType) models.ResponseStatus {
+	switch response {
+	case graphmodels.ACCEPTED_RESPONSETYPE:
+		return models.ResponseAccepted
+	case graphmodels.TENTATIVELYACCEPTED_RESPONSETYPE:
+		return models.ResponseTentative
+	case graphmodels.DECLINED_RESPONSETYPE:
+		return models.ResponseDeclined
+	default:
+		return models.ResponseNone
+	}
+}
+
+// organizerLocation returns the time zone events are written in for organizer
+// Reads the Outlook setting of mailbox (the signed-in user's when empty) and falls back to
+// organizer's stored time zone
+func (c *GraphAPIClient) organizerLocation(ctx context.Context, mailbox, organizer string) *time.Location {
+	loc, err := c.mailboxLocation(ctx, mailbox)
+	if err == nil {
+		return loc
+	}
+	log.Printf("Warning: Failed to read mailbox time zone of %s, using stored time zone: %v", organizer, err)
+	return c.userLocation(ctx, organizer)
+}
+
+// graphEventID returns an event's ID for log messages
+func graphEventID(item graphmodels.Eventable) string {
+	if item.GetId() == nil {
+		return "(no id)"
+	}
+	return *item.GetId()
+}
+
+// userLocation returns the time zone of userEmail's all-day events
+func (c *GraphAPIClient) userLocation(ctx context.Context, userEmail string) *time.Location {
+	var db *sql.DB
+	if c.Config != nil {
+		db = c.Config.DB
+	}
+	return UserLocation(ctx, db, userEmail)
+}
+
+// ConvertGraphSensitivity maps a Graph event sensitivity to a models.Sensitivity
+// Graph's "personal" has no equivalent and is treated as private
+func ConvertGraphSensitivity(sensitivity *graphmodels.Sensitivity) models.Sensitivity {
+	if sensitivity == nil {
+		return models.SensitivityNormal
+	}
+	switch *sensitivity {
+	case graphmodels.PERSONAL_SENSITIVITY, graphmodels.PRIVATE_SENSITIVITY:
+		return models.SensitivityPrivate
+	case graphmodels.CONFIDENTIAL_SENSITIVITY:
+		return models.SensitivityConfidential
+	default:
+		return models.SensitivityNormal
+	}
+}
+
+// graphSensitivity maps a models.Sensitivity to its Graph value
+func graphSensitivity(sensitivity models.Sensitivity) graphmodels.Sensitivity {
+	switch sensitivity {
+	case models.SensitivityPrivate:
+		return graphmodels.PRIVATE_SENSITIVITY
+	case models.SensitivityConfidential:
+		return graphmodels.CONFIDENTIAL_SENSITIVITY
+	default:
+		return graphmodels.NORMAL_SENSITIVITY
+	}
+}
+
+// wrapGraphEventError maps Graph 404 responses to ErrEventNotFound, rejected changes to
+// ErrInvalidEvent and responses still throttled after retrying to ErrGraphBusy
+func wrapGraphEventError(action string, err error) error {
+	var odataErr *odataerrors.ODataError
+	if errors.As(err, &odataErr) {
+		switch odataErr.GetStatusCode() {
+		case http.StatusNotFound:
+			return ErrEventNotFound
+		case http.StatusBadRequest:
+			return fmt.Errorf("failed to %s: %w: %v", action, ErrInvalidEvent, err)
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return fmt.Errorf("failed to %s: %w", action, ErrGraphBusy)
+		}
+	}
+	return fmt.Errorf("failed to %s: %v", action, err)
+}
+
 // GetAvailability checks availability for a user within a time range (UTC working hours)
-func (c *GraphAPIClient) GetAvailability(userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
-	return c.GetAvailabilityWithTimezone(userEmail, startTime, endTime, "")
+func (c *GraphAPIClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return c.GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, "")
 }
 
 // GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
-func (c *GraphAPIClient) GetAvailabilityWithTimezone(userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+func (c *GraphAPIClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
 	// Get calendar events
-	events, err := c.GetCalendarView(userEmail, startTime, endTime)
+	events, truncated, err := c.GetCalendarView(ctx, userEmail, startTime, endTime)
 	if err != nil {
 		return models.AvailabilityResponse{}, err
 	}
 
-	// Calculate busy and free slots
-	busySlots := []models.TimeSlot{}
-	for _, event := range events {
-		busySlots = append(busySlots, models.TimeSlot{
-			Start: event.Start,
-			End:   event.End,
-		})
-	}
+	availability := availabilityFromEvents(userEmail, events, startTime, endTime, timezone)
+	availability.Truncated = truncated
+	return availability, nil
+}
+
+// availabilityFromEvents computes a user's busy and free time in [startTime, endTime) from their events
+// Free time is filtered by working hours in timezone
+func availabilityFromEvents(userEmail string, events []models.Event, startTime, endTime time.Time, timezone string) models.AvailabilityResponse {
+	busySlots := clipTimeSlots(BusyIntervals(events), startTime, endTime)
 
 	// Calculate free slots filtered by working hours in the specified timezone
 	standardSlots, extendedSlots := calculateFreeSlots(startTime, endTime, busySlots, timezone)
 
-	// Calculate total times
 	totalBusyTime := 0
 	for _, slot := range busySlots {
 		totalBusyTime += int(slot.End.Sub(slot.Start).Minutes())
@@ -416,12 +819,6 @@ func (c *GraphAPIClient) GetAvailabilityWithTimezone(userEmail string, startTime
 		totalFreeTime += int(slot.End.Sub(slot.Start).Minutes())
 	}
 
-	// Calculate total free time for extended hours
-	totalExtendedFreeTime := 0
-	for _, slot := range extendedSlots {
-		totalExtendedFreeTime += int(slot.End.Sub(slot.Start).Minutes())
-	}
-
 	// Determine actual working hours based on standard free slots
 	workingHoursStart := startTime
 	workingHoursEnd := endTime
@@ -456,7 +853,7 @@ func (c *GraphAPIClient) GetAvailabilityWithTimezone(userEmail string, startTime
 		},
 		TotalFreeTime: totalFreeTime,
 		TotalBusyTime: totalBusyTime,
-	}, nil
+	}
 }
 
 // calculateFreeSlots calculates free time slots between busy slots, filtered by working hours
@@ -503,7 +900,7 @@ func calculateFreeSlots(startTime, endTime time.Time, busySlots []models.TimeSlo
 }
 
 // filterByWorkingHours filters time slots into standard (9am-6pm) and extended (7-9am, 6-11pm) hours
-// If timezone is provided (IANA format like "Asia/Kolkata"), working hours are applied in that timezone
+// If timezone is provided (IANA like "Asia/Kolkata" or a Windows ID), working hours are applied in that timezone
 // Returns both standard and extended hours slots separately
 func filterByWorkingHours(slots []models.TimeSlot, timezone string) struct {
 	Standard []models.TimeSlot
@@ -520,7 +917,7 @@ func filterByWorkingHours(slots []models.TimeSlot, timezone string) struct {
 	var loc *time.Location
 	var err error
 	if timezone != "" {
-		loc, err = time.LoadLocation(timezone)
+		loc, err = LoadTimeZone(timezone)
 		if err != nil {
 			// Fallback to UTC if timezone is invalid
 			loc = time.UTC
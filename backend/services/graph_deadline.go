@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// defaultGraphCallTimeout bounds one GraphClient, UserService or Sender call against Graph,
+// every page and retry included
+const defaultGraphCallTimeout = 60 * time.Second
+
+// GraphCallTimeoutFromEnv reads GRAPH_CALL_TIMEOUT, a Go duration (default 60s)
+func GraphCallTimeoutFromEnv() time.Duration {
+	value := os.Getenv("GRAPH_CALL_TIMEOUT")
+	if value == "" {
+		return defaultGraphCallTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("Warning: Invalid GRAPH_CALL_TIMEOUT %q, using %s", value, defaultGraphCallTimeout)
+		return defaultGraphCallTimeout
+	}
+	return timeout
+}
+
+// graphCallContext gives one call against Graph its deadline
+// An earlier deadline or cancellation of ctx still ends the call first
+func graphCallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, GraphCallTimeoutFromEnv())
+}
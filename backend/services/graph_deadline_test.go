@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGraphCallsStopWithTheirContext(t *testing.T) {
+	t.Setenv("GRAPH_CALL_TIMEOUT", "100ms")
+	t.Setenv("GRAPH_MAX_RETRIES", "0")
+
+	// Graph never answers; calls only end through their context
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer graph.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"call deadline", context.Background()},
+		{"request cancelled", cancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GraphAPIClient{Client: InitializeGraphClient("token"), AccessToken: "token"}
+			client.Client.GetAdapter().SetBaseUrl(graph.URL)
+
+			started := time.Now()
+			day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+			_, _, err := client.GetUserEvents(tt.ctx, "bob@gruve.ai", day, day.AddDate(0, 0, 1))
+			if err == nil {
+				t.Fatal("GetUserEvents() succeeded against an unresponsive Graph")
+			}
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Errorf("GetUserEvents() returned after %s, want it to stop with its context", elapsed)
+			}
+		})
+	}
+}
+
+func TestGmailSenderStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Nothing listens here; a sender that ignored ctx would fail to connect instead
+	sender := &GmailSender{Email: "scheduler@gruve.ai", Password: "secret", SMTPHost: "127.0.0.1", SMTPPort: "1"}
+
+	err := sender.SendCancellation(ctx, &MeetingInvite{
+		UID:       "evt-1@gruve.ai",
+		Subject:   "Design review",
+		StartTime: "2026-03-05T14:00:00Z",
+		EndTime:   "2026-03-05T15:00:00Z",
+		Organizer: "alice@gruve.ai",
+		Attendees: []string{"bob@gruve.ai", "carol@gruve.ai"},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SendCancellation() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// Graph list requests are paged; these bound how much of a collection is read
+const (
+	defaultGraphPageSize = 100
+	maxGraphPageSize     = 999 // Largest $top accepted by /users and calendarView
+	defaultGraphMaxItems = 5000
+)
+
+// GraphPaging controls how Graph collections are read
+type GraphPaging struct {
+	PageSize int32 // $top sent with the first request
+	MaxItems int   // Items read across all pages before the result is reported as truncated
+}
+
+// GraphPagingFromEnv reads GRAPH_PAGE_SIZE and GRAPH_MAX_ITEMS
+// Defaults to pages of 100 and at most 5000 items
+func GraphPagingFromEnv() GraphPaging {
+	paging := GraphPaging{PageSize: defaultGraphPageSize, MaxItems: defaultGraphMaxItems}
+
+	if value := os.Getenv("GRAPH_PAGE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxGraphPageSize {
+			log.Printf("Warning: Invalid GRAPH_PAGE_SIZE %q, using %d", value, defaultGraphPageSize)
+		} else {
+			paging.PageSize = int32(size)
+		}
+	}
+
+	if value := os.Getenv("GRAPH_MAX_ITEMS"); value != "" {
+		max, err := strconv.Atoi(value)
+		if err != nil || max < 1 {
+			log.Printf("Warning: Invalid GRAPH_MAX_ITEMS %q, using %d", value, defaultGraphMaxItems)
+		} else {
+			paging.MaxItems = max
+		}
+	}
+
+	return paging
+}
+
+// graphPage is a page of a Graph collection response
+type graphPage[T any] interface {
+	GetValue() []T
+	GetOdataNextLink() *string
+}
+
+// CollectGraphPages reads the items of first and of every page after it by following @odata.nextLink
+// with next, stopping after maxItems. The returned flag reports that more items were available
+func CollectGraphPages[T any, P graphPage[T]](first P, next func(nextLink string) (P, error), maxItems int) ([]T, bool, error) {
+	var items []T
+	page := first
+	for {
+		for _, item := range page.GetValue() {
+			if len(items) >= maxItems {
+				return items, true, nil
+			}
+			items = append(items, item)
+		}
+
+		link := page.GetOdataNextLink()
+		if link == nil || *link == "" {
+			return items, false, nil
+		}
+		if len(items) >= maxItems {
+			return items, true, nil
+		}
+
+		nextPage, err := next(*link)
+		if err != nil {
+			return items, false, err
+		}
+		page = nextPage
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	graphusers "github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// fakeCalendarView serves a calendarView of total events in pages of the requested $top,
+// linking each page to the next with @odata.nextLink
+type fakeCalendarView struct {
+	t        *testing.T
+	total    int
+	requests int
+	server   *httptest.Server
+}
+
+func (f *fakeCalendarView) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests++
+	// Preferences such as the time zone must reach every page, not just the first
+	if r.Header.Get("Prefer") == "" {
+		f.t.Errorf("page %d was requested without the Prefer header", f.requests)
+	}
+	top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+	skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+	if top == 0 {
+		f.t.Fatalf("page %d was requested without $top: %s", f.requests, r.URL)
+	}
+
+	var values []map[string]any
+	for i := skip; i < skip+top && i < f.total; i++ {
+		values = append(values, map[string]any{
+			"id":    fmt.Sprintf("evt-%d", i),
+			"start": map[string]string{"dateTime": "2026-03-02T09:00:00.0000000", "timeZone": "UTC"},
+			"end":   map[string]string{"dateTime": "2026-03-02T10:00:00.0000000", "timeZone": "UTC"},
+		})
+	}
+	page := map[string]any{"value": values}
+	if skip+top < f.total {
+		page["@odata.nextLink"] = fmt.Sprintf("%s%s?$top=%d&$skip=%d", f.server.URL, r.URL.Path, top, skip+top)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestReadCalendarViewPaging(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int
+		paging        GraphPaging
+		wantItems     int
+		wantTruncated bool
+		wantRequests  int
+	}{
+		{"one page", 3, GraphPaging{PageSize: 10, MaxItems: 100}, 3, false, 1},
+		{"follows every page", 25, GraphPaging{PageSize: 10, MaxItems: 100}, 25, false, 3},
+		{"cap inside a page", 25, GraphPaging{PageSize: 10, MaxItems: 15}, 15, true, 2},
+		{"cap at a page boundary", 25, GraphPaging{PageSize: 10, MaxItems: 20}, 20, true, 2},
+		{"cap equals the total", 20, GraphPaging{PageSize: 10, MaxItems: 20}, 20, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := &fakeCalendarView{t: t, total: tt.total}
+			view.server = httptest.NewServer(view)
+			defer view.server.Close()
+
+			client := InitializeGraphClient("token")
+			client.GetAdapter().SetBaseUrl(view.server.URL)
+
+			headers := abstractions.NewRequestHeaders()
+			PreferTimeZone(headers, time.UTC)
+			start, end := "2026-03-02T00:00:00Z", "2026-03-03T00:00:00Z"
+			config := &graphusers.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &graphusers.ItemCalendarViewRequestBuilderGetQueryParameters{
+					StartDateTime: &start,
+					EndDateTime:   &end,
+					Top:           &tt.paging.PageSize,
+				},
+			}
+
+			items, truncated, err := ReadCalendarView(context.Background(), client.Users().ByUserId("bob@gruve.ai").CalendarView(), config, tt.paging)
+			if err != nil {
+				t.Fatalf("ReadCalendarView() error = %v", err)
+			}
+			if len(items) != tt.wantItems || truncated != tt.wantTruncated || view.requests != tt.wantRequests {
+				t.Errorf("ReadCalendarView() = %d items, truncated %v after %d requests, want %d, %v after %d",
+					len(items), truncated, view.requests, tt.wantItems, tt.wantTruncated, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGraphPagingFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize string
+		maxItems string
+		want     GraphPaging
+	}{
+		{"defaults", "", "", GraphPaging{PageSize: defaultGraphPageSize, MaxItems: defaultGraphMaxItems}},
+		{"configured", "250", "1000", GraphPaging{PageSize: 250, MaxItems: 1000}},
+		{"page size above the Graph limit", "5000", "", GraphPaging{PageSize: defaultGraphPageSize, MaxItems: defaultGraphMaxItems}},
+		{"invalid values", "zero", "-1", GraphPaging{PageSize: defaultGraphPageSize, MaxItems: defaultGraphMaxItems}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GRAPH_PAGE_SIZE", tt.pageSize)
+			t.Setenv("GRAPH_MAX_ITEMS", tt.maxItems)
+			if got := GraphPagingFromEnv(); got != tt.want {
+				t.Errorf("GraphPagingFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// graphDateTimeLayout is the format of dateTimeTimeZone.dateTime, which carries no offset
+const graphDateTimeLayout = "2006-01-02T15:04:05"
+
+// graphZone returns loc, or UTC when loc has no name Graph could resolve
+// Fixed offsets parsed from RFC3339 strings and the process-local zone are unnamed
+func graphZone(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	switch name := loc.String(); name {
+	case "", "Local", "UTC":
+		return time.UTC
+	default:
+		if _, err := time.LoadLocation(name); err != nil {
+			return time.UTC
+		}
+		return loc
+	}
+}
+
+// GraphTimeZoneName returns the Windows ID Outlook uses for loc, or its IANA name when there is none
+func GraphTimeZoneName(loc *time.Location) string {
+	loc = graphZone(loc)
+	if loc == time.UTC {
+		return "UTC"
+	}
+	if windows, ok := IANAToWindows(loc.String()); ok {
+		return windows
+	}
+	return loc.String()
+}
+
+// ParseGraphDateTime converts a Graph dateTimeTimeZone into a time.Time
+// dateTime is wall-clock time in timeZone; responses that omit timeZone are in preferred,
+// the zone requested with the Prefer: outlook.timezone header
+func ParseGraphDateTime(dt graphmodels.DateTimeTimeZoneable, preferred *time.Location) (time.Time, error) {
+	if dt == nil || dt.GetDateTime() == nil {
+		return time.Time{}, fmt.Errorf("missing dateTime")
+	}
+
+	loc := preferred
+	if loc == nil {
+		loc = time.UTC
+	}
+	if dt.GetTimeZone() != nil && *dt.GetTimeZone() != "" {
+		zone, err := LoadTimeZone(*dt.GetTimeZone())
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = zone
+	}
+
+	value := *dt.GetDateTime()
+	// Some endpoints still append an offset; it wins over timeZone
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	// dateTime has up to seven fractional digits, more than time.Parse reads from a layout
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		value = value[:dot]
+	}
+	t, err := time.ParseInLocation(graphDateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid dateTime %q: %v", *dt.GetDateTime(), err)
+	}
+	return t, nil
+}
+
+// NewGraphDateTime converts t to a Graph dateTimeTimeZone as wall-clock time in loc
+func NewGraphDateTime(t time.Time, loc *time.Location) graphmodels.DateTimeTimeZoneable {
+	loc = graphZone(loc)
+	dt := graphmodels.NewDateTimeTimeZone()
+	dateTime := t.In(loc).Format(graphDateTimeLayout)
+	tz := GraphTimeZoneName(loc)
+	dt.SetDateTime(&dateTime)
+	dt.SetTimeZone(&tz)
+	return dt
+}
+
+// PreferTimeZone asks Graph to return event times in loc
+func PreferTimeZone(headers *abstractions.RequestHeaders, loc *time.Location) {
+	headers.Add("Prefer", fmt.Sprintf("outlook.timezone=%q", GraphTimeZoneName(loc)))
+}
+
+// parseGraphDate reads the calendar date of a Graph dateTimeTimeZone, ignoring its clock time and zone
+func parseGraphDate(dt graphmodels.DateTimeTimeZoneable) (time.Time, error) {
+	if dt == nil || dt.GetDateTime() == nil || len(*dt.GetDateTime()) < len("2006-01-02") {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	t, err := time.Parse("2006-01-02", (*dt.GetDateTime())[:len("2006-01-02")])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %v", *dt.GetDateTime(), err)
+	}
+	return t, nil
+}
+
+// mailboxLocation reads the time zone a mailbox owner set in Outlook
+// An empty userEmail reads the signed-in user's mailbox
+func (c *GraphAPIClient) mailboxLocation(ctx context.Context, userEmail string) (*time.Location, error) {
+	user := c.Client.Me()
+	if userEmail != "" {
+		user = c.Client.Users().ByUserId(userEmail)
+	}
+	settings, err := user.MailboxSettings().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox settings: %v", err)
+	}
+	if settings.GetTimeZone() == nil || *settings.GetTimeZone() == "" {
+		return nil, fmt.Errorf("mailbox has no time zone")
+	}
+	return LoadTimeZone(*settings.GetTimeZone())
+}
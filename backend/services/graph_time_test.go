@@ -0,0 +1,159 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestGraphTimeZoneName(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		loc  *time.Location
+		want string
+	}{
+		{"nil", nil, "UTC"},
+		{"utc", time.UTC, "UTC"},
+		{"unnamed offset", time.FixedZone("", 5*3600), "UTC"},
+		{"iana zone", kolkata, "India Standard Time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GraphTimeZoneName(tt.loc); got != tt.want {
+				t.Errorf("GraphTimeZoneName(%v) = %q, want %q", tt.loc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGraphDateTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphTime := func(dateTime, timeZone string) graphmodels.DateTimeTimeZoneable {
+		dt := graphmodels.NewDateTimeTimeZone()
+		dt.SetDateTime(&dateTime)
+		if timeZone != "" {
+			dt.SetTimeZone(&timeZone)
+		}
+		return dt
+	}
+
+	tests := []struct {
+		name      string
+		dt        graphmodels.DateTimeTimeZoneable
+		preferred *time.Location
+		want      time.Time
+		wantErr   bool
+	}{
+		{
+			name: "windows zone with seven fractional digits",
+			dt:   graphTime("2026-03-02T09:30:00.0000000", "Pacific Standard Time"),
+			want: time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "iana zone",
+			dt:   graphTime("2026-07-01T09:00:00", "Europe/Berlin"),
+			want: time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "zone left out uses the preferred one",
+			dt:        graphTime("2026-03-02T09:00:00", ""),
+			preferred: berlin,
+			want:      time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "zone left out without a preferred one is utc",
+			dt:   graphTime("2026-03-02T09:00:00", ""),
+			want: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "offset wins over the zone",
+			dt:   graphTime("2026-03-02T09:00:00+05:30", "Pacific Standard Time"),
+			want: time.Date(2026, 3, 2, 3, 30, 0, 0, time.UTC),
+		},
+		{name: "missing dateTime", dt: graphmodels.NewDateTimeTimeZone(), wantErr: true},
+		{name: "unknown zone", dt: graphTime("2026-03-02T09:00:00", "Mars Standard Time"), wantErr: true},
+		{name: "invalid dateTime", dt: graphTime("yesterday", "UTC"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGraphDateTime(tt.dt, tt.preferred)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGraphDateTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseGraphDateTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGraphDateTime(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		loc          *time.Location
+		wantDateTime string
+		wantTimeZone string
+	}{
+		{"utc", time.UTC, "2026-03-02T09:00:00", "UTC"},
+		{"iana zone", kolkata, "2026-03-02T14:30:00", "India Standard Time"},
+		{"unnamed offset falls back to utc", time.FixedZone("", -7*3600), "2026-03-02T09:00:00", "UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt := NewGraphDateTime(start, tt.loc)
+			if *dt.GetDateTime() != tt.wantDateTime || *dt.GetTimeZone() != tt.wantTimeZone {
+				t.Errorf("NewGraphDateTime() = %s %s, want %s %s", *dt.GetDateTime(), *dt.GetTimeZone(), tt.wantDateTime, tt.wantTimeZone)
+			}
+			back, err := ParseGraphDateTime(dt, nil)
+			if err != nil || !back.Equal(start) {
+				t.Errorf("ParseGraphDateTime(NewGraphDateTime()) = %v, %v, want %v", back, err, start)
+			}
+		})
+	}
+}
+
+func TestParseGraphDate(t *testing.T) {
+	graphDate := func(dateTime string) graphmodels.DateTimeTimeZoneable {
+		dt := graphmodels.NewDateTimeTimeZone()
+		dt.SetDateTime(&dateTime)
+		return dt
+	}
+
+	tests := []struct {
+		name    string
+		dt      graphmodels.DateTimeTimeZoneable
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "all-day start", dt: graphDate("2026-03-02T00:00:00.0000000"), want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "time of day is ignored", dt: graphDate("2026-03-02T23:30:00"), want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "nil", dt: nil, wantErr: true},
+		{name: "too short", dt: graphDate("2026-03"), wantErr: true},
+		{name: "invalid date", dt: graphDate("2026-13-02T00:00:00"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGraphDate(tt.dt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGraphDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseGraphDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
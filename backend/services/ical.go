@@ -0,0 +1,441 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// icalProperty is a single content line of an iCalendar object (RFC 5545 section 3.1)
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalComponent is a BEGIN/END block such as VCALENDAR or VEVENT
+type icalComponent struct {
+	Name       string
+	Properties []icalProperty
+	Children   []*icalComponent
+}
+
+// Get returns the first property with the given name
+func (c *icalComponent) Get(name string) (icalProperty, bool) {
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return icalProperty{}, false
+}
+
+// Value returns the value of the first property with the given name, or ""
+func (c *icalComponent) Value(name string) string {
+	prop, _ := c.Get(name)
+	return prop.Value
+}
+
+// All returns every property with the given name
+func (c *icalComponent) All(name string) []icalProperty {
+	var props []icalProperty
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			props = append(props, prop)
+		}
+	}
+	return props
+}
+
+// Components returns the direct children with the given name
+func (c *icalComponent) Components(name string) []*icalComponent {
+	var children []*icalComponent
+	for _, child := range c.Children {
+		if child.Name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Set replaces the properties named prop.Name with prop, keeping the position of the first one
+func (c *icalComponent) Set(prop icalProperty) {
+	for i, existing := range c.Properties {
+		if existing.Name == prop.Name {
+			c.Remove(prop.Name)
+			c.Properties = append(c.Properties[:i], append([]icalProperty{prop}, c.Properties[i:]...)...)
+			return
+		}
+	}
+	c.Properties = append(c.Properties, prop)
+}
+
+// Remove drops every property with the given name
+func (c *icalComponent) Remove(name string) {
+	kept := c.Properties[:0]
+	for _, prop := range c.Properties {
+		if prop.Name != name {
+			kept = append(kept, prop)
+		}
+	}
+	c.Properties = kept
+}
+
+// writeICalComponent writes c and its children to b as folded content lines
+// Values are written as stored, so text values must already be escaped
+func writeICalComponent(b *strings.Builder, c *icalComponent) {
+	b.WriteString("BEGIN:" + c.Name + "\r\n")
+	for _, prop := range c.Properties {
+		b.WriteString(foldICalLine(formatICalProperty(prop)))
+		b.WriteString("\r\n")
+	}
+	for _, child := range c.Children {
+		writeICalComponent(b, child)
+	}
+	b.WriteString("END:" + c.Name + "\r\n")
+}
+
+// formatICalProperty reverses parseICalLine, quoting parameter values that need it
+func formatICalProperty(prop icalProperty) string {
+	names := make([]string, 0, len(prop.Params))
+	for name := range prop.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	line := prop.Name
+	for _, name := range names {
+		value := prop.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = "\"" + value + "\""
+		}
+		line += ";" + name + "=" + value
+	}
+	return line + ":" + prop.Value
+}
+
+// parseICalendar parses an iCalendar stream and returns its top-level VCALENDAR
+func parseICalendar(r io.Reader) (*icalComponent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *icalComponent
+	var stack []*icalComponent
+	for _, line := range lines {
+		prop, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &icalComponent{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, component)
+			} else if root == nil {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("unexpected END:%s", prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				continue
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if root == nil || root.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("no VCALENDAR found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated %s component", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfoldICalLines splits content into logical lines, joining folded continuation lines
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalLine parses "NAME;PARAM=value;PARAM2="quoted":value"
+func parseICalLine(line string) (icalProperty, error) {
+	prop := icalProperty{Params: map[string]string{}}
+
+	// Find the name/params vs value separator, skipping colons inside quoted params
+	inQuotes := false
+	sep := -1
+	for i, ch := range line {
+		if ch == '"' {
+			inQuotes = !inQuotes
+		} else if ch == ':' && !inQuotes {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return prop, fmt.Errorf("invalid iCalendar line: %q", line)
+	}
+
+	head := line[:sep]
+	prop.Value = line[sep+1:]
+
+	parts := splitICalParams(head)
+	prop.Name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			continue
+		}
+		prop.Params[strings.ToUpper(key)] = strings.Trim(value, "\"")
+	}
+
+	return prop, nil
+}
+
+// splitICalParams splits the name and parameters on semicolons outside quotes
+func splitICalParams(head string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, ch := range head {
+		switch {
+		case ch == '"':
+			inQuotes = !inQuotes
+		case ch == ';' && !inQuotes:
+			parts = append(parts, head[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, head[start:])
+}
+
+// unescapeICalText reverses escapeICalText
+func unescapeICalText(text string) string {
+	replacer := strings.NewReplacer("\\n", "\n", "\\N", "\n", "\\,", ",", "\\;", ";", "\\\\", "\\")
+	return replacer.Replace(text)
+}
+
+// parseICalTime parses a DATE-TIME or DATE property value
+// UTC ("Z") values and TZID parameters are honored; floating times are read as UTC
+func parseICalTime(prop icalProperty) (time.Time, error) {
+	return icalTimeZones(nil).parse(prop)
+}
+
+// parseICalWallClock parses a DATE-TIME or DATE value into its wall clock fields, stored as UTC
+func parseICalWallClock(value string) (wall time.Time, isUTC bool, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		wall, err = time.Parse("20060102T150405Z", value)
+		return wall, true, false, err
+	case len(value) == len("20060102"):
+		wall, err = time.Parse("20060102", value)
+		return wall, false, true, err
+	default:
+		wall, err = time.Parse("20060102T150405", value)
+		return wall, false, false, err
+	}
+}
+
+// icalTimeZones resolves TZID parameters against the VTIMEZONE definitions of a calendar
+// IANA names are loaded from the system database; other names fall back to their VTIMEZONE
+type icalTimeZones map[string]*icalComponent
+
+// newICalTimeZones indexes the VTIMEZONE components of calendar by TZID
+func newICalTimeZones(calendar *icalComponent) icalTimeZones {
+	zones := icalTimeZones{}
+	for _, tz := range calendar.Components("VTIMEZONE") {
+		zones[tz.Value("TZID")] = tz
+	}
+	return zones
+}
+
+// parse converts a DATE-TIME or DATE property to an instant
+func (z icalTimeZones) parse(prop icalProperty) (time.Time, error) {
+	wall, isUTC, _, err := parseICalWallClock(prop.Value)
+	if err != nil || isUTC {
+		return wall, err
+	}
+	return z.localize(wall, prop.Params["TZID"]), nil
+}
+
+// localize interprets wall clock time in the zone named tzid, an IANA name or the Windows ID Outlook writes
+// Floating times and unknown zones are read as UTC
+func (z icalTimeZones) localize(wall time.Time, tzid string) time.Time {
+	if tzid == "" {
+		return wall
+	}
+	if loc, err := LoadTimeZone(tzid); err == nil {
+		return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	}
+	if tz, ok := z[tzid]; ok {
+		if offset, ok := vtimezoneOffset(tz, wall); ok {
+			return wall.Add(-offset)
+		}
+	}
+	return wall
+}
+
+// vtimezoneOffset returns the UTC offset in effect at wall clock time in a VTIMEZONE
+// The observance (STANDARD or DAYLIGHT) with the latest onset at or before wall applies
+func vtimezoneOffset(tz *icalComponent, wall time.Time) (time.Duration, bool) {
+	var latest time.Time
+	var offset time.Duration
+	found := false
+
+	for _, observance := range tz.Children {
+		if observance.Name != "STANDARD" && observance.Name != "DAYLIGHT" {
+			continue
+		}
+		onset, _, _, err := parseICalWallClock(observance.Value("DTSTART"))
+		if err != nil {
+			continue
+		}
+		to, err := parseUTCOffset(observance.Value("TZOFFSETTO"))
+		if err != nil {
+			continue
+		}
+
+		onsets := []time.Time{onset}
+		if rule := observance.Value("RRULE"); rule != "" {
+			expanded, err := expandRRule(rule, onset, wall.AddDate(-2, 0, 0), wall.Add(time.Nanosecond), func(t time.Time) time.Time { return t }, 500)
+			if err == nil {
+				onsets = expanded
+			}
+		}
+		for _, rdate := range observance.All("RDATE") {
+			for _, value := range strings.Split(rdate.Value, ",") {
+				if t, _, _, err := parseICalWallClock(value); err == nil {
+					onsets = append(onsets, t)
+				}
+			}
+		}
+
+		for _, t := range onsets {
+			if !t.After(wall) && (!found || t.After(latest)) {
+				latest, offset, found = t, to, true
+			}
+		}
+	}
+
+	return offset, found
+}
+
+// parseUTCOffset parses a UTC-OFFSET value such as "-0800" or "+053000"
+func parseUTCOffset(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) != 5 && len(value) != 7 {
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+
+	sign := time.Duration(1)
+	switch value[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+
+	var hours, minutes, seconds int
+	if _, err := fmt.Sscanf(value[1:5], "%02d%02d", &hours, &minutes); err != nil {
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+	if len(value) == 7 {
+		if _, err := fmt.Sscanf(value[5:], "%02d", &seconds); err != nil {
+			return 0, fmt.Errorf("invalid UTC offset: %q", value)
+		}
+	}
+
+	return sign * (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second), nil
+}
+
+// parseICalDuration parses a DURATION value such as "PT1H30M" or "-P1D"
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+		value = value[1:]
+	}
+	value = strings.TrimPrefix(value, "+")
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	var total time.Duration
+	number := 0
+	digits := false
+	for _, ch := range value[1:] {
+		if ch >= '0' && ch <= '9' {
+			number = number*10 + int(ch-'0')
+			digits = true
+			continue
+		}
+
+		var unit time.Duration
+		switch ch {
+		case 'T':
+			continue
+		case 'W':
+			unit = 7 * 24 * time.Hour
+		case 'D':
+			unit = 24 * time.Hour
+		case 'H':
+			unit = time.Hour
+		case 'M':
+			unit = time.Minute
+		case 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration: %q", value)
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration: %q", value)
+		}
+		total += time.Duration(number) * unit
+		number, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	return sign * total, nil
+}
+
+// icalAddress strips the mailto: scheme from a CAL-ADDRESS value
+func icalAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return strings.ToLower(value)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"strings"
+	"time"
+)
+
+// GenerateCalendarFeed renders events as a published iCalendar feed for calendar subscriptions
+// With freeBusyOnly, every event is reduced to a private "Busy" block without details or attendees.
+// All-day events are written as dates in loc, the calendar owner's time zone
+func GenerateCalendarFeed(name string, events []models.Event, freeBusyOnly bool, loc *time.Location) string {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	var ics strings.Builder
+	ics.WriteString("BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Gruve.ai//Smart Meeting Scheduler//EN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"CALSCALE:GREGORIAN\r\n")
+	ics.WriteString(foldICalLine("X-WR-CALNAME:"+escapeICalText(name)) + "\r\n")
+	// Suggest a refresh interval to subscribing clients
+	ics.WriteString("REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n" +
+		"X-PUBLISHED-TTL:PT1H\r\n")
+
+	for _, event := range events {
+		ev := icsEvent{
+			// Same UID as the emailed invite so clients can relate the two
+			UID:      InviteUID(event.ID),
+			Stamp:    stamp,
+			Start:    event.Start.UTC().Format("20060102T150405Z"),
+			End:      event.End.UTC().Format("20060102T150405Z"),
+			Status:   "CONFIRMED",
+			Sequence: event.Sequence,
+			Private:  event.Sensitivity == models.SensitivityPrivate || event.Sensitivity == models.SensitivityConfidential,
+			// Events that leave the owner available stay transparent to free/busy lookups
+			Transparent: !event.ShowAs.BlocksTime(),
+		}
+		if event.IsAllDay {
+			ev.AllDay = true
+			ev.Start = event.Start.In(loc).Format("20060102")
+			ev.End = event.End.In(loc).Format("20060102")
+		}
+		if freeBusyOnly {
+			ev.Summary = "Busy"
+			ev.Private = true
+		} else {
+			ev.Summary = event.Subject
+			ev.Description = event.BodyPreview
+			ev.Location = event.Location
+			if ev.Location == "" && event.OnlineURL != "" {
+				ev.Location = event.OnlineURL
+			}
+			ev.Organizer = event.Organizer
+			ev.Attendees = event.Attendees
+		}
+		writeICSEvent(&ics, ev)
+	}
+
+	ics.WriteString("END:VCALENDAR\r\n")
+	return ics.String()
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrencesPerSeries caps how many occurrences of one recurring event are imported
+const maxOccurrencesPerSeries = 1000
+
+// ICSEvent is a single event occurrence read from an iCalendar file
+type ICSEvent struct {
+	UID          string
+	RecurrenceID time.Time // original start of a recurring occurrence; zero for single events
+	Event        models.Event
+}
+
+// icsSeries groups the VEVENTs sharing a UID: the master and its modified occurrences
+type icsSeries struct {
+	master    *icalComponent
+	overrides map[int64]*icalComponent // keyed by RECURRENCE-ID instant
+}
+
+// ParseICSEvents reads the VEVENTs of an iCalendar stream
+// Recurring events are expanded into their occurrences between from and to; modified
+// occurrences (RECURRENCE-ID) replace the generated ones and cancelled ones are dropped.
+// Returns the occurrences and a warning for every event that was skipped or only partly imported
+func ParseICSEvents(r io.Reader, from, to time.Time) ([]ICSEvent, []string, error) {
+	calendar, err := parseICalendar(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	zones := newICalTimeZones(calendar)
+
+	var warnings []string
+	var uids []string
+	series := map[string]*icsSeries{}
+	for _, vevent := range calendar.Components("VEVENT") {
+		uid := vevent.Value("UID")
+		if uid == "" {
+			warnings = append(warnings, fmt.Sprintf("skipped %q: event has no UID", vevent.Value("SUMMARY")))
+			continue
+		}
+
+		s, ok := series[uid]
+		if !ok {
+			s = &icsSeries{overrides: map[int64]*icalComponent{}}
+			series[uid] = s
+			uids = append(uids, uid)
+		}
+
+		if rid, ok := vevent.Get("RECURRENCE-ID"); ok {
+			t, err := zones.parse(rid)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipped occurrence of %s: invalid RECURRENCE-ID", uid))
+				continue
+			}
+			s.overrides[t.Unix()] = vevent
+		} else {
+			s.master = vevent
+		}
+	}
+
+	var events []ICSEvent
+	for _, uid := range uids {
+		occurrences, seriesWarnings := expandICSSeries(uid, series[uid], zones, from, to)
+		events = append(events, occurrences...)
+		warnings = append(warnings, seriesWarnings...)
+	}
+
+	return events, warnings, nil
+}
+
+// expandICSSeries converts one UID's VEVENTs into occurrences
+func expandICSSeries(uid string, s *icsSeries, zones icalTimeZones, from, to time.Time) ([]ICSEvent, []string) {
+	var events []ICSEvent
+	var warnings []string
+
+	addOverride := func(recurrenceID time.Time, vevent *icalComponent) {
+		event, cancelled, err := icsToEvent(vevent, zones)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped occurrence of %s: %v", uid, err))
+			return
+		}
+		if !cancelled {
+			events = append(events, ICSEvent{UID: uid, RecurrenceID: recurrenceID, Event: event})
+		}
+	}
+
+	if s.master == nil {
+		// Modified occurrences without their series (e.g. a single forwarded instance)
+		for unix, vevent := range s.overrides {
+			addOverride(time.Unix(unix, 0).UTC(), vevent)
+		}
+		return events, warnings
+	}
+
+	master, cancelled, err := icsToEvent(s.master, zones)
+	if err != nil {
+		return nil, append(warnings, fmt.Sprintf("skipped %s: %v", uid, err))
+	}
+	if cancelled {
+		return nil, warnings
+	}
+
+	rule := s.master.Value("RRULE")
+	if rule == "" && len(s.master.All("RDATE")) == 0 {
+		return []ICSEvent{{UID: uid, Event: master}}, warnings
+	}
+
+	startProp, _ := s.master.Get("DTSTART")
+	wallStart, isUTC, _, _ := parseICalWallClock(startProp.Value)
+	localize := func(wall time.Time) time.Time {
+		if isUTC {
+			return wall
+		}
+		return zones.localize(wall, startProp.Params["TZID"])
+	}
+
+	instants := map[int64]time.Time{}
+	if rule != "" {
+		walls, err := expandRRule(rule, wallStart, from, to, localize, maxOccurrencesPerSeries)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("imported only the first occurrence of %s: %v", uid, err))
+			walls = []time.Time{wallStart}
+		}
+		for _, wall := range walls {
+			instant := localize(wall)
+			instants[instant.Unix()] = instant
+		}
+	}
+	for _, t := range icsDateList(s.master.All("RDATE"), zones) {
+		if !t.Before(from) && t.Before(to) {
+			instants[t.Unix()] = t
+		}
+	}
+	for _, t := range icsDateList(s.master.All("EXDATE"), zones) {
+		delete(instants, t.Unix())
+	}
+
+	duration := master.End.Sub(master.Start)
+	occurrences := make([]time.Time, 0, len(instants))
+	for _, t := range instants {
+		occurrences = append(occurrences, t)
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+
+	for _, start := range occurrences {
+		recurrenceID := start.UTC()
+		if vevent, ok := s.overrides[start.Unix()]; ok {
+			delete(s.overrides, start.Unix())
+			addOverride(recurrenceID, vevent)
+			continue
+		}
+
+		occurrence := master
+		occurrence.Start = start
+		occurrence.End = start.Add(duration)
+		occurrence.Attendees = append([]string(nil), master.Attendees...)
+		events = append(events, ICSEvent{UID: uid, RecurrenceID: recurrenceID, Event: occurrence})
+	}
+
+	// Occurrences moved into the window from outside it
+	for unix, vevent := range s.overrides {
+		addOverride(time.Unix(unix, 0).UTC(), vevent)
+	}
+
+	return events, warnings
+}
+
+// icsToEvent converts a VEVENT to an Event; cancelled reports STATUS:CANCELLED
+func icsToEvent(vevent *icalComponent, zones icalTimeZones) (models.Event, bool, error) {
+	startProp, ok := vevent.Get("DTSTART")
+	if !ok {
+		return models.Event{}, false, fmt.Errorf("event has no DTSTART")
+	}
+	start, err := zones.parse(startProp)
+	if err != nil {
+		return models.Event{}, false, fmt.Errorf("invalid DTSTART: %v", err)
+	}
+	_, _, dateOnly, _ := parseICalWallClock(startProp.Value)
+
+	end := start
+	if endProp, ok := vevent.Get("DTEND"); ok {
+		if end, err = zones.parse(endProp); err != nil {
+			return models.Event{}, false, fmt.Errorf("invalid DTEND: %v", err)
+		}
+	} else if value := vevent.Value("DURATION"); value != "" {
+		duration, err := parseICalDuration(value)
+		if err != nil {
+			return models.Event{}, false, err
+		}
+		end = start.Add(duration)
+	} else if dateOnly {
+		end = start.AddDate(0, 0, 1)
+	}
+	if end.Before(start) {
+		return models.Event{}, false, fmt.Errorf("DTEND is before DTSTART")
+	}
+
+	event := models.Event{
+		Subject:     unescapeICalText(vevent.Value("SUMMARY")),
+		Start:       start,
+		End:         end,
+		Organizer:   icalAddress(vevent.Value("ORGANIZER")),
+		Location:    unescapeICalText(vevent.Value("LOCATION")),
+		BodyPreview: unescapeICalText(vevent.Value("DESCRIPTION")),
+	}
+
+	seen := map[string]bool{}
+	for _, attendee := range vevent.All("ATTENDEE") {
+		email := icalAddress(attendee.Value)
+		if email != "" && !seen[email] {
+			seen[email] = true
+			event.Attendees = append(event.Attendees, email)
+		}
+	}
+
+	// Conference links of RFC 7986 and those exported by Google Calendar and Outlook
+	for _, name := range []string{"CONFERENCE", "X-GOOGLE-CONFERENCE", "X-MICROSOFT-SKYPETEAMSMEETINGURL"} {
+		if url := vevent.Value(name); url != "" {
+			event.OnlineURL = url
+			event.IsOnline = true
+			break
+		}
+	}
+
+	switch strings.ToUpper(vevent.Value("CLASS")) {
+	case "PRIVATE":
+		event.Sensitivity = models.SensitivityPrivate
+	case "CONFIDENTIAL":
+		event.Sensitivity = models.SensitivityConfidential
+	default:
+		event.Sensitivity = models.SensitivityNormal
+	}
+
+	// Date-only events are all-day; ImportEvents anchors them to the owner's time zone
+	event.IsAllDay = dateOnly
+	event.ShowAs = icsShowAs(vevent)
+
+	if sequence, err := strconv.Atoi(vevent.Value("SEQUENCE")); err == nil {
+		event.Sequence = sequence
+	}
+
+	return event, strings.EqualFold(vevent.Value("STATUS"), "CANCELLED"), nil
+}
+
+// icsShowAs reads an event's free/busy status from Outlook's X-MICROSOFT-CDO-BUSYSTATUS or TRANSP
+func icsShowAs(vevent *icalComponent) models.ShowAs {
+	switch strings.ToUpper(vevent.Value("X-MICROSOFT-CDO-BUSYSTATUS")) {
+	case "FREE":
+		return models.ShowAsFree
+	case "TENTATIVE":
+		return models.ShowAsTentative
+	case "BUSY":
+		return models.ShowAsBusy
+	case "OOF":
+		return models.ShowAsOutOfOffice
+	case "WORKINGELSEWHERE":
+		return models.ShowAsWorkingElsewhere
+	}
+	if strings.EqualFold(vevent.Value("TRANSP"), "TRANSPARENT") {
+		return models.ShowAsFree
+	}
+	return models.ShowAsBusy
+}
+
+// icsDateList parses the comma-separated values of RDATE or EXDATE properties
+func icsDateList(props []icalProperty, zones icalTimeZones) []time.Time {
+	var times []time.Time
+	for _, prop := range props {
+		for _, value := range strings.Split(prop.Value, ",") {
+			t, err := zones.parse(icalProperty{Name: prop.Name, Params: prop.Params, Value: value})
+			if err == nil {
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrencePeriods bounds RRULE expansion for rules that rarely or never produce occurrences
+const maxRecurrencePeriods = 100000
+
+// icalWeekday is a BYDAY entry such as "MO", "2TU" or "-1FR"
+type icalWeekday struct {
+	Ordinal int // 0 means every matching weekday in the period
+	Day     time.Weekday
+}
+
+// icalRecurrence is a parsed RRULE (RFC 5545 section 3.3.10)
+// Only the FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH and WKST parts are supported
+type icalRecurrence struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      string // raw UNTIL value; resolved against the event's zone during expansion
+	ByDay      []icalWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday // First day of the week; only WEEKLY rules with INTERVAL > 1 and BYDAY depend on it
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRRule parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"
+func parseRRule(rule string) (icalRecurrence, error) {
+	r := icalRecurrence{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			r.Until = value
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				item = strings.ToUpper(strings.TrimSpace(item))
+				if len(item) < 2 {
+					return r, fmt.Errorf("invalid BYDAY: %q", value)
+				}
+				day, ok := icalWeekdays[item[len(item)-2:]]
+				if !ok {
+					return r, fmt.Errorf("invalid BYDAY: %q", value)
+				}
+				weekday := icalWeekday{Day: day}
+				if prefix := item[:len(item)-2]; prefix != "" {
+					if weekday.Ordinal, err = strconv.Atoi(prefix); err != nil {
+						return r, fmt.Errorf("invalid BYDAY: %q", value)
+					}
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				day, convErr := strconv.Atoi(strings.TrimSpace(item))
+				if convErr != nil || day == 0 || day < -31 || day > 31 {
+					return r, fmt.Errorf("invalid BYMONTHDAY: %q", value)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, day)
+			}
+		case "BYMONTH":
+			for _, item := range strings.Split(value, ",") {
+				month, convErr := strconv.Atoi(strings.TrimSpace(item))
+				if convErr != nil || month < 1 || month > 12 {
+					return r, fmt.Errorf("invalid BYMONTH: %q", value)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			day, ok := icalWeekdays[strings.ToUpper(strings.TrimSpace(value))]
+			if !ok {
+				return r, fmt.Errorf("invalid WKST: %q", value)
+			}
+			r.WeekStart = day
+		default:
+			return r, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return r, fmt.Errorf("RRULE is missing FREQ")
+	default:
+		return r, fmt.Errorf("unsupported FREQ %s", r.Freq)
+	}
+	return r, nil
+}
+
+// expandRRule returns the occurrences of rule for an event starting at wall clock time start
+// Occurrences are wall clock times; localize converts them to instants for comparison with UNTIL,
+// from and to. Occurrences before from still count towards COUNT but are not returned.
+// Expansion stops before to, at COUNT or UNTIL, or after max returned occurrences
+func expandRRule(rule string, start, from, to time.Time, localize func(time.Time) time.Time, max int) ([]time.Time, error) {
+	r, err := parseRRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var until time.Time
+	if r.Until != "" {
+		wall, isUTC, dateOnly, err := parseICalWallClock(r.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UNTIL: %v", err)
+		}
+		switch {
+		case isUTC:
+			until = wall
+		case dateOnly:
+			// A date UNTIL includes occurrences on that day
+			until = localize(wall.AddDate(0, 0, 1)).Add(-time.Nanosecond)
+		default:
+			until = localize(wall)
+		}
+	}
+
+	var occurrences []time.Time
+	counted := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		candidates, periodStart := r.periodCandidates(start, period*r.Interval)
+		if !localize(periodStart).Before(to) {
+			break
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Before(start) {
+				continue
+			}
+			instant := localize(candidate)
+			if !until.IsZero() && instant.After(until) {
+				return occurrences, nil
+			}
+			if !instant.Before(to) {
+				return occurrences, nil
+			}
+
+			counted++
+			if !instant.Before(from) {
+				occurrences = append(occurrences, candidate)
+				if len(occurrences) >= max {
+					return occurrences, nil
+				}
+			}
+			if r.Count > 0 && counted >= r.Count {
+				return occurrences, nil
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// periodCandidates returns the sorted wall clock candidates in the period offset periods after start's,
+// along with the start of that period
+func (r icalRecurrence) periodCandidates(start time.Time, offset int) ([]time.Time, time.Time) {
+	clock := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	}
+
+	var candidates []time.Time
+	var periodStart time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := start.AddDate(0, 0, offset)
+		periodStart = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day.Weekday()) {
+			candidates = append(candidates, day)
+		}
+
+	case "WEEKLY":
+		// Weeks start on WKST, which decides which weeks an INTERVAL skips
+		daysIntoWeek := r.daysFromWeekStart(start.Weekday())
+		weekStart := time.Date(start.Year(), start.Month(), start.Day()-daysIntoWeek+7*offset, 0, 0, 0, 0, time.UTC)
+		periodStart = weekStart
+		if len(r.ByDay) == 0 {
+			candidates = append(candidates, start.AddDate(0, 0, 7*offset))
+			break
+		}
+		for _, weekday := range r.ByDay {
+			day := weekStart.AddDate(0, 0, r.daysFromWeekStart(weekday.Day))
+			if r.matchesMonth(day.Month()) {
+				candidates = append(candidates, clock(day.Year(), day.Month(), day.Day()))
+			}
+		}
+
+	case "MONTHLY":
+		first := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		periodStart = first
+		if r.matchesMonth(first.Month()) {
+			for _, day := range r.monthDays(first.Year(), first.Month(), start.Day()) {
+				candidates = append(candidates, clock(first.Year(), first.Month(), day))
+			}
+		}
+
+	case "YEARLY":
+		year := start.Year() + offset
+		periodStart = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			for _, day := range r.monthDays(year, month, start.Day()) {
+				candidates = append(candidates, clock(year, month, day))
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates, periodStart
+}
+
+// daysFromWeekStart is how many days weekday comes after the first day of the week
+func (r icalRecurrence) daysFromWeekStart(weekday time.Weekday) int {
+	return (int(weekday) - int(r.WeekStart) + 7) % 7
+}
+
+// monthDays returns the days of month that match BYMONTHDAY and BYDAY, or defaultDay when neither is set
+func (r icalRecurrence) monthDays(year int, month time.Month, defaultDay int) []int {
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = daysInMonth + day + 1
+			}
+			if day < 1 || day > daysInMonth {
+				continue
+			}
+			if r.matchesWeekday(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()) {
+				days = append(days, day)
+			}
+		}
+
+	case len(r.ByDay) > 0:
+		for _, weekday := range r.ByDay {
+			var matches []int
+			for day := 1; day <= daysInMonth; day++ {
+				if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() == weekday.Day {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case weekday.Ordinal == 0:
+				days = append(days, matches...)
+			case weekday.Ordinal > 0 && weekday.Ordinal <= len(matches):
+				days = append(days, matches[weekday.Ordinal-1])
+			case weekday.Ordinal < 0 && -weekday.Ordinal <= len(matches):
+				days = append(days, matches[len(matches)+weekday.Ordinal])
+			}
+		}
+
+	default:
+		if defaultDay <= daysInMonth {
+			days = append(days, defaultDay)
+		}
+	}
+
+	return days
+}
+
+func (r icalRecurrence) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r icalRecurrence) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || daysInMonth+d+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r icalRecurrence) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Day == weekday {
+			return true
+		}
+	}
+	return false
+}
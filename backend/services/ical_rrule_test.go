@@ -0,0 +1,116 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandRRule(t *testing.T) {
+	utc := func(t time.Time) time.Time { return t }
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	dates := func(times []time.Time) string {
+		var out []string
+		for _, t := range times {
+			out = append(out, t.Format("2006-01-02T15"))
+		}
+		return strings.Join(out, " ")
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		start   time.Time
+		from    time.Time
+		to      time.Time
+		max     int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "daily with count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: date(2026, 3, 2, 9),
+			want:  "2026-03-02T09 2026-03-03T09 2026-03-04T09",
+		},
+		{
+			name:  "weekly on two days",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			start: date(2026, 3, 2, 9),
+			want:  "2026-03-02T09 2026-03-04T09 2026-03-09T09 2026-03-11T09",
+		},
+		// RFC 5545 section 3.3.10: WKST changes which weeks an INTERVAL skips
+		{
+			name:  "every other week starting on Monday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+			start: date(1997, 8, 5, 9),
+			want:  "1997-08-05T09 1997-08-10T09 1997-08-19T09 1997-08-24T09",
+		},
+		{
+			name:  "every other week starting on Sunday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			start: date(1997, 8, 5, 9),
+			want:  "1997-08-05T09 1997-08-17T09 1997-08-19T09 1997-08-31T09",
+		},
+		{
+			name:  "monthly on the last Friday until a date",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20260501",
+			start: date(2026, 1, 30, 15),
+			want:  "2026-01-30T15 2026-02-27T15 2026-03-27T15 2026-04-24T15",
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			start: date(2026, 1, 31, 8),
+			want:  "2026-01-31T08 2026-03-31T08 2026-05-31T08",
+		},
+		{
+			name:  "yearly",
+			rule:  "FREQ=YEARLY;COUNT=2",
+			start: date(2026, 2, 28, 12),
+			want:  "2026-02-28T12 2027-02-28T12",
+		},
+		{
+			name:  "occurrences before from count towards COUNT",
+			rule:  "FREQ=DAILY;COUNT=5",
+			start: date(2026, 3, 2, 9),
+			from:  date(2026, 3, 5, 0),
+			want:  "2026-03-05T09 2026-03-06T09",
+		},
+		{
+			name:  "stops at to and max",
+			rule:  "FREQ=DAILY",
+			start: date(2026, 3, 2, 9),
+			to:    date(2026, 3, 10, 0),
+			max:   2,
+			want:  "2026-03-02T09 2026-03-03T09",
+		},
+		{name: "missing FREQ", rule: "COUNT=2", start: date(2026, 3, 2, 9), wantErr: true},
+		{name: "unsupported FREQ", rule: "FREQ=HOURLY", start: date(2026, 3, 2, 9), wantErr: true},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYSETPOS=1", start: date(2026, 3, 2, 9), wantErr: true},
+		{name: "invalid WKST", rule: "FREQ=WEEKLY;WKST=XX", start: date(2026, 3, 2, 9), wantErr: true},
+		{name: "invalid INTERVAL", rule: "FREQ=DAILY;INTERVAL=0", start: date(2026, 3, 2, 9), wantErr: true},
+		{name: "invalid BYDAY", rule: "FREQ=WEEKLY;BYDAY=XY", start: date(2026, 3, 2, 9), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, max := tt.from, tt.to, tt.max
+			if to.IsZero() {
+				to = tt.start.AddDate(2, 0, 0)
+			}
+			if max == 0 {
+				max = 100
+			}
+
+			got, err := expandRRule(tt.rule, tt.start, from, to, utc, max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandRRule(%q) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			}
+			if !tt.wantErr && dates(got) != tt.want {
+				t.Errorf("expandRRule(%q) = %s, want %s", tt.rule, dates(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"Smart-Meeting-Scheduler/models"
+
+	"github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+)
+
+// MailSource delivers raw RFC 5322 messages from an inbox
+// A message is marked as handled only when handle returns nil, so failed messages are retried
+type MailSource interface {
+	Each(handle func(raw []byte) error) error
+}
+
+// MaildirSource reads messages from the new/ directory of a Maildir
+// Handled messages are moved to cur/ with the Seen flag
+type MaildirSource struct {
+	Dir string
+}
+
+// Each implements MailSource
+func (m *MaildirSource) Each(handle func(raw []byte) error) error {
+	newDir := filepath.Join(m.Dir, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return fmt.Errorf("failed to read maildir: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(newDir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Warning: Failed to read message %s: %v", path, err)
+			continue
+		}
+
+		if err := handle(raw); err != nil {
+			log.Printf("Warning: Failed to process message %s: %v", entry.Name(), err)
+			continue
+		}
+
+		name, _, _ := strings.Cut(entry.Name(), ":")
+		if err := os.Rename(path, filepath.Join(m.Dir, "cur", name+":2,S")); err != nil {
+			log.Printf("Warning: Failed to move message %s to cur: %v", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// IMAPSource reads unseen messages from an IMAP mailbox over TLS
+// Handled messages are flagged \Seen
+type IMAPSource struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	Mailbox  string
+}
+
+// Each implements MailSource
+func (s *IMAPSource) Each(handle func(raw []byte) error) error {
+	c, err := imapclient.DialTLS(s.Addr, &tls.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to IMAP server: %v", err)
+	}
+	defer c.Logout()
+
+	if err := c.Login(s.Username, s.Password); err != nil {
+		return fmt.Errorf("IMAP login failed: %v", err)
+	}
+	if _, err := c.Select(s.Mailbox, false); err != nil {
+		return fmt.Errorf("failed to select mailbox %s: %v", s.Mailbox, err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return fmt.Errorf("IMAP search failed: %v", err)
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqSet, []imap.FetchItem{imap.FetchUid, section.FetchItem()}, messages)
+	}()
+
+	// Flags are set after the fetch completes; the connection cannot run commands concurrently
+	handled := new(imap.SeqSet)
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			log.Printf("Warning: Failed to read IMAP message %d: %v", msg.Uid, err)
+			continue
+		}
+		if err := handle(raw); err != nil {
+			log.Printf("Warning: Failed to process IMAP message %d: %v", msg.Uid, err)
+			continue
+		}
+		handled.AddNum(msg.Uid)
+	}
+	if err := <-done; err != nil {
+		return fmt.Errorf("IMAP fetch failed: %v", err)
+	}
+
+	if handled.Empty() {
+		return nil
+	}
+	item := imap.FormatFlagsOp(imap.AddFlags, true)
+	if err := c.UidStore(handled, item, []interface{}{imap.SeenFlag}, nil); err != nil {
+		return fmt.Errorf("failed to mark messages as seen: %v", err)
+	}
+	return nil
+}
+
+// IMIPStore is the part of models.MeetingStore that iMIP messages are recorded in
+type IMIPStore interface {
+	GetOrganizerByUID(ctx context.Context, icalUID string) (string, error)
+	SetResponseByUID(ctx context.Context, icalUID, email string, status models.ResponseStatus, respondedAt time.Time) error
+	AddProposal(ctx context.Context, icalUID, email string, start, end time.Time, comment string) (*models.MeetingProposal, error)
+}
+
+// IMIPProcessor applies iMIP (RFC 6047) REPLY and COUNTER messages from attendees to recorded meetings
+// REPLY updates the attendee's response; COUNTER is stored as a pending proposal for the organizer.
+// A message is matched to a meeting by the UID and ORGANIZER of the scheduler's invite and answers
+// for the single ATTENDEE it names, who must be invited; the unauthenticated From header is not trusted
+type IMIPProcessor struct {
+	Source   MailSource
+	Store    IMIPStore
+	Interval time.Duration // polling interval used by Run
+}
+
+// NewIMIPProcessorFromEnv creates an iMIP processor from environment variables
+// Returns nil when IMIP_SOURCE is not set
+// - IMIP_SOURCE: "maildir" or "imap"
+// - IMIP_MAILDIR: Maildir path (maildir source)
+// - IMIP_IMAP_ADDR: IMAP server host:port (default imap.gmail.com:993)
+// - IMIP_IMAP_USERNAME / IMIP_IMAP_PASSWORD: defaults to GMAIL_EMAIL / GMAIL_APP_PASSWORD
+// - IMIP_IMAP_MAILBOX: mailbox to read (default INBOX)
+// - IMIP_POLL_INTERVAL: Go duration between polls (default 1m)
+func NewIMIPProcessorFromEnv(db *sql.DB) (*IMIPProcessor, error) {
+	var source MailSource
+	switch mode := os.Getenv("IMIP_SOURCE"); mode {
+	case "":
+		return nil, nil
+	case "maildir":
+		dir := os.Getenv("IMIP_MAILDIR")
+		if dir == "" {
+			return nil, fmt.Errorf("IMIP_MAILDIR must be set when IMIP_SOURCE=maildir")
+		}
+		source = &MaildirSource{Dir: dir}
+	case "imap":
+		imapSource := &IMAPSource{
+			Addr:     getEnvOrDefault("IMIP_IMAP_ADDR", "imap.gmail.com:993"),
+			Username: getEnvOrDefault("IMIP_IMAP_USERNAME", os.Getenv("GMAIL_EMAIL")),
+			Password: getEnvOrDefault("IMIP_IMAP_PASSWORD", os.Getenv("GMAIL_APP_PASSWORD")),
+			Mailbox:  getEnvOrDefault("IMIP_IMAP_MAILBOX", "INBOX"),
+		}
+		if imapSource.Username == "" || imapSource.Password == "" {
+			return nil, fmt.Errorf("IMAP credentials not configured")
+		}
+		source = imapSource
+	default:
+		return nil, fmt.Errorf("unknown IMIP_SOURCE: %s", mode)
+	}
+
+	interval, err := time.ParseDuration(getEnvOrDefault("IMIP_POLL_INTERVAL", "1m"))
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid IMIP_POLL_INTERVAL: %s", os.Getenv("IMIP_POLL_INTERVAL"))
+	}
+
+	return &IMIPProcessor{Source: source, Store: models.NewMeetingStore(db), Interval: interval}, nil
+}
+
+// Run polls the mail source every Interval until ctx is canceled
+func (p *IMIPProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.ProcessOnce(ctx); err != nil {
+			log.Printf("Warning: iMIP polling failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce handles every message currently waiting in the mail source
+func (p *IMIPProcessor) ProcessOnce(ctx context.Context) error {
+	return p.Source.Each(func(raw []byte) error {
+		return p.handleMessage(ctx, raw)
+	})
+}
+
+// handleMessage applies a single message
+// Messages that are not iTIP replies or counters, or that do not match a recorded meeting,
+// are skipped; only storage failures are returned so the message is retried
+func (p *IMIPProcessor) handleMessage(ctx context.Context, raw []byte) error {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		log.Printf("Skipping unreadable message: %v", err)
+		return nil
+	}
+	// Only used in logs; who answers is taken from the calendar data
+	messageID := msg.Header.Get("Message-Id")
+
+	calendarData, err := findCalendarPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		log.Printf("Skipping message %s: %v", messageID, err)
+		return nil
+	}
+	if calendarData == nil {
+		return nil
+	}
+
+	calendar, err := parseICalendar(bytes.NewReader(calendarData))
+	if err != nil {
+		log.Printf("Skipping invalid calendar data in message %s: %v", messageID, err)
+		return nil
+	}
+
+	method := strings.ToUpper(calendar.Value("METHOD"))
+	if method != "REPLY" && method != "COUNTER" {
+		return nil
+	}
+	for _, event := range calendar.Components("VEVENT") {
+		attendee, ok, err := p.invitedAttendee(ctx, event)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if method == "REPLY" {
+			err = p.applyReply(ctx, event, attendee)
+		} else {
+			err = p.applyCounter(ctx, event, attendee)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// invitedAttendee checks a REPLY or COUNTER against the meeting whose invite carried its UID
+// and returns the attendee answering. Reports false, after logging why, when the UID is unknown,
+// the ORGANIZER is not the meeting's organizer or the message does not name exactly one attendee
+func (p *IMIPProcessor) invitedAttendee(ctx context.Context, event *icalComponent) (string, bool, error) {
+	uid := event.Value("UID")
+	organizer, err := p.Store.GetOrganizerByUID(ctx, uid)
+	if errors.Is(err, models.ErrMeetingNotFound) {
+		log.Printf("Skipping iMIP message: no recorded meeting for %s", uid)
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up meeting %s: %v", uid, err)
+	}
+
+	if got := icalAddress(event.Value("ORGANIZER")); organizer == "" || !strings.EqualFold(got, organizer) {
+		log.Printf("Skipping iMIP message for %s: ORGANIZER %q is not the meeting's organizer", uid, got)
+		return "", false, nil
+	}
+
+	// RFC 5546: a REPLY or COUNTER carries the ATTENDEE property of the one attendee answering
+	attendees := event.All("ATTENDEE")
+	if len(attendees) != 1 {
+		log.Printf("Skipping iMIP message for %s: expected one ATTENDEE, got %d", uid, len(attendees))
+		return "", false, nil
+	}
+	return icalAddress(attendees[0].Value), true, nil
+}
+
+// applyReply records the PARTSTAT of the attendee answering a REPLY
+func (p *IMIPProcessor) applyReply(ctx context.Context, event *icalComponent, attendeeEmail string) error {
+	uid := event.Value("UID")
+	attendee := event.All("ATTENDEE")[0]
+
+	status, ok := convertPartStat(attendee.Params["PARTSTAT"])
+	if !ok {
+		log.Printf("Skipping REPLY for %s: unsupported PARTSTAT %q", uid, attendee.Params["PARTSTAT"])
+		return nil
+	}
+
+	respondedAt := time.Now()
+	if stamp, ok := event.Get("DTSTAMP"); ok {
+		if t, err := parseICalTime(stamp); err == nil {
+			respondedAt = t
+		}
+	}
+
+	err := p.Store.SetResponseByUID(ctx, uid, attendeeEmail, status, respondedAt)
+	if errors.Is(err, models.ErrMeetingNotFound) {
+		log.Printf("Skipping REPLY from %s: not invited to %s", attendeeEmail, uid)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record reply: %v", err)
+	}
+
+	log.Printf("Recorded iMIP reply %s from %s for %s", status, attendeeEmail, uid)
+	return nil
+}
+
+// applyCounter stores the new time proposed in a COUNTER for the organizer to review
+func (p *IMIPProcessor) applyCounter(ctx context.Context, event *icalComponent, attendeeEmail string) error {
+	uid := event.Value("UID")
+	startProp, hasStart := event.Get("DTSTART")
+	endProp, hasEnd := event.Get("DTEND")
+	if !hasStart || !hasEnd {
+		log.Printf("Skipping COUNTER for %s: DTSTART and DTEND are required", uid)
+		return nil
+	}
+	start, err := parseICalTime(startProp)
+	if err != nil {
+		log.Printf("Skipping COUNTER for %s: invalid DTSTART: %v", uid, err)
+		return nil
+	}
+	end, err := parseICalTime(endProp)
+	if err != nil || !end.After(start) {
+		log.Printf("Skipping COUNTER for %s: invalid DTEND", uid)
+		return nil
+	}
+
+	proposal, err := p.Store.AddProposal(ctx, uid, attendeeEmail, start, end, unescapeICalText(event.Value("COMMENT")))
+	if errors.Is(err, models.ErrMeetingNotFound) {
+		log.Printf("Skipping COUNTER from %s: not invited to %s", attendeeEmail, uid)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record counter proposal: %v", err)
+	}
+
+	log.Printf("Recorded iMIP counter proposal %s from %s for %s", proposal.ID, attendeeEmail, uid)
+	return nil
+}
+
+// convertPartStat maps an iCalendar PARTSTAT to a ResponseStatus
+func convertPartStat(partStat string) (models.ResponseStatus, bool) {
+	switch strings.ToUpper(partStat) {
+	case "ACCEPTED":
+		return models.ResponseAccepted, true
+	case "TENTATIVE":
+		return models.ResponseTentative, true
+	case "DECLINED":
+		return models.ResponseDeclined, true
+	case "NEEDS-ACTION":
+		return models.ResponseNone, true
+	default:
+		return "", false
+	}
+}
+
+// findCalendarPart returns the decoded text/calendar content of a message body, or nil if it has none
+func findCalendarPart(contentType, transferEncoding string, body io.Reader) ([]byte, error) {
+	if contentType == "" {
+		return nil, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type: %v", err)
+	}
+
+	switch {
+	case mediaType == "text/calendar" || mediaType == "application/ics":
+		return io.ReadAll(decodeTransferEncoding(transferEncoding, body))
+	case strings.HasPrefix(mediaType, "multipart/"):
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			// NextPart already decodes quoted-printable parts
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart body: %v", err)
+			}
+			data, err := findCalendarPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil || data != nil {
+				return data, err
+			}
+		}
+	default:
+		return nil, nil
+	}
+}
+
+// decodeTransferEncoding wraps body with a decoder for its Content-Transfer-Encoding
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// getEnvOrDefault returns the environment variable key, or fallback when it is unset
+func getEnvOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
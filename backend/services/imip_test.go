@@ -0,0 +1,211 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryIMIPStore records what the iMIP processor applies to one known meeting
+type memoryIMIPStore struct {
+	uid       string
+	organizer string
+	attendees []string
+	responses map[string]models.ResponseStatus
+	proposals []models.MeetingProposal
+}
+
+func (m *memoryIMIPStore) GetOrganizerByUID(ctx context.Context, icalUID string) (string, error) {
+	if icalUID != m.uid {
+		return "", models.ErrMeetingNotFound
+	}
+	return m.organizer, nil
+}
+
+func (m *memoryIMIPStore) invited(icalUID, email string) bool {
+	if icalUID != m.uid {
+		return false
+	}
+	for _, attendee := range m.attendees {
+		if strings.EqualFold(attendee, email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryIMIPStore) SetResponseByUID(ctx context.Context, icalUID, email string, status models.ResponseStatus, respondedAt time.Time) error {
+	if !m.invited(icalUID, email) {
+		return models.ErrMeetingNotFound
+	}
+	m.responses[strings.ToLower(email)] = status
+	return nil
+}
+
+func (m *memoryIMIPStore) AddProposal(ctx context.Context, icalUID, email string, start, end time.Time, comment string) (*models.MeetingProposal, error) {
+	if !m.invited(icalUID, email) {
+		return nil, models.ErrMeetingNotFound
+	}
+	proposal := models.MeetingProposal{AttendeeEmail: strings.ToLower(email), Start: start, End: end, Comment: comment, Status: models.ProposalPending}
+	m.proposals = append(m.proposals, proposal)
+	return &proposal, nil
+}
+
+// copyMaildir copies the new/ messages of the fixture Maildir into a fresh Maildir
+func copyMaildir(t *testing.T, fixture string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(fixture, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(fixture, "new", entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "new", entry.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// replyToInvite answers an invite produced by generateICS the way a calendar client does:
+// METHOD:REPLY with the invite's UID and ORGANIZER echoed back and only the answering ATTENDEE
+func replyToInvite(t *testing.T, invite *MeetingInvite, attendee string, partStat string) string {
+	t.Helper()
+	ics, err := generateICS(invite, "scheduler@gruve.ai", icalMethodRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendar, err := parseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendar.Set(icalProperty{Name: "METHOD", Params: map[string]string{}, Value: "REPLY"})
+	event := calendar.Components("VEVENT")[0]
+	event.Remove("ATTENDEE")
+	event.Set(icalProperty{Name: "ATTENDEE", Params: map[string]string{"PARTSTAT": partStat}, Value: "mailto:" + attendee})
+
+	var reply strings.Builder
+	writeICalComponent(&reply, calendar)
+	return "From: " + attendee + "\r\n" +
+		"To: scheduler@gruve.ai\r\n" +
+		"Subject: Accepted: " + invite.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/calendar; charset=utf-8; method=REPLY\r\n" +
+		"\r\n" + reply.String()
+}
+
+func TestIMIPProcessorMaildir(t *testing.T) {
+	dir := copyMaildir(t, filepath.Join("testdata", "maildir"))
+	invite := &MeetingInvite{
+		UID:       "evt-1@gruve.ai",
+		Subject:   "Design review",
+		StartTime: "2026-03-05T14:00:00Z",
+		EndTime:   "2026-03-05T15:00:00Z",
+		Organizer: "alice@gruve.ai",
+		Attendees: []string{"bob@gruve.ai", "carol@gruve.ai", "dave@gruve.ai"},
+	}
+	reply := replyToInvite(t, invite, "bob@gruve.ai", "ACCEPTED")
+	if err := os.WriteFile(filepath.Join(dir, "new", "1000000001.reply-accepted.gruve"), []byte(reply), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryIMIPStore{
+		uid:       "evt-1@gruve.ai",
+		organizer: "alice@gruve.ai",
+		attendees: []string{"bob@gruve.ai", "carol@gruve.ai", "dave@gruve.ai"},
+		responses: map[string]models.ResponseStatus{},
+	}
+	processor := &IMIPProcessor{Source: &MaildirSource{Dir: dir}, Store: store}
+
+	if err := processor.ProcessOnce(context.Background()); err != nil {
+		t.Fatalf("ProcessOnce() error = %v", err)
+	}
+
+	// Only the accepted reply names the meeting's organizer and a single attendee
+	wantResponses := map[string]models.ResponseStatus{"bob@gruve.ai": models.ResponseAccepted}
+	if len(store.responses) != len(wantResponses) {
+		t.Errorf("responses = %v, want %v", store.responses, wantResponses)
+	}
+	for email, want := range wantResponses {
+		if got := store.responses[email]; got != want {
+			t.Errorf("response of %s = %q, want %q", email, got, want)
+		}
+	}
+
+	if len(store.proposals) != 1 {
+		t.Fatalf("got %d proposals, want 1", len(store.proposals))
+	}
+	proposal := store.proposals[0]
+	wantStart := time.Date(2026, 3, 5, 15, 0, 0, 0, time.UTC)
+	if proposal.AttendeeEmail != "carol@gruve.ai" || !proposal.Start.Equal(wantStart) || !proposal.End.Equal(wantStart.Add(time.Hour)) {
+		t.Errorf("proposal = %+v, want carol@gruve.ai from %s for an hour", proposal, wantStart)
+	}
+	if proposal.Comment != "Thursday works better, thanks" {
+		t.Errorf("proposal comment = %q", proposal.Comment)
+	}
+
+	// Skipped messages are handled too, so nothing is left to retry
+	left, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d messages left in new/", len(left))
+	}
+	moved, err := os.ReadDir(filepath.Join(dir, "cur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range moved {
+		if !strings.HasSuffix(entry.Name(), ":2,S") {
+			t.Errorf("message %s in cur/ is not flagged seen", entry.Name())
+		}
+	}
+}
+
+func TestIMIPProcessorInvitedAttendee(t *testing.T) {
+	store := &memoryIMIPStore{uid: "evt-1@gruve.ai", organizer: "Alice@Gruve.ai"}
+	processor := &IMIPProcessor{Store: store}
+
+	tests := []struct {
+		name  string
+		event string
+		want  string
+		ok    bool
+	}{
+		{"matches", "UID:evt-1@gruve.ai\r\nORGANIZER:mailto:alice@gruve.ai\r\nATTENDEE:mailto:bob@gruve.ai", "bob@gruve.ai", true},
+		{"organizer with name", "UID:evt-1@gruve.ai\r\nORGANIZER;CN=Alice:MAILTO:alice@gruve.ai\r\nATTENDEE;CN=Bob:mailto:bob@gruve.ai", "bob@gruve.ai", true},
+		{"unknown uid", "UID:other@gruve.ai\r\nORGANIZER:mailto:alice@gruve.ai\r\nATTENDEE:mailto:bob@gruve.ai", "", false},
+		{"other organizer", "UID:evt-1@gruve.ai\r\nORGANIZER:mailto:mallory@example.com\r\nATTENDEE:mailto:bob@gruve.ai", "", false},
+		{"no organizer", "UID:evt-1@gruve.ai\r\nATTENDEE:mailto:bob@gruve.ai", "", false},
+		{"no attendee", "UID:evt-1@gruve.ai\r\nORGANIZER:mailto:alice@gruve.ai", "", false},
+		{"two attendees", "UID:evt-1@gruve.ai\r\nORGANIZER:mailto:alice@gruve.ai\r\nATTENDEE:mailto:bob@gruve.ai\r\nATTENDEE:mailto:carol@gruve.ai", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar, err := parseICalendar(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + tt.event + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok, err := processor.invitedAttendee(context.Background(), calendar.Components("VEVENT")[0])
+			if err != nil {
+				t.Fatalf("invitedAttendee() error = %v", err)
+			}
+			if !strings.EqualFold(got, tt.want) || ok != tt.ok {
+				t.Errorf("invitedAttendee() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
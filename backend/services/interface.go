@@ -2,30 +2,60 @@ package services
 
 import (
 	"Smart-Meeting-Scheduler/models"
+	"context"
+	"errors"
 	"time"
 )
 
+// ErrEventNotFound is returned when an event ID does not exist in the calendar
+var ErrEventNotFound = errors.New("event not found")
+
+// ErrNotOrganizer is returned when a user tries to change an event they do not organize
+var ErrNotOrganizer = errors.New("only the organizer can modify this event")
+
+// ErrInvalidEvent is returned when a change would leave an event invalid, such as ending before it starts
+var ErrInvalidEvent = errors.New("invalid event")
+
+// ErrGraphBusy is returned when Graph keeps throttling or failing a request after every retry
+var ErrGraphBusy = errors.New("microsoft graph is busy, try again later")
+
 // GraphClient defines the interface for interacting with Microsoft Graph API
 // or a mock implementation for calendar and meeting operations
+// Every method stops its work once ctx is cancelled or past its deadline
 type GraphClient interface {
 	// GetCalendarView retrieves calendar events for a user within a time range
-	GetCalendarView(userEmail string, startTime, endTime time.Time) ([]models.Event, error)
+	// The flag reports that the range held more events than were returned
+	GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error)
 
 	// GetUserEvents retrieves all events for a user within a time range
-	GetUserEvents(userEmail string, startTime, endTime time.Time) ([]models.Event, error)
+	// The flag reports that the range held more events than were returned
+	GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error)
+
+	// GetSchedules returns the free/busy schedules of several users within a time range
+	// The availability view has one digit per interval; a schedule that cannot be read carries an Error
+	GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error)
 
 	// FindMeetingTimes finds available meeting times for a group of attendees
-	FindMeetingTimes(organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error)
+	FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error)
 
 	// CreateOnlineMeeting creates a new online meeting (Teams meeting)
-	CreateOnlineMeeting(organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error)
+	CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error)
 
 	// CreateCalendarEvent creates a regular calendar event
-	CreateCalendarEvent(organizer string, event models.CreateMeetingRequest) (models.Event, error)
+	CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error)
+
+	// UpdateCalendarEvent applies a partial update to an event owned by the organizer
+	UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error)
+
+	// CancelCalendarEvent cancels an event owned by the organizer and returns the cancelled event
+	CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error)
+
+	// GetEventResponses returns each attendee's RSVP status for an event owned by the organizer
+	GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error)
 
 	// GetAvailability checks availability for a user within a time range
-	GetAvailability(userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error)
+	GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error)
 	
 	// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
-	GetAvailabilityWithTimezone(userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error)
+	GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error)
 }
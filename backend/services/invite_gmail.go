@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/smtp"
 	"os"
 	"strings"
@@ -45,15 +48,38 @@ func NewGmailSender() *GmailSender {
 	}
 }
 
+// iCalendar methods used in iTIP messages (RFC 5546)
+const (
+	icalMethodRequest = "REQUEST"
+	icalMethodCancel  = "CANCEL"
+)
+
 // SendInvite sends a meeting invitation via Gmail SMTP with .ics attachment
 // Sends individual emails to each attendee for better Outlook compatibility
-func (g *GmailSender) SendInvite(invite *MeetingInvite) error {
+func (g *GmailSender) SendInvite(ctx context.Context, invite *MeetingInvite) error {
+	return g.send(ctx, invite, icalMethodRequest, "")
+}
+
+// SendUpdate re-sends the invitation with the same UID and a higher SEQUENCE
+// so calendar clients replace the original event
+func (g *GmailSender) SendUpdate(ctx context.Context, invite *MeetingInvite) error {
+	return g.send(ctx, invite, icalMethodRequest, "Updated: ")
+}
+
+// SendCancellation sends a METHOD:CANCEL notice that removes the event from attendees' calendars
+func (g *GmailSender) SendCancellation(ctx context.Context, invite *MeetingInvite) error {
+	return g.send(ctx, invite, icalMethodCancel, "Canceled: ")
+}
+
+// send delivers an iTIP message with the given method to every attendee
+// Attendees not yet reached when ctx is done are skipped
+func (g *GmailSender) send(ctx context.Context, invite *MeetingInvite, method string, subjectPrefix string) error {
 	if g.Email == "" || g.Password == "" {
 		return fmt.Errorf("gmail credentials not configured (GMAIL_EMAIL and GMAIL_APP_PASSWORD required)")
 	}
 
 	// Generate .ics file content
-	icsContent, err := generateICS(invite, g.Email)
+	icsContent, err := generateICS(invite, g.Email, method)
 	if err != nil {
 		return fmt.Errorf("failed to generate ICS content: %w", err)
 	}
@@ -67,8 +93,14 @@ func (g *GmailSender) SendInvite(invite *MeetingInvite) error {
 	var lastErr error
 	sentCount := 0
 	for _, attendee := range invite.Attendees {
+		if err := ctx.Err(); err != nil {
+			log.Printf("Stopped sending meeting %s after %d attendees: %v", method, sentCount, err)
+			lastErr = err
+			break
+		}
+
 		// Build MIME email with .ics attachment for this specific attendee
-		message, err := buildMIMEMessage(invite, icsContent, g.Email, attendee)
+		message, err := buildMIMEMessage(invite, icsContent, g.Email, attendee, method, subjectPrefix)
 		if err != nil {
 			log.Printf("Failed to build MIME message for %s: %v", attendee, err)
 			lastErr = err
@@ -77,14 +109,14 @@ func (g *GmailSender) SendInvite(invite *MeetingInvite) error {
 
 		// Send to this specific attendee
 		recipients := []string{attendee}
-		err = smtp.SendMail(addr, auth, g.Email, recipients, []byte(message))
+		err = sendMail(ctx, addr, g.SMTPHost, auth, g.Email, recipients, []byte(message))
 		if err != nil {
 			log.Printf("Failed to send email to %s: %v", attendee, err)
 			lastErr = err
 			continue
 		}
 		sentCount++
-		log.Printf("Successfully sent meeting invite via Gmail to %s", attendee)
+		log.Printf("Successfully sent meeting %s via Gmail to %s", method, attendee)
 	}
 
 	if sentCount == 0 {
@@ -95,10 +127,56 @@ func (g *GmailSender) SendInvite(invite *MeetingInvite) error {
 		log.Printf("Warning: Only sent %d out of %d invites", sentCount, len(invite.Attendees))
 	}
 
-	log.Printf("Successfully sent meeting invites via Gmail to %d out of %d attendees", sentCount, len(invite.Attendees))
+	log.Printf("Successfully sent meeting %s via Gmail to %d out of %d attendees", method, sentCount, len(invite.Attendees))
 	return nil
 }
 
+// sendMail works like smtp.SendMail, but gives up once ctx is done
+func sendMail(ctx context.Context, addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if err := client.Auth(auth); err != nil {
+		return err
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
 // generateUID creates a unique identifier for the calendar event
 func generateUID() string {
 	b := make([]byte, 16)
@@ -141,9 +219,25 @@ func escapeICalText(text string) string {
 }
 
 // generateICS creates an RFC-5545 compliant iCalendar (.ics) file content
-func generateICS(invite *MeetingInvite, organizerEmail string) (string, error) {
-	// Generate unique UID for the event
-	uid := generateUID()
+// method is the iTIP method (REQUEST or CANCEL)
+// senderEmail is the mailbox the invite is sent from; it is named in SENT-BY when it is not the organizer
+func generateICS(invite *MeetingInvite, senderEmail string, method string) (string, error) {
+	// Reuse the invite's UID so updates and cancellations match the original event
+	uid := invite.UID
+	if uid == "" {
+		uid = generateUID()
+	}
+
+	// Replies echo ORGANIZER back, so it must be the meeting's organizer for them to be matched
+	organizer := invite.Organizer
+	if organizer == "" {
+		organizer = senderEmail
+	}
+
+	status := "CONFIRMED"
+	if method == icalMethodCancel {
+		status = "CANCELLED"
+	}
 
 	// Get current timestamp in iCal format
 	now := time.Now().UTC()
@@ -160,67 +254,141 @@ func generateICS(invite *MeetingInvite, organizerEmail string) (string, error) {
 		return "", fmt.Errorf("invalid end time: %w", err)
 	}
 
-	// Build attendee list
-	var attendees strings.Builder
-	for _, email := range invite.Attendees {
-		attendees.WriteString(fmt.Sprintf("ATTENDEE;CN=%s;RSVP=TRUE:mailto:%s\r\n", email, email))
+	// Build iCalendar content per RFC 5545
+	// Use explicit CRLF line endings for Outlook compatibility
+	var ics strings.Builder
+	ics.WriteString("BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Gruve.ai//Smart Meeting Scheduler//EN\r\n" +
+		"METHOD:" + method + "\r\n" +
+		"CALSCALE:GREGORIAN\r\n")
+	writeICSEvent(&ics, icsEvent{
+		UID:         uid,
+		Stamp:       timestamp,
+		Start:       startTime,
+		End:         endTime,
+		Summary:     invite.Subject,
+		Description: invite.Description,
+		Location:    invite.Location,
+		Organizer:   organizer,
+		SentBy:      senderEmail,
+		Attendees:   invite.Attendees,
+		Status:      status,
+		Sequence:    invite.Sequence,
+	})
+	ics.WriteString("END:VCALENDAR\r\n")
+
+	return ics.String(), nil
+}
+
+// icsEvent holds the VEVENT fields written by writeICSEvent
+// Times are already formatted as iCal UTC datetimes; text fields are escaped when written
+type icsEvent struct {
+	UID         string
+	Stamp       string
+	Start       string
+	End         string
+	Summary     string
+	Description string
+	Location    string
+	Organizer   string // omitted when empty
+	SentBy      string // mailbox sending on the organizer's behalf; omitted when empty or the organizer
+	Attendees   []string
+	Status      string
+	Sequence    int
+	Private     bool // CLASS:PRIVATE instead of CLASS:PUBLIC
+	AllDay      bool // Start and End are YYYYMMDD dates
+	Transparent bool // TRANSP:TRANSPARENT; the event does not block time
+}
+
+// writeICSEvent writes a VEVENT component to b, folding long lines per RFC 5545
+func writeICSEvent(b *strings.Builder, ev icsEvent) {
+	class := "PUBLIC"
+	if ev.Private {
+		class = "PRIVATE"
 	}
 
-	// Escape special characters in text fields
-	subject := escapeICalText(invite.Subject)
-	description := escapeICalText(invite.Description)
-	location := escapeICalText(invite.Location)
+	dateParam := ""
+	if ev.AllDay {
+		dateParam = ";VALUE=DATE"
+	}
+	transp := "OPAQUE"
+	if ev.Transparent {
+		transp = "TRANSPARENT"
+	}
 
-	// Build iCalendar content per RFC 5545
-	// Use explicit CRLF line endings for Outlook compatibility
-	ics := fmt.Sprintf(
-		"BEGIN:VCALENDAR\r\n"+
-			"VERSION:2.0\r\n"+
-			"PRODID:-//Gruve.ai//Smart Meeting Scheduler//EN\r\n"+
-			"METHOD:REQUEST\r\n"+
-			"CALSCALE:GREGORIAN\r\n"+
-			"BEGIN:VEVENT\r\n"+
-			"UID:%s\r\n"+
-			"DTSTAMP:%s\r\n"+
-			"DTSTART:%s\r\n"+
-			"DTEND:%s\r\n"+
-			"SUMMARY:%s\r\n"+
-			"DESCRIPTION:%s\r\n"+
-			"LOCATION:%s\r\n"+
-			"ORGANIZER;CN=%s:mailto:%s\r\n"+
-			"%sSTATUS:CONFIRMED\r\n"+
-			"SEQUENCE:0\r\n"+
-			"PRIORITY:5\r\n"+
-			"CLASS:PUBLIC\r\n"+
-			"TRANSP:OPAQUE\r\n"+
-			"END:VEVENT\r\n"+
-			"END:VCALENDAR\r\n",
-		uid,
-		timestamp,
-		startTime,
-		endTime,
-		subject,
-		description,
-		location,
-		organizerEmail,
-		organizerEmail,
-		attendees.String(),
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + ev.UID,
+		"DTSTAMP:" + ev.Stamp,
+		"DTSTART" + dateParam + ":" + ev.Start,
+		"DTEND" + dateParam + ":" + ev.End,
+		"SUMMARY:" + escapeICalText(ev.Summary),
+	}
+	if ev.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICalText(ev.Description))
+	}
+	if ev.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeICalText(ev.Location))
+	}
+	if ev.Organizer != "" {
+		sentBy := ""
+		if ev.SentBy != "" && !strings.EqualFold(ev.SentBy, ev.Organizer) {
+			sentBy = fmt.Sprintf(";SENT-BY=\"mailto:%s\"", ev.SentBy)
+		}
+		lines = append(lines, fmt.Sprintf("ORGANIZER;CN=%s%s:mailto:%s", ev.Organizer, sentBy, ev.Organizer))
+	}
+	for _, email := range ev.Attendees {
+		lines = append(lines, fmt.Sprintf("ATTENDEE;CN=%s;RSVP=TRUE:mailto:%s", email, email))
+	}
+	lines = append(lines,
+		"STATUS:"+ev.Status,
+		fmt.Sprintf("SEQUENCE:%d", ev.Sequence),
+		"PRIORITY:5",
+		"CLASS:"+class,
+		"TRANSP:"+transp,
+		"END:VEVENT",
 	)
 
-	return ics, nil
+	for _, line := range lines {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+}
+
+// foldICalLine splits lines longer than 75 octets into CRLF + space continuations
+// without breaking UTF-8 sequences
+func foldICalLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var b strings.Builder
+	width, limit := 0, 75
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			// Continuation lines start with a space
+			width, limit = 0, 74
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
 }
 
 // buildMIMEMessage constructs a multipart MIME email with both text and .ics attachment
 // Improved for Outlook compatibility
 // recipient is the specific email address to send to (for individual emails)
-func buildMIMEMessage(invite *MeetingInvite, icsContent string, fromEmail string, recipient string) (string, error) {
+func buildMIMEMessage(invite *MeetingInvite, icsContent string, fromEmail string, recipient string, method string, subjectPrefix string) (string, error) {
 	var buffer strings.Builder
 	boundary := generateBoundary()
 
 	// Email headers - improved for Outlook compatibility
 	buffer.WriteString(fmt.Sprintf("From: %s\r\n", fromEmail))
 	buffer.WriteString(fmt.Sprintf("To: %s\r\n", recipient))
-	buffer.WriteString(fmt.Sprintf("Subject: %s\r\n", invite.Subject))
+	buffer.WriteString(fmt.Sprintf("Subject: %s%s\r\n", subjectPrefix, invite.Subject))
 	buffer.WriteString("MIME-Version: 1.0\r\n")
 	buffer.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary))
 	buffer.WriteString("Content-Transfer-Encoding: 7bit\r\n")
@@ -234,13 +402,13 @@ func buildMIMEMessage(invite *MeetingInvite, icsContent string, fromEmail string
 	buffer.WriteString("Content-Transfer-Encoding: 7bit\r\n")
 	buffer.WriteString("\r\n")
 
-	emailBody := buildEmailBody(invite)
+	emailBody := buildEmailBody(invite, method, recipient)
 	buffer.WriteString(emailBody)
 	buffer.WriteString("\r\n\r\n")
 
 	// Calendar attachment part - improved for Outlook compatibility
 	buffer.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	buffer.WriteString("Content-Type: text/calendar; method=REQUEST; charset=\"UTF-8\"\r\n")
+	buffer.WriteString(fmt.Sprintf("Content-Type: text/calendar; method=%s; charset=\"UTF-8\"\r\n", method))
 	buffer.WriteString("Content-Transfer-Encoding: 7bit\r\n")
 	buffer.WriteString("Content-Disposition: attachment; filename=\"invite.ics\"\r\n")
 	buffer.WriteString("X-Mailer: Smart Meeting Scheduler\r\n")
@@ -255,10 +423,18 @@ func buildMIMEMessage(invite *MeetingInvite, icsContent string, fromEmail string
 }
 
 // buildEmailBody creates a human-readable email body
-func buildEmailBody(invite *MeetingInvite) string {
+// Invitations include the recipient's accept/tentative/decline links when available
+func buildEmailBody(invite *MeetingInvite, method string, recipient string) string {
 	var body strings.Builder
 
-	body.WriteString(fmt.Sprintf("You have been invited to: %s\r\n\r\n", invite.Subject))
+	switch {
+	case method == icalMethodCancel:
+		body.WriteString(fmt.Sprintf("This meeting has been canceled: %s\r\n\r\n", invite.Subject))
+	case invite.Sequence > 0:
+		body.WriteString(fmt.Sprintf("This meeting has been updated: %s\r\n\r\n", invite.Subject))
+	default:
+		body.WriteString(fmt.Sprintf("You have been invited to: %s\r\n\r\n", invite.Subject))
+	}
 
 	if invite.Description != "" {
 		body.WriteString(fmt.Sprintf("Description: %s\r\n\r\n", invite.Description))
@@ -271,7 +447,15 @@ func buildEmailBody(invite *MeetingInvite) string {
 	}
 
 	body.WriteString(fmt.Sprintf("\r\nOrganizer: %s\r\n", invite.Organizer))
-	body.WriteString("\r\nPlease accept or decline this invitation.\r\n")
+	if method != icalMethodCancel {
+		body.WriteString("\r\nPlease accept or decline this invitation.\r\n")
+
+		if link := invite.RSVPLinks[strings.ToLower(recipient)]; link != "" {
+			body.WriteString(fmt.Sprintf("\r\nAccept: %s?response=accepted\r\n", link))
+			body.WriteString(fmt.Sprintf("Tentative: %s?response=tentative\r\n", link))
+			body.WriteString(fmt.Sprintf("Decline: %s?response=declined\r\n", link))
+		}
+	}
 
 	return body.String()
 }
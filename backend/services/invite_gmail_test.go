@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateICSOrganizer(t *testing.T) {
+	tests := []struct {
+		name      string
+		organizer string
+		want      string
+	}{
+		{"organizer sent by the scheduler mailbox", "alice@gruve.ai", `ORGANIZER;CN=alice@gruve.ai;SENT-BY="mailto:scheduler@gruve.ai":mailto:alice@gruve.ai`},
+		{"organizer is the scheduler mailbox", "Scheduler@gruve.ai", "ORGANIZER;CN=Scheduler@gruve.ai:mailto:Scheduler@gruve.ai"},
+		{"no organizer", "", "ORGANIZER;CN=scheduler@gruve.ai:mailto:scheduler@gruve.ai"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invite := &MeetingInvite{
+				UID:       "evt-1@gruve.ai",
+				Subject:   "Design review",
+				StartTime: "2026-03-05T14:00:00Z",
+				EndTime:   "2026-03-05T15:00:00Z",
+				Organizer: tt.organizer,
+				Attendees: []string{"bob@gruve.ai"},
+			}
+			ics, err := generateICS(invite, "scheduler@gruve.ai", icalMethodRequest)
+			if err != nil {
+				t.Fatalf("generateICS() error = %v", err)
+			}
+			calendar, err := parseICalendar(strings.NewReader(ics))
+			if err != nil {
+				t.Fatal(err)
+			}
+			organizer, _ := calendar.Components("VEVENT")[0].Get("ORGANIZER")
+			if got := formatICalProperty(organizer); got != tt.want {
+				t.Errorf("ORGANIZER = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeetingNotices(t *testing.T) {
+	invite := &MeetingInvite{
+		UID:         "evt-1@gruve.ai",
+		Subject:     "Design review",
+		Description: "Moved to the afternoon",
+		StartTime:   "2026-03-05T14:00:00Z",
+		EndTime:     "2026-03-05T15:00:00Z",
+		Organizer:   "alice@gruve.ai",
+		Attendees:   []string{"bob@gruve.ai"},
+		RSVPLinks:   map[string]string{"bob@gruve.ai": "https://scheduler.gruve.ai/rsvp/token"},
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		subjectPrefix string
+		sequence      int
+		want          []string
+		notWant       []string
+	}{
+		{
+			name:   "invitation",
+			method: icalMethodRequest,
+			want: []string{"METHOD:REQUEST", "STATUS:CONFIRMED", "SEQUENCE:0", "Subject: Design review",
+				"You have been invited to", "Accept: https://scheduler.gruve.ai/rsvp/token?response=accepted"},
+		},
+		{
+			name:          "update",
+			method:        icalMethodRequest,
+			subjectPrefix: "Updated: ",
+			sequence:      2,
+			want: []string{"METHOD:REQUEST", "STATUS:CONFIRMED", "SEQUENCE:2", "Subject: Updated: Design review",
+				"This meeting has been updated", "Decline: https://scheduler.gruve.ai/rsvp/token?response=declined"},
+		},
+		{
+			name:          "cancellation",
+			method:        icalMethodCancel,
+			subjectPrefix: "Canceled: ",
+			sequence:      3,
+			want: []string{"METHOD:CANCEL", "STATUS:CANCELLED", "SEQUENCE:3", "Subject: Canceled: Design review",
+				"This meeting has been canceled", "Content-Type: text/calendar; method=CANCEL"},
+			notWant: []string{"rsvp/token", "Please accept or decline"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice := *invite
+			notice.Sequence = tt.sequence
+			ics, err := generateICS(&notice, "scheduler@gruve.ai", tt.method)
+			if err != nil {
+				t.Fatalf("generateICS() error = %v", err)
+			}
+			message, err := buildMIMEMessage(&notice, ics, "scheduler@gruve.ai", "bob@gruve.ai", tt.method, tt.subjectPrefix)
+			if err != nil {
+				t.Fatalf("buildMIMEMessage() error = %v", err)
+			}
+
+			// Every notice about the event keeps the UID of the invitation
+			if !strings.Contains(ics, "UID:evt-1@gruve.ai\r\n") {
+				t.Errorf("notice does not keep the invitation's UID:\n%s", ics)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(message, want) {
+					t.Errorf("notice is missing %q:\n%s", want, message)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(message, notWant) {
+					t.Errorf("notice contains %q:\n%s", notWant, message)
+				}
+			}
+		})
+	}
+}
@@ -1,22 +1,42 @@
 package services
 
+import "context"
+
 // MeetingInvite represents a meeting invitation with all necessary details
 // to send via email or calendar API
 type MeetingInvite struct {
 	Subject     string   // Meeting subject/title
-	Description string   // Meeting description/body
+	Description string   // Meeting description/body (cancellation comment for cancellations)
 	StartTime   string   // ISO8601 format (e.g., "2024-01-15T14:00:00Z")
 	EndTime     string   // ISO8601 format (e.g., "2024-01-15T15:00:00Z")
 	Attendees   []string // List of attendee email addresses
 	Organizer   string   // Organizer email address
 	Location    string   // Meeting location (physical or virtual)
+	UID         string   // Stable iCalendar UID shared by the invite, its updates and cancellation
+	Sequence    int      // iCalendar SEQUENCE; must increase with every update
+
+	// RSVPLinks maps lowercase attendee email to that attendee's RSVP page URL
+	// Senders that cannot carry per-recipient content may ignore it
+	RSVPLinks map[string]string
 }
 
 // Sender defines the interface for sending meeting invitations
 // Implementations can use different mechanisms (SMTP, Graph API, etc.)
+// and stop delivering once ctx is cancelled or past its deadline
 type Sender interface {
 	// SendInvite sends a meeting invitation to all attendees
 	// Returns an error if the invitation could not be sent
-	SendInvite(invite *MeetingInvite) error
+	SendInvite(ctx context.Context, invite *MeetingInvite) error
+
+	// SendUpdate notifies attendees that a previously sent invite has changed
+	// The invite must carry the original UID and a higher Sequence
+	SendUpdate(ctx context.Context, invite *MeetingInvite) error
+
+	// SendCancellation notifies attendees that a previously sent invite was cancelled
+	SendCancellation(ctx context.Context, invite *MeetingInvite) error
 }
 
+// InviteUID derives the iCalendar UID used for all notices about an event
+func InviteUID(eventID string) string {
+	return eventID + "@gruve.ai"
+}
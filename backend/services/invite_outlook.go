@@ -52,11 +52,136 @@ func NewOutlookSenderFromEnv() *OutlookSender {
 	}
 }
 
+// inviteUIDPropertyID is the single-value extended property used to tag events created
+// by OutlookSender with the invite UID, so later updates and cancellations can find them
+const inviteUIDPropertyID = "String {9c0b3f5e-4a1d-4e3b-8f6a-2d7c5e1b9a40} Name SchedulerInviteUID"
+
 // SendInvite sends a meeting invitation via Microsoft Graph API
 // Creates a calendar event which automatically sends invites to all attendees
-func (o *OutlookSender) SendInvite(invite *MeetingInvite) error {
+func (o *OutlookSender) SendInvite(ctx context.Context, invite *MeetingInvite) error {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	graphClient, organizerEmail, err := o.client(invite)
+	if err != nil {
+		return err
+	}
+
+	requestBody, timeZone, err := buildOutlookEvent(invite)
+	if err != nil {
+		return err
+	}
+
+	// Tag the event with the invite UID so it can be updated or cancelled later
+	if invite.UID != "" {
+		propertyID := inviteUIDPropertyID
+		uidProperty := graphmodels.NewSingleValueLegacyExtendedProperty()
+		uidProperty.SetId(&propertyID)
+		uidProperty.SetValue(&invite.UID)
+		requestBody.SetSingleValueExtendedProperties([]graphmodels.SingleValueLegacyExtendedPropertyable{uidProperty})
+	}
+
+	// Allow new time proposals
+	allowNewTimeProposals := true
+	requestBody.SetAllowNewTimeProposals(&allowNewTimeProposals)
+
+	// Set up headers with timezone preference
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", fmt.Sprintf("outlook.timezone=\"%s\"", timeZone))
+
+	configuration := &graphusers.ItemEventsRequestBuilderPostRequestConfiguration{
+		Headers: headers,
+	}
+
+	// Create the event using Graph API
+	// This automatically sends calendar invitations to all attendees
+	createdEvent, err := outlookUser(graphClient, organizerEmail).Events().Post(ctx, requestBody, configuration)
+	if err != nil {
+		return fmt.Errorf("failed to create event via Graph API: %v", err)
+	}
+
+	if createdEvent.GetId() != nil {
+		log.Printf("Successfully created event %s and sent invites to %d attendees via Outlook/Graph API", 
+			*createdEvent.GetId(), len(invite.Attendees))
+	} else {
+		log.Printf("Successfully created event and sent invites to %d attendees via Outlook/Graph API", 
+			len(invite.Attendees))
+	}
+
+	return nil
+}
+
+// SendUpdate patches the event previously created for this invite
+// Graph sends the update notices to attendees
+func (o *OutlookSender) SendUpdate(ctx context.Context, invite *MeetingInvite) error {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	graphClient, organizerEmail, err := o.client(invite)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := findOutlookEventByUID(ctx, graphClient, organizerEmail, invite.UID)
+	if err != nil {
+		return err
+	}
+
+	requestBody, timeZone, err := buildOutlookEvent(invite)
+	if err != nil {
+		return err
+	}
+
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", fmt.Sprintf("outlook.timezone=\"%s\"", timeZone))
+
+	configuration := &graphusers.ItemEventsEventItemRequestBuilderPatchRequestConfiguration{
+		Headers: headers,
+	}
+
+	_, err = outlookUser(graphClient, organizerEmail).Events().ByEventId(eventID).Patch(ctx, requestBody, configuration)
+	if err != nil {
+		return fmt.Errorf("failed to update event via Graph API: %v", err)
+	}
+
+	log.Printf("Successfully updated event %s and notified %d attendees via Outlook/Graph API", eventID, len(invite.Attendees))
+	return nil
+}
+
+// SendCancellation cancels the event previously created for this invite
+// invite.Description is sent as the cancellation comment
+func (o *OutlookSender) SendCancellation(ctx context.Context, invite *MeetingInvite) error {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+
+	graphClient, organizerEmail, err := o.client(invite)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := findOutlookEventByUID(ctx, graphClient, organizerEmail, invite.UID)
+	if err != nil {
+		return err
+	}
+
+	body := graphusers.NewItemEventsItemCancelPostRequestBody()
+	if invite.Description != "" {
+		body.SetComment(&invite.Description)
+	}
+
+	err = outlookUser(graphClient, organizerEmail).Events().ByEventId(eventID).Cancel().Post(ctx, body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to cancel event via Graph API: %v", err)
+	}
+
+	log.Printf("Successfully cancelled event %s and notified %d attendees via Outlook/Graph API", eventID, len(invite.Attendees))
+	return nil
+}
+
+// client initializes a Graph SDK client and resolves the organizer for an invite
+func (o *OutlookSender) client(invite *MeetingInvite) (*msgraphsdk.GraphServiceClient, string, error) {
 	if o.AccessToken == "" {
-		return fmt.Errorf("access token not provided - Outlook sender requires authentication")
+		return nil, "", fmt.Errorf("access token not provided - Outlook sender requires authentication")
 	}
 
 	// Use organizer from invite if provided, otherwise use sender's organizer
@@ -65,17 +190,55 @@ func (o *OutlookSender) SendInvite(invite *MeetingInvite) error {
 		organizerEmail = o.OrganizerEmail
 	}
 	if organizerEmail == "" {
-		return fmt.Errorf("organizer email not provided")
+		return nil, "", fmt.Errorf("organizer email not provided")
 	}
 
 	// Initialize Graph SDK client
 	authProvider := &TokenAuthProvider{AccessToken: o.AccessToken}
 	adapter, err := msgraphsdk.NewGraphRequestAdapter(authProvider)
 	if err != nil {
-		return fmt.Errorf("error creating graph adapter: %v", err)
+		return nil, "", fmt.Errorf("error creating graph adapter: %v", err)
+	}
+	return msgraphsdk.NewGraphServiceClient(adapter), organizerEmail, nil
+}
+
+// outlookUser returns the /me request builder for "me" organizers, /users/{email} otherwise
+func outlookUser(graphClient *msgraphsdk.GraphServiceClient, organizerEmail string) *graphusers.UserItemRequestBuilder {
+	if organizerEmail == "me" || strings.HasSuffix(strings.ToLower(organizerEmail), "@me") {
+		return graphClient.Me()
 	}
-	graphClient := msgraphsdk.NewGraphServiceClient(adapter)
+	return graphClient.Users().ByUserId(organizerEmail)
+}
 
+// findOutlookEventByUID looks up the organizer's event tagged with the invite UID
+func findOutlookEventByUID(ctx context.Context, graphClient *msgraphsdk.GraphServiceClient, organizerEmail, uid string) (string, error) {
+	if uid == "" {
+		return "", fmt.Errorf("invite UID not provided - cannot locate the original event")
+	}
+
+	filter := fmt.Sprintf("singleValueExtendedProperties/Any(ep: ep/id eq '%s' and ep/value eq '%s')",
+		inviteUIDPropertyID, strings.ReplaceAll(uid, "'", "''"))
+	configuration := &graphusers.ItemEventsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &graphusers.ItemEventsRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Select: []string{"id"},
+		},
+	}
+
+	events, err := outlookUser(graphClient, organizerEmail).Events().Get(ctx, configuration)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up event via Graph API: %v", err)
+	}
+	if len(events.GetValue()) == 0 || events.GetValue()[0].GetId() == nil {
+		return "", fmt.Errorf("no Outlook event found for invite %s: %w", uid, ErrEventNotFound)
+	}
+
+	return *events.GetValue()[0].GetId(), nil
+}
+
+// buildOutlookEvent converts an invite into a Graph event body
+// Returns the event and the time zone its start and end are expressed in
+func buildOutlookEvent(invite *MeetingInvite) (graphmodels.Eventable, string, error) {
 	// Create event request body
 	requestBody := graphmodels.NewEvent()
 	
@@ -94,37 +257,20 @@ func (o *OutlookSender) SendInvite(invite *MeetingInvite) error {
 	// Parse and set start time
 	startTime, err := parseTimeString(invite.StartTime)
 	if err != nil {
-		return fmt.Errorf("invalid start time format: %w", err)
-	}
-	
-	// Determine timezone from parsed time or use default
-	timeZone := getTimeZoneFromTime(startTime)
-	
-	// Set up headers with timezone preference
-	headers := abstractions.NewRequestHeaders()
-	headers.Add("Prefer", fmt.Sprintf("outlook.timezone=\"%s\"", timeZone))
-
-	configuration := &graphusers.ItemEventsRequestBuilderPostRequestConfiguration{
-		Headers: headers,
+		return nil, "", fmt.Errorf("invalid start time format: %w", err)
 	}
 	
-	start := graphmodels.NewDateTimeTimeZone()
-	// Format as dateTime without timezone indicator (Graph API expects format like "2017-04-15T12:00:00")
-	startDateTime := startTime.Format("2006-01-02T15:04:05")
-	start.SetDateTime(&startDateTime)
-	start.SetTimeZone(&timeZone)
-	requestBody.SetStart(start)
+	// Write the times in the zone they were given in; bare offsets have no Windows ID and become UTC
+	loc := startTime.Location()
+	timeZone := GraphTimeZoneName(loc)
+	requestBody.SetStart(NewGraphDateTime(startTime, loc))
 
 	// Parse and set end time
 	endTime, err := parseTimeString(invite.EndTime)
 	if err != nil {
-		return fmt.Errorf("invalid end time format: %w", err)
+		return nil, "", fmt.Errorf("invalid end time format: %w", err)
 	}
-	end := graphmodels.NewDateTimeTimeZone()
-	endDateTime := endTime.Format("2006-01-02T15:04:05")
-	end.SetDateTime(&endDateTime)
-	end.SetTimeZone(&timeZone)
-	requestBody.SetEnd(end)
+	requestBody.SetEnd(NewGraphDateTime(endTime, loc))
 
 	// Set location
 	if invite.Location != "" {
@@ -154,34 +300,7 @@ func (o *OutlookSender) SendInvite(invite *MeetingInvite) error {
 	}
 	requestBody.SetAttendees(attendeeObjs)
 
-	// Allow new time proposals
-	allowNewTimeProposals := true
-	requestBody.SetAllowNewTimeProposals(&allowNewTimeProposals)
-
-	// Create the event using Graph API
-	// This automatically sends calendar invitations to all attendees
-	var createdEvent graphmodels.Eventable
-	if organizerEmail == "me" || strings.HasSuffix(strings.ToLower(organizerEmail), "@me") {
-		// Use /me/events endpoint for authenticated user
-		createdEvent, err = graphClient.Me().Events().Post(context.Background(), requestBody, configuration)
-	} else {
-		// Use /users/{email}/events endpoint for specific user
-		createdEvent, err = graphClient.Users().ByUserId(organizerEmail).Events().Post(context.Background(), requestBody, configuration)
-	}
-
-	if err != nil {
-		return fmt.Errorf("failed to create event via Graph API: %v", err)
-	}
-
-	if createdEvent.GetId() != nil {
-		log.Printf("Successfully created event %s and sent invites to %d attendees via Outlook/Graph API", 
-			*createdEvent.GetId(), len(invite.Attendees))
-	} else {
-		log.Printf("Successfully created event and sent invites to %d attendees via Outlook/Graph API", 
-			len(invite.Attendees))
-	}
-
-	return nil
+	return requestBody, timeZone, nil
 }
 
 // parseTimeString parses a time string in various formats (RFC3339, ISO8601, etc.)
@@ -207,32 +326,6 @@ func parseTimeString(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse time string: %s", timeStr)
 }
 
-// getTimeZoneFromTime determines the timezone from the time or uses a default
-// In production, this should be determined from user preferences or invite data
-func getTimeZoneFromTime(t time.Time) string {
-	// Try to get timezone from the time's location
-	if t.Location() != nil && t.Location().String() != "UTC" {
-		// Convert common Go timezone names to IANA format
-		loc := t.Location().String()
-		if strings.Contains(loc, "IST") || strings.Contains(loc, "Asia/Kolkata") {
-			return "Asia/Kolkata"
-		}
-		if strings.Contains(loc, "PST") || strings.Contains(loc, "Pacific") {
-			return "Pacific Standard Time"
-		}
-		if strings.Contains(loc, "EST") || strings.Contains(loc, "Eastern") {
-			return "Eastern Standard Time"
-		}
-		// Return the location string if it looks like an IANA timezone
-		if strings.Contains(loc, "/") {
-			return loc
-		}
-	}
-	
-	// Default to UTC
-	return "UTC"
-}
-
 // extractNameFromEmail extracts a display name from an email address
 // e.g., "john.doe@example.com" -> "John Doe"
 func extractNameFromEmail(email string) string {
@@ -2,12 +2,15 @@ package services
 
 import (
 	"Smart-Meeting-Scheduler/models"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // MockGraphClient implements GraphClient using local Postgres database
@@ -23,7 +26,7 @@ func NewMockGraphClient(db *sql.DB, teamsMeetingBaseURL string) *MockGraphClient
 
 // GetCalendarView retrieves calendar events for a user within a time range from local DB
 // Includes events where user is organizer OR attendee
-func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endTime time.Time) ([]models.Event, error) {
+func (m *MockGraphClient) GetCalendarView(ctx context.Context, userIdentifier string, startTime, endTime time.Time) ([]models.Event, bool, error) {
 	// First, try to resolve the userIdentifier to a user ID or email
 	// It could be a display name, email, or UUID
 	var userEmail string
@@ -38,7 +41,7 @@ func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endT
 			WHERE id = $1
 			LIMIT 1
 		`
-		err := m.DB.QueryRow(userQuery, userID).Scan(&userEmail)
+		err := m.DB.QueryRowContext(ctx, userQuery, userID).Scan(&userEmail)
 		if err != nil {
 			// If not found, use the ID as-is
 			userEmail = userID
@@ -50,7 +53,7 @@ func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endT
 			WHERE email = $1 OR display_name = $1 OR user_principal_name = $1
 			LIMIT 1
 		`
-		err := m.DB.QueryRow(userQuery, userIdentifier).Scan(&userID, &userEmail)
+		err := m.DB.QueryRowContext(ctx, userQuery, userIdentifier).Scan(&userID, &userEmail)
 		if err != nil {
 			// If not found in users table, use the identifier as email directly
 			userEmail = userIdentifier
@@ -59,21 +62,24 @@ func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endT
 	}
 
 	// Query events where user is organizer OR attendee (case-insensitive)
+	// Imported events are only in the calendar of the user who imported them
 	query := `
 		SELECT DISTINCT e.id, e.subject, e.start_time, e.end_time, e.organizer, 
-		       e.location, e.is_online, e.online_url
+		       e.location, e.is_online, e.online_url, e.sensitivity, e.is_all_day, e.show_as
 		FROM mock_events e
 		LEFT JOIN mock_event_attendees ea ON e.id = ea.event_id
-		WHERE (LOWER(e.organizer) = LOWER($1) OR LOWER(e.organizer) = LOWER($4) 
-		       OR LOWER(ea.attendee_email) = LOWER($1) OR LOWER(ea.attendee_email) = LOWER($4))
+		WHERE ((e.imported_by IS NULL
+		        AND (LOWER(e.organizer) = LOWER($1) OR LOWER(e.organizer) = LOWER($4) 
+		             OR LOWER(ea.attendee_email) = LOWER($1) OR LOWER(ea.attendee_email) = LOWER($4)))
+		       OR e.imported_by = LOWER($1))
 		  AND e.start_time < $3
 		  AND e.end_time > $2
 		ORDER BY e.start_time ASC
 	`
 
-	rows, err := m.DB.Query(query, userEmail, startTime, endTime, userID)
+	rows, err := m.DB.QueryContext(ctx, query, userEmail, startTime, endTime, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %v", err)
+		return nil, false, fmt.Errorf("failed to query events: %v", err)
 	}
 	defer rows.Close()
 
@@ -90,9 +96,12 @@ func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endT
 			&location,
 			&event.IsOnline,
 			&onlineURL,
+			&event.Sensitivity,
+			&event.IsAllDay,
+			&event.ShowAs,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %v", err)
+			return nil, false, fmt.Errorf("failed to scan event: %v", err)
 		}
 
 		if location.Valid {
@@ -103,32 +112,34 @@ func (m *MockGraphClient) GetCalendarView(userIdentifier string, startTime, endT
 		}
 
 		// Get attendees for this event
-		attendees, _ := m.getEventAttendees(event.ID)
+		attendees, _ := m.getEventAttendees(ctx, event.ID)
 		event.Attendees = attendees
 
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, false, nil
 }
 
 // GetUserEvents retrieves all events for a user within a time range
-func (m *MockGraphClient) GetUserEvents(userEmail string, startTime, endTime time.Time) ([]models.Event, error) {
+func (m *MockGraphClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
 	// For mock, this is similar to GetCalendarView but includes events where user is an attendee
 	query := `
 		SELECT DISTINCT e.id, e.subject, e.start_time, e.end_time, e.organizer, 
-		       e.location, e.is_online, e.online_url, e.body_preview
+		       e.location, e.is_online, e.online_url, e.body_preview, e.sensitivity,
+		       e.is_all_day, e.show_as
 		FROM mock_events e
 		LEFT JOIN mock_event_attendees ea ON e.id = ea.event_id
-		WHERE (e.organizer = $1 OR ea.attendee_email = $1)
+		WHERE ((e.imported_by IS NULL AND (e.organizer = $1 OR ea.attendee_email = $1))
+		       OR e.imported_by = LOWER($1))
 		  AND e.start_time < $3
 		  AND e.end_time > $2
 		ORDER BY e.start_time ASC
 	`
 
-	rows, err := m.DB.Query(query, userEmail, startTime, endTime)
+	rows, err := m.DB.QueryContext(ctx, query, userEmail, startTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %v", err)
+		return nil, false, fmt.Errorf("failed to query events: %v", err)
 	}
 	defer rows.Close()
 
@@ -146,9 +157,12 @@ func (m *MockGraphClient) GetUserEvents(userEmail string, startTime, endTime tim
 			&event.IsOnline,
 			&onlineURL,
 			&bodyPreview,
+			&event.Sensitivity,
+			&event.IsAllDay,
+			&event.ShowAs,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %v", err)
+			return nil, false, fmt.Errorf("failed to scan event: %v", err)
 		}
 
 		if location.Valid {
@@ -162,43 +176,88 @@ func (m *MockGraphClient) GetUserEvents(userEmail string, startTime, endTime tim
 		}
 
 		// Get attendees for this event
-		attendees, _ := m.getEventAttendees(event.ID)
+		attendees, _ := m.getEventAttendees(ctx, event.ID)
 		event.Attendees = attendees
 
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, false, nil
+}
+
+// GetSchedules returns the free/busy schedules of several users from the local DB in one query
+// Everyone is assumed to work 9am-6pm on weekdays in their own time zone
+func (m *MockGraphClient) GetSchedules(ctx context.Context, userEmails []string, startTime, endTime time.Time, interval time.Duration) ([]models.Schedule, error) {
+	query := `
+		SELECT DISTINCT LOWER(p.email), e.id, e.start_time, e.end_time, e.show_as
+		FROM UNNEST($1::text[]) AS p(email)
+		JOIN mock_events e ON e.start_time < $3 AND e.end_time > $2
+		LEFT JOIN mock_event_attendees ea ON e.id = ea.event_id
+		WHERE (e.imported_by IS NULL
+		       AND (LOWER(e.organizer) = LOWER(p.email) OR LOWER(ea.attendee_email) = LOWER(p.email)))
+		   OR e.imported_by = LOWER(p.email)
+		ORDER BY e.start_time ASC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(userEmails), startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %v", err)
+	}
+	defer rows.Close()
+
+	items := make(map[string][]models.ScheduleItem)
+	for rows.Next() {
+		var email, eventID string
+		var item models.ScheduleItem
+		if err := rows.Scan(&email, &eventID, &item.Start, &item.End, &item.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule item: %v", err)
+		}
+		items[email] = append(items[email], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %v", err)
+	}
+
+	interval = scheduleInterval(interval)
+	schedules := make([]models.Schedule, 0, len(userEmails))
+	for _, email := range userEmails {
+		userItems := items[strings.ToLower(email)]
+		if userItems == nil {
+			userItems = []models.ScheduleItem{}
+		}
+		schedules = append(schedules, models.Schedule{
+			UserEmail:        email,
+			AvailabilityView: availabilityView(userItems, startTime, endTime, interval),
+			Items:            userItems,
+			WorkingHours:     standardWorkingHours(UserLocation(ctx, m.DB, email)),
+		})
+	}
+	return schedules, nil
 }
 
 // FindMeetingTimes finds available meeting times for a group of attendees
-func (m *MockGraphClient) FindMeetingTimes(organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
+func (m *MockGraphClient) FindMeetingTimes(ctx context.Context, organizer string, attendees []string, duration time.Duration, startTime, endTime time.Time) ([]models.MeetingSuggestion, error) {
 	// Get all busy times for organizer and attendees
 	allEmails := append([]string{organizer}, attendees...)
 	busySlots := make(map[string][]models.TimeSlot)
 
 	for _, email := range allEmails {
-		events, err := m.GetCalendarView(email, startTime, endTime)
+		events, _, err := m.GetCalendarView(ctx, email, startTime, endTime)
 		if err != nil {
 			continue // Skip if user not found
 		}
 
-		for _, event := range events {
-			busySlots[email] = append(busySlots[email], models.TimeSlot{
-				Start: event.Start,
-				End:   event.End,
-			})
-		}
+		busySlots[email] = BusyIntervals(events)
 	}
 
 	// Find common free slots
-	suggestions := m.findCommonFreeSlots(busySlots, startTime, endTime, duration)
+	suggestions := commonFreeSlots(busySlots, startTime, endTime, duration)
 
 	return suggestions, nil
 }
 
 // CreateOnlineMeeting creates a new online meeting in local DB
-func (m *MockGraphClient) CreateOnlineMeeting(organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
+func (m *MockGraphClient) CreateOnlineMeeting(ctx context.Context, organizer string, start time.Time, end time.Time, subject string, attendees []string) (models.Event, error) {
 	eventID := uuid.New().String()
 	baseURL := strings.TrimRight(m.TeamsMeetingBaseURL, "/")
 	onlineURL := fmt.Sprintf("%s/%s", baseURL, eventID)
@@ -208,14 +267,14 @@ func (m *MockGraphClient) CreateOnlineMeeting(organizer string, start time.Time,
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
-	_, err := m.DB.Exec(query, eventID, subject, start, end, organizer, true, onlineURL, time.Now())
+	_, err := m.DB.ExecContext(ctx, query, eventID, subject, start, end, organizer, true, onlineURL, time.Now())
 	if err != nil {
 		return models.Event{}, fmt.Errorf("failed to create online meeting: %v", err)
 	}
 
 	// Add attendees
 	for _, attendee := range attendees {
-		_, _ = m.DB.Exec(
+		_, _ = m.DB.ExecContext(ctx,
 			"INSERT INTO mock_event_attendees (event_id, attendee_email) VALUES ($1, $2)",
 			eventID, attendee,
 		)
@@ -234,7 +293,7 @@ func (m *MockGraphClient) CreateOnlineMeeting(organizer string, start time.Time,
 }
 
 // CreateCalendarEvent creates a regular calendar event in local DB
-func (m *MockGraphClient) CreateCalendarEvent(organizer string, event models.CreateMeetingRequest) (models.Event, error) {
+func (m *MockGraphClient) CreateCalendarEvent(ctx context.Context, organizer string, event models.CreateMeetingRequest) (models.Event, error) {
 	eventID := uuid.New().String()
 	onlineURL := ""
 
@@ -243,20 +302,25 @@ func (m *MockGraphClient) CreateCalendarEvent(organizer string, event models.Cre
 		onlineURL = fmt.Sprintf("%s/%s", baseURL, eventID)
 	}
 
+	sensitivity := event.Sensitivity
+	if sensitivity == "" {
+		sensitivity = models.SensitivityNormal
+	}
+
 	query := `
-		INSERT INTO mock_events (id, subject, start_time, end_time, organizer, location, is_online, online_url, body_preview, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO mock_events (id, subject, start_time, end_time, organizer, location, is_online, online_url, body_preview, created_at, sensitivity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
-	_, err := m.DB.Exec(query, eventID, event.Subject, event.Start, event.End, organizer,
-		event.Location, event.IsOnline, onlineURL, event.Description, time.Now())
+	_, err := m.DB.ExecContext(ctx, query, eventID, event.Subject, event.Start, event.End, organizer,
+		event.Location, event.IsOnline, onlineURL, event.Description, time.Now(), string(sensitivity))
 	if err != nil {
 		return models.Event{}, fmt.Errorf("failed to create calendar event: %v", err)
 	}
 
 	// Add attendees
 	for _, attendee := range event.Attendees {
-		_, _ = m.DB.Exec(
+		_, _ = m.DB.ExecContext(ctx,
 			"INSERT INTO mock_event_attendees (event_id, attendee_email) VALUES ($1, $2)",
 			eventID, attendee,
 		)
@@ -273,84 +337,278 @@ func (m *MockGraphClient) CreateCalendarEvent(organizer string, event models.Cre
 		OnlineURL:   onlineURL,
 		BodyPreview: event.Description,
 		IsOnline:    event.IsOnline,
+		Sensitivity: sensitivity,
+		ShowAs:      models.ShowAsBusy,
 	}, nil
 }
 
-// GetAvailability checks availability for a user within a time range (UTC working hours)
-func (m *MockGraphClient) GetAvailability(userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
-	return m.GetAvailabilityWithTimezone(userEmail, startTime, endTime, "")
+// UpdateCalendarEvent applies a partial update to an event in local DB and bumps its sequence
+func (m *MockGraphClient) UpdateCalendarEvent(ctx context.Context, organizer string, eventID string, update models.UpdateMeetingRequest) (models.Event, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	event, err := m.getOwnedEvent(ctx, tx, organizer, eventID)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	if update.Subject != nil {
+		event.Subject = *update.Subject
+	}
+	if update.Start != nil {
+		event.Start = *update.Start
+	}
+	if update.End != nil {
+		event.End = *update.End
+	}
+	if update.Description != nil {
+		event.BodyPreview = *update.Description
+	}
+	if update.Location != nil {
+		event.Location = *update.Location
+	}
+	if !event.End.After(event.Start) {
+		return models.Event{}, fmt.Errorf("%w: end time must be after start time", ErrInvalidEvent)
+	}
+	event.Sequence++
+
+	query := `
+		UPDATE mock_events
+		SET subject = $2, start_time = $3, end_time = $4, location = $5, body_preview = $6,
+		    sequence = $7, updated_at = $8
+		WHERE id = $1
+	`
+	_, err = tx.ExecContext(ctx, query, eventID, event.Subject, event.Start, event.End, event.Location,
+		event.BodyPreview, event.Sequence, time.Now())
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to update calendar event: %v", err)
+	}
+
+	// Replace attendees only when a new list was provided
+	if update.Attendees != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM mock_event_attendees WHERE event_id = $1", eventID); err != nil {
+			return models.Event{}, fmt.Errorf("failed to update attendees: %v", err)
+		}
+		for _, attendee := range update.Attendees {
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO mock_event_attendees (event_id, attendee_email) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+				eventID, attendee,
+			)
+			if err != nil {
+				return models.Event{}, fmt.Errorf("failed to update attendees: %v", err)
+			}
+		}
+		event.Attendees = update.Attendees
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Event{}, fmt.Errorf("failed to commit event update: %v", err)
+	}
+
+	return event, nil
 }
 
-// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
-func (m *MockGraphClient) GetAvailabilityWithTimezone(userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
-	events, err := m.GetCalendarView(userEmail, startTime, endTime)
+// CancelCalendarEvent removes an event (and its attendees) from local DB
+func (m *MockGraphClient) CancelCalendarEvent(ctx context.Context, organizer string, eventID string, comment string) (models.Event, error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return models.AvailabilityResponse{}, err
+		return models.Event{}, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	event, err := m.getOwnedEvent(ctx, tx, organizer, eventID)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	// mock_event_attendees rows are removed by ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mock_events WHERE id = $1", eventID); err != nil {
+		return models.Event{}, fmt.Errorf("failed to cancel calendar event: %v", err)
 	}
 
-	busySlots := []models.TimeSlot{}
-	for _, event := range events {
-		busySlots = append(busySlots, models.TimeSlot{
-			Start: event.Start,
-			End:   event.End,
+	if err := tx.Commit(); err != nil {
+		return models.Event{}, fmt.Errorf("failed to commit event cancellation: %v", err)
+	}
+
+	// Cancellation notices must supersede the last update
+	event.Sequence++
+	return event, nil
+}
+
+// GetEventResponses returns attendee responses recorded for a meeting in local DB
+// Events seeded directly into mock_events have no RSVP history, so every attendee is reported as "none"
+func (m *MockGraphClient) GetEventResponses(ctx context.Context, organizer string, eventID string) ([]models.AttendeeResponse, error) {
+	responses, meetingOrganizer, err := models.NewMeetingStore(m.DB).GetResponses(ctx, eventID)
+	if err == nil {
+		if !strings.EqualFold(meetingOrganizer, organizer) {
+			return nil, ErrNotOrganizer
+		}
+		return responses, nil
+	}
+	if !errors.Is(err, models.ErrMeetingNotFound) {
+		return nil, fmt.Errorf("failed to load responses: %v", err)
+	}
+
+	var eventOrganizer string
+	err = m.DB.QueryRowContext(ctx, "SELECT organizer FROM mock_events WHERE id = $1", eventID).Scan(&eventOrganizer)
+	if err == sql.ErrNoRows {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event: %v", err)
+	}
+	if !strings.EqualFold(eventOrganizer, organizer) {
+		return nil, ErrNotOrganizer
+	}
+
+	attendees, err := m.getEventAttendees(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attendees: %v", err)
+	}
+
+	responses = []models.AttendeeResponse{}
+	for _, attendee := range attendees {
+		responses = append(responses, models.AttendeeResponse{
+			Email:  strings.ToLower(attendee),
+			Status: models.ResponseNone,
 		})
 	}
+	return responses, nil
+}
 
-	// Calculate free slots filtered by working hours in the specified timezone
-	standardSlots, extendedSlots := calculateFreeSlots(startTime, endTime, busySlots, timezone)
+// ImportEvents upserts events read from an .ics file, expanded over [windowStart, windowEnd), into local DB for owner
+// Occurrences are keyed by owner, UID and recurrence ID so re-importing a file updates them in place;
+// occurrences of a re-imported series within the window that are no longer in the file are removed,
+// while those outside it were not expanded and are kept.
+// Imported events show up only in the owner's calendar: the organizer and attendees named in the
+// file are stored with the event but do not make it part of their calendars
+func (m *MockGraphClient) ImportEvents(ctx context.Context, owner string, events []ICSEvent, windowStart, windowEnd time.Time) (models.ImportCalendarResponse, error) {
+	result := models.ImportCalendarResponse{}
 
-	totalBusyTime := 0
-	for _, slot := range busySlots {
-		totalBusyTime += int(slot.End.Sub(slot.Start).Minutes())
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer tx.Rollback()
+
+	// All-day events cover whole days of the owner's calendar
+	loc := UserLocation(ctx, m.DB, owner)
 
-	// Calculate total free time for standard hours
-	totalFreeTime := 0
-	for _, slot := range standardSlots {
-		totalFreeTime += int(slot.End.Sub(slot.Start).Minutes())
+	idsByUID := map[string][]string{}
+	var uids []string
+	for _, imported := range events {
+		event := AnchorAllDay(imported.Event, loc)
+		if event.ShowAs == "" {
+			event.ShowAs = models.ShowAsBusy
+		}
+		if event.Organizer == "" {
+			event.Organizer = owner
+		}
+
+		recurrenceID := ""
+		if !imported.RecurrenceID.IsZero() {
+			recurrenceID = imported.RecurrenceID.UTC().Format(time.RFC3339)
+		}
+		eventID := uuid.NewSHA1(uuid.NameSpaceURL, []byte("ics:"+strings.ToLower(owner)+"/"+imported.UID+"/"+recurrenceID)).String()
+		if _, ok := idsByUID[imported.UID]; !ok {
+			uids = append(uids, imported.UID)
+		}
+		idsByUID[imported.UID] = append(idsByUID[imported.UID], eventID)
+
+		// Columns are TIMESTAMP without time zone and hold UTC
+		var inserted bool
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO mock_events (id, subject, start_time, end_time, organizer, location, is_online,
+			                         online_url, body_preview, sequence, sensitivity, is_all_day, show_as,
+			                         ical_uid, imported_by, imported_attendees)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, LOWER($15), $16)
+			ON CONFLICT (id) DO UPDATE SET
+				subject = EXCLUDED.subject,
+				start_time = EXCLUDED.start_time,
+				end_time = EXCLUDED.end_time,
+				organizer = EXCLUDED.organizer,
+				location = EXCLUDED.location,
+				is_online = EXCLUDED.is_online,
+				online_url = EXCLUDED.online_url,
+				body_preview = EXCLUDED.body_preview,
+				sequence = EXCLUDED.sequence,
+				sensitivity = EXCLUDED.sensitivity,
+				is_all_day = EXCLUDED.is_all_day,
+				show_as = EXCLUDED.show_as,
+				imported_attendees = EXCLUDED.imported_attendees,
+				updated_at = CURRENT_TIMESTAMP
+			RETURNING (xmax = 0)
+		`, eventID, event.Subject, event.Start.UTC(), event.End.UTC(), event.Organizer, event.Location,
+			event.IsOnline, event.OnlineURL, event.BodyPreview, event.Sequence, string(event.Sensitivity),
+			event.IsAllDay, string(event.ShowAs), imported.UID, owner, pq.Array(event.Attendees)).Scan(&inserted)
+		if err != nil {
+			return result, fmt.Errorf("failed to import event %s: %v", imported.UID, err)
+		}
+		if inserted {
+			result.Imported++
+		} else {
+			result.Updated++
+		}
 	}
 
-	// Determine actual working hours based on standard free slots
-	workingHoursStart := startTime
-	workingHoursEnd := endTime
-	if len(standardSlots) > 0 {
-		workingHoursStart = standardSlots[0].Start
-		workingHoursEnd = standardSlots[len(standardSlots)-1].End
-	} else if len(extendedSlots) > 0 {
-		// If no standard slots, use extended slots for working hours
-		workingHoursStart = extendedSlots[0].Start
-		workingHoursEnd = extendedSlots[len(extendedSlots)-1].End
+	for _, uid := range uids {
+		removed, err := tx.ExecContext(ctx, `
+			DELETE FROM mock_events
+			WHERE imported_by = LOWER($1) AND ical_uid = $2 AND NOT (id = ANY($3))
+			  AND start_time < $5 AND end_time > $4
+		`, owner, uid, pq.Array(idsByUID[uid]), windowStart.UTC(), windowEnd.UTC())
+		if err != nil {
+			return result, fmt.Errorf("failed to remove stale occurrences of %s: %v", uid, err)
+		}
+		if n, err := removed.RowsAffected(); err == nil {
+			result.Removed += int(n)
+		}
 	}
 
-	// Ensure arrays are never nil (return empty slices)
-	if standardSlots == nil {
-		standardSlots = []models.TimeSlot{}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit import: %v", err)
 	}
-	if extendedSlots == nil {
-		extendedSlots = []models.TimeSlot{}
+	return result, nil
+}
+
+// containsFold reports whether emails contains email, ignoring case
+func containsFold(emails []string, email string) bool {
+	for _, e := range emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
 	}
-	if busySlots == nil {
-		busySlots = []models.TimeSlot{}
+	return false
+}
+
+// GetAvailability checks availability for a user within a time range (UTC working hours)
+func (m *MockGraphClient) GetAvailability(ctx context.Context, userEmail string, startTime, endTime time.Time) (models.AvailabilityResponse, error) {
+	return m.GetAvailabilityWithTimezone(ctx, userEmail, startTime, endTime, "")
+}
+
+// GetAvailabilityWithTimezone checks availability with timezone-aware working hours filtering
+func (m *MockGraphClient) GetAvailabilityWithTimezone(ctx context.Context, userEmail string, startTime, endTime time.Time, timezone string) (models.AvailabilityResponse, error) {
+	events, truncated, err := m.GetCalendarView(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return models.AvailabilityResponse{}, err
 	}
 
-	return models.AvailabilityResponse{
-		UserEmail:          userEmail,
-		FreeSlots:          standardSlots,
-		ExtendedHoursSlots: extendedSlots,
-		BusySlots:          busySlots,
-		WorkingHours: models.TimeSlot{
-			Start: workingHoursStart,
-			End:   workingHoursEnd,
-		},
-		TotalFreeTime: totalFreeTime,
-		TotalBusyTime: totalBusyTime,
-	}, nil
+	availability := availabilityFromEvents(userEmail, events, startTime, endTime, timezone)
+	availability.Truncated = truncated
+	return availability, nil
 }
 
-// Helper function to get attendees for an event
-func (m *MockGraphClient) getEventAttendees(eventID string) ([]string, error) {
-	query := "SELECT attendee_email FROM mock_event_attendees WHERE event_id = $1"
-	rows, err := m.DB.Query(query, eventID)
+// Helper function to get attendees for an event, including those named by an imported event
+func (m *MockGraphClient) getEventAttendees(ctx context.Context, eventID string) ([]string, error) {
+	query := `
+		SELECT attendee_email FROM mock_event_attendees WHERE event_id = $1
+		UNION ALL
+		SELECT UNNEST(imported_attendees) FROM mock_events WHERE id = $1
+	`
+	rows, err := m.DB.QueryContext(ctx, query, eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -367,8 +625,59 @@ func (m *MockGraphClient) getEventAttendees(eventID string) ([]string, error) {
 	return attendees, nil
 }
 
-// Helper function to find common free slots for all attendees
-func (m *MockGraphClient) findCommonFreeSlots(busySlots map[string][]models.TimeSlot, startTime, endTime time.Time, duration time.Duration) []models.MeetingSuggestion {
+// getOwnedEvent loads an event for update inside tx and checks that organizer owns it
+func (m *MockGraphClient) getOwnedEvent(ctx context.Context, tx *sql.Tx, organizer string, eventID string) (models.Event, error) {
+	query := `
+		SELECT id, subject, start_time, end_time, organizer, location, is_online, online_url,
+		       body_preview, sequence, sensitivity, is_all_day, show_as
+		FROM mock_events
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var event models.Event
+	var location, onlineURL, bodyPreview sql.NullString
+	err := tx.QueryRowContext(ctx, query, eventID).Scan(
+		&event.ID,
+		&event.Subject,
+		&event.Start,
+		&event.End,
+		&event.Organizer,
+		&location,
+		&event.IsOnline,
+		&onlineURL,
+		&bodyPreview,
+		&event.Sequence,
+		&event.Sensitivity,
+		&event.IsAllDay,
+		&event.ShowAs,
+	)
+	if err == sql.ErrNoRows {
+		return models.Event{}, ErrEventNotFound
+	}
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to load event: %v", err)
+	}
+
+	if !strings.EqualFold(event.Organizer, organizer) {
+		return models.Event{}, ErrNotOrganizer
+	}
+
+	event.Location = location.String
+	event.OnlineURL = onlineURL.String
+	event.BodyPreview = bodyPreview.String
+
+	attendees, err := m.getEventAttendees(ctx, event.ID)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to load attendees: %v", err)
+	}
+	event.Attendees = attendees
+
+	return event, nil
+}
+
+// commonFreeSlots suggests up to five slots of duration in which nobody in busySlots is busy
+func commonFreeSlots(busySlots map[string][]models.TimeSlot, startTime, endTime time.Time, duration time.Duration) []models.MeetingSuggestion {
 	// Merge all busy slots
 	allBusySlots := []models.TimeSlot{}
 	for _, slots := range busySlots {
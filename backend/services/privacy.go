@@ -0,0 +1,175 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultSharingLevel returns the sharing level of users who have not configured one
+// Set with DEFAULT_SHARING_LEVEL (freeBusy, limited or full); defaults to limited
+func DefaultSharingLevel() models.SharingLevel {
+	level := models.SharingLevel(os.Getenv("DEFAULT_SHARING_LEVEL"))
+	if level.IsValid() {
+		return level
+	}
+	return models.SharingLimited
+}
+
+// GetSharingLevel returns how much of owner's calendar other people may see
+// Falls back to DefaultSharingLevel when no database is configured or the lookup fails
+func GetSharingLevel(ctx context.Context, db *sql.DB, owner string) models.SharingLevel {
+	if db == nil {
+		return DefaultSharingLevel()
+	}
+
+	level, ok, err := models.NewSharingStore(db).GetSharingLevel(ctx, owner)
+	if err != nil {
+		log.Printf("Warning: Failed to load sharing level for %s: %v", owner, err)
+		return DefaultSharingLevel()
+	}
+	if !ok || !level.IsValid() {
+		return DefaultSharingLevel()
+	}
+	return level
+}
+
+// SharesCalendarWith reports whether owner shares more than free/busy times with viewer
+// Everyone shares their own calendar with themselves
+func SharesCalendarWith(ctx context.Context, db *sql.DB, owner, viewer string) bool {
+	if viewer != "" && strings.EqualFold(owner, viewer) {
+		return true
+	}
+	level := GetSharingLevel(ctx, db, owner)
+	return level == models.SharingLimited || level == models.SharingFull
+}
+
+// VisibleEvents returns owner's events as viewer may see them under owner's stored sharing level
+// An empty viewer is an anonymous one, such as the holder of a calendar feed URL
+func VisibleEvents(ctx context.Context, db *sql.DB, owner, viewer string, events []models.Event) []models.Event {
+	if viewer != "" && strings.EqualFold(owner, viewer) {
+		return events
+	}
+	return MaskEvents(events, owner, viewer, GetSharingLevel(ctx, db, owner))
+}
+
+// PrivacyGraphClient masks the calendar events it reads for Viewer with VisibleEvents
+// Everything else passes through to the wrapped client
+type PrivacyGraphClient struct {
+	GraphClient
+	DB     *sql.DB
+	Viewer string
+}
+
+// WithPrivacy wraps client so that events of other users are masked for viewer
+func WithPrivacy(client GraphClient, db *sql.DB, viewer string) GraphClient {
+	return &PrivacyGraphClient{GraphClient: client, DB: db, Viewer: viewer}
+}
+
+// GetCalendarView retrieves userEmail's calendar events as the viewer may see them
+func (p *PrivacyGraphClient) GetCalendarView(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	events, truncated, err := p.GraphClient.GetCalendarView(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return nil, false, err
+	}
+	return VisibleEvents(ctx, p.DB, userEmail, p.Viewer, events), truncated, nil
+}
+
+// GetUserEvents retrieves userEmail's events as the viewer may see them
+func (p *PrivacyGraphClient) GetUserEvents(ctx context.Context, userEmail string, startTime, endTime time.Time) ([]models.Event, bool, error) {
+	events, truncated, err := p.GraphClient.GetUserEvents(ctx, userEmail, startTime, endTime)
+	if err != nil {
+		return nil, false, err
+	}
+	return VisibleEvents(ctx, p.DB, userEmail, p.Viewer, events), truncated, nil
+}
+
+// MaskEvents strips the details of owner's events that viewer is not entitled to see
+// Owners, organizers and attendees see events in full. Everyone else is limited by owner's
+// sharing level, and private or confidential events are always reduced to free/busy for them.
+func MaskEvents(events []models.Event, owner, viewer string, level models.SharingLevel) []models.Event {
+	if viewer != "" && strings.EqualFold(owner, viewer) {
+		return events
+	}
+
+	masked := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		masked = append(masked, MaskEvent(event, viewer, level))
+	}
+	return masked
+}
+
+// MaskEvent applies MaskEvents to a single event of another user
+func MaskEvent(event models.Event, viewer string, level models.SharingLevel) models.Event {
+	if viewer != "" && (strings.EqualFold(event.Organizer, viewer) || containsFold(event.Attendees, viewer)) {
+		return event
+	}
+
+	private := event.Sensitivity == models.SensitivityPrivate || event.Sensitivity == models.SensitivityConfidential
+	switch {
+	case private || level == models.SharingFreeBusy || !level.IsValid():
+		subject := "Busy"
+		if private {
+			subject = "Private appointment"
+		}
+		return models.Event{
+			ID:          maskedEventID(event.ID),
+			Subject:     subject,
+			Start:       event.Start,
+			End:         event.End,
+			Attendees:   []string{},
+			Sensitivity: event.Sensitivity,
+			IsAllDay:    event.IsAllDay,
+			ShowAs:      event.ShowAs,
+		}
+
+	case level == models.SharingLimited:
+		return models.Event{
+			ID:          event.ID,
+			Subject:     event.Subject,
+			Start:       event.Start,
+			End:         event.End,
+			Organizer:   event.Organizer,
+			Attendees:   []string{},
+			Location:    event.Location,
+			IsOnline:    event.IsOnline,
+			Sensitivity: event.Sensitivity,
+			IsAllDay:    event.IsAllDay,
+			ShowAs:      event.ShowAs,
+		}
+
+	default:
+		return event
+	}
+}
+
+// maskedEventID derives a stable ID for an event reduced to free/busy
+// Feed UIDs and cache merges stay distinct per event without revealing the provider's event ID
+func maskedEventID(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(id))
+	return "masked-" + hex.EncodeToString(sum[:16])
+}
+
+// BusyIntervals reduces events to the intervals in which their owner is unavailable
+// Events shown as free or working elsewhere are left out, so an all-day reminder does not
+// block the day while an all-day out-of-office event does.
+// This is the only calendar data that may be shared with third-party slot providers
+func BusyIntervals(events []models.Event) []models.TimeSlot {
+	slots := make([]models.TimeSlot, 0, len(events))
+	for _, event := range events {
+		if !event.ShowAs.BlocksTime() {
+			continue
+		}
+		slots = append(slots, models.TimeSlot{Start: event.Start, End: event.End})
+	}
+	return slots
+}
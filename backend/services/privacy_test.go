@@ -0,0 +1,93 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaskEvent(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	event := models.Event{
+		ID:          "evt-1",
+		Subject:     "Design review",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Organizer:   "alice@gruve.ai",
+		Attendees:   []string{"bob@gruve.ai"},
+		Location:    "Room 1",
+		BodyPreview: "Agenda",
+		Sensitivity: models.SensitivityNormal,
+		ShowAs:      models.ShowAsBusy,
+	}
+	private := event
+	private.Sensitivity = models.SensitivityPrivate
+
+	tests := []struct {
+		name         string
+		event        models.Event
+		viewer       string
+		level        models.SharingLevel
+		wantSubject  string
+		wantLocation string
+		wantDetails  bool // organizer, attendees and body are kept
+	}{
+		{"full", event, "carol@gruve.ai", models.SharingFull, "Design review", "Room 1", true},
+		{"limited", event, "carol@gruve.ai", models.SharingLimited, "Design review", "Room 1", false},
+		{"free/busy", event, "carol@gruve.ai", models.SharingFreeBusy, "Busy", "", false},
+		{"private under full sharing", private, "carol@gruve.ai", models.SharingFull, "Private appointment", "", false},
+		{"private to an attendee", private, "Bob@gruve.ai", models.SharingFreeBusy, "Design review", "Room 1", true},
+		{"anonymous viewer", event, "", models.SharingLimited, "Design review", "Room 1", false},
+		{"unknown level", event, "carol@gruve.ai", models.SharingLevel("everything"), "Busy", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MaskEvent(tt.event, tt.viewer, tt.level)
+			if got.Subject != tt.wantSubject || got.Location != tt.wantLocation {
+				t.Errorf("MaskEvent() subject, location = %q, %q, want %q, %q", got.Subject, got.Location, tt.wantSubject, tt.wantLocation)
+			}
+			hasDetails := len(got.Attendees) > 0 && got.BodyPreview != ""
+			if hasDetails != tt.wantDetails {
+				t.Errorf("MaskEvent() attendees %v, body %q, want details %v", got.Attendees, got.BodyPreview, tt.wantDetails)
+			}
+			if !got.Start.Equal(tt.event.Start) || !got.End.Equal(tt.event.End) || got.ShowAs != tt.event.ShowAs {
+				t.Errorf("MaskEvent() changed the busy time to %v-%v %s", got.Start, got.End, got.ShowAs)
+			}
+			if got.ID == "" {
+				t.Error("MaskEvent() dropped the event ID")
+			}
+		})
+	}
+}
+
+func TestMaskEventsKeepsEventsApart(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	events := []models.Event{
+		{ID: "evt-1", Subject: "Dentist", Start: start, End: start.Add(time.Hour), Sensitivity: models.SensitivityPrivate},
+		{ID: "evt-2", Subject: "Therapy", Start: start, End: start.Add(time.Hour), Sensitivity: models.SensitivityPrivate},
+	}
+
+	masked := MaskEvents(events, "alice@gruve.ai", "carol@gruve.ai", models.SharingFreeBusy)
+	again := MaskEvents(events, "alice@gruve.ai", "carol@gruve.ai", models.SharingFreeBusy)
+
+	if masked[0].ID == masked[1].ID {
+		t.Errorf("masked events share the ID %q", masked[0].ID)
+	}
+	if eventMergeKey(masked[0]) == eventMergeKey(masked[1]) {
+		t.Errorf("masked events share the merge key %q", eventMergeKey(masked[0]))
+	}
+	for i := range masked {
+		if masked[i].ID != again[i].ID {
+			t.Errorf("masked ID of %s changed from %q to %q", events[i].ID, masked[i].ID, again[i].ID)
+		}
+		if strings.Contains(masked[i].ID, events[i].ID) {
+			t.Errorf("masked ID %q reveals the event ID %q", masked[i].ID, events[i].ID)
+		}
+	}
+
+	feed := GenerateCalendarFeed("Alice", masked, false, time.UTC)
+	if strings.Count(feed, "UID:"+InviteUID(masked[0].ID)) != 1 || strings.Count(feed, "UID:"+InviteUID(masked[1].ID)) != 1 {
+		t.Errorf("feed of masked events does not give each its own UID:\n%s", feed)
+	}
+}
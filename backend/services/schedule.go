@@ -0,0 +1,119 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/models"
+	"strings"
+	"time"
+)
+
+// maxScheduleUsers is how many users one getSchedule request may ask for
+const maxScheduleUsers = 20
+
+// defaultScheduleInterval is the availability view interval used when none is given
+const defaultScheduleInterval = 30 * time.Minute
+
+// ScheduleBusyIntervals returns the periods that make a schedule's owner unavailable
+// Free and working-elsewhere items leave the time open, as they do for events
+func ScheduleBusyIntervals(schedule models.Schedule) []models.TimeSlot {
+	slots := make([]models.TimeSlot, 0, len(schedule.Items))
+	for _, item := range schedule.Items {
+		if !item.Status.BlocksTime() {
+			continue
+		}
+		slots = append(slots, models.TimeSlot{Start: item.Start, End: item.End})
+	}
+	return slots
+}
+
+// scheduleFromEvents builds the schedule getSchedule would report for a user's events, keeping
+// only their times and show-as statuses
+func scheduleFromEvents(userEmail string, events []models.Event, startTime, endTime time.Time, interval time.Duration) models.Schedule {
+	items := make([]models.ScheduleItem, 0, len(events))
+	for _, event := range events {
+		if !event.Start.Before(endTime) || !event.End.After(startTime) {
+			continue
+		}
+		status := event.ShowAs
+		if status == "" {
+			status = models.ShowAsBusy
+		}
+		items = append(items, models.ScheduleItem{Start: event.Start, End: event.End, Status: status})
+	}
+	return models.Schedule{
+		UserEmail:        userEmail,
+		AvailabilityView: availabilityView(items, startTime, endTime, scheduleInterval(interval)),
+		Items:            items,
+	}
+}
+
+// scheduleEvents turns a schedule's items into bare events, so schedules can be cached like calendars
+func scheduleEvents(schedule models.Schedule) []models.Event {
+	events := make([]models.Event, 0, len(schedule.Items))
+	for _, item := range schedule.Items {
+		events = append(events, models.Event{Start: item.Start, End: item.End, ShowAs: item.Status, Attendees: []string{}})
+	}
+	return events
+}
+
+// scheduleInterval clamps an availability view interval to what getSchedule accepts (5 to 1440 minutes)
+func scheduleInterval(interval time.Duration) time.Duration {
+	switch {
+	case interval <= 0:
+		return defaultScheduleInterval
+	case interval < 5*time.Minute:
+		return 5 * time.Minute
+	case interval > 24*time.Hour:
+		return 24 * time.Hour
+	}
+	return interval.Truncate(time.Minute)
+}
+
+// availabilityView encodes items the way getSchedule does: one digit per interval from start to
+// end, showing the most restrictive status of the items overlapping it
+func availabilityView(items []models.ScheduleItem, start, end time.Time, interval time.Duration) string {
+	var view strings.Builder
+	for slotStart := start; slotStart.Before(end); slotStart = slotStart.Add(interval) {
+		slotEnd := slotStart.Add(interval)
+		code := byte('0')
+		for _, item := range items {
+			if item.Start.Before(slotEnd) && item.End.After(slotStart) {
+				if next := availabilityCode(item.Status); availabilityRank(next) > availabilityRank(code) {
+					code = next
+				}
+			}
+		}
+		view.WriteByte(code)
+	}
+	return view.String()
+}
+
+// availabilityCode maps a status to its availability view digit; unknown counts as busy
+func availabilityCode(status models.ShowAs) byte {
+	switch status {
+	case models.ShowAsFree:
+		return '0'
+	case models.ShowAsTentative:
+		return '1'
+	case models.ShowAsOutOfOffice:
+		return '3'
+	case models.ShowAsWorkingElsewhere:
+		return '4'
+	default:
+		return '2'
+	}
+}
+
+// availabilityRank orders digits from free to away
+func availabilityRank(code byte) int {
+	return strings.IndexByte("04123", code)
+}
+
+// standardWorkingHours is the 9am-6pm weekday schedule assumed for users without mailbox settings
+func standardWorkingHours(loc *time.Location) *models.WorkingHours {
+	return &models.WorkingHours{
+		DaysOfWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+		StartTime:  "09:00",
+		EndTime:    "18:00",
+		TimeZone:   loc.String(),
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"Smart-Meeting-Scheduler/config"
+	"Smart-Meeting-Scheduler/models"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	graphmodels "github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// Graph lets subscriptions to events live for at most 4230 minutes
+const (
+	subscriptionLifetime    = 70 * time.Hour
+	subscriptionRenewBefore = 24 * time.Hour
+)
+
+// SubscriptionManager keeps a Graph change-notification subscription to the events of every
+// calendar the sync worker tracks, so changes reach the event cache within seconds
+type SubscriptionManager struct {
+	Store           *models.SubscriptionStore
+	Cache           *models.EventCacheStore
+	Config          *config.Config // Supplies the application token
+	NotificationURL string         // Public URL of /webhooks/graph
+	Interval        time.Duration  // How often subscriptions are created and renewed
+}
+
+// NewSubscriptionManagerFromEnv creates a subscription manager from environment variables
+// Returns nil when GRAPH_WEBHOOK_URL is not set
+// - GRAPH_WEBHOOK_URL: public HTTPS URL of /webhooks/graph that Graph can reach
+// - GRAPH_SUBSCRIPTION_CHECK_INTERVAL: Go duration between renewal checks (default 1h)
+// Notifications refresh the event cache, so the calendar sync must be enabled too
+func NewSubscriptionManagerFromEnv(cfg *config.Config) (*SubscriptionManager, error) {
+	notificationURL := os.Getenv("GRAPH_WEBHOOK_URL")
+	if notificationURL == "" {
+		return nil, nil
+	}
+	if _, enabled, err := CalendarSyncSettingsFromEnv(); err != nil || !enabled {
+		return nil, fmt.Errorf("change notifications need the calendar sync (CALENDAR_SYNC_INTERVAL)")
+	}
+	if os.Getenv("GRAPH_MODE") != "real" {
+		return nil, fmt.Errorf("change notifications need GRAPH_MODE=real")
+	}
+	if cfg.DB == nil {
+		return nil, fmt.Errorf("change notifications need a database")
+	}
+
+	interval, err := time.ParseDuration(getEnvOrDefault("GRAPH_SUBSCRIPTION_CHECK_INTERVAL", "1h"))
+	if err != nil || interval <= 0 || interval >= subscriptionRenewBefore {
+		return nil, fmt.Errorf("invalid GRAPH_SUBSCRIPTION_CHECK_INTERVAL: %s", os.Getenv("GRAPH_SUBSCRIPTION_CHECK_INTERVAL"))
+	}
+
+	return &SubscriptionManager{
+		Store:           models.NewSubscriptionStore(cfg.DB),
+		Cache:           models.NewEventCacheStore(cfg.DB),
+		Config:          cfg,
+		NotificationURL: notificationURL,
+		Interval:        interval,
+	}, nil
+}
+
+// Run maintains subscriptions every Interval until ctx is canceled
+func (m *SubscriptionManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.EnsureOnce(ctx); err != nil {
+			log.Printf("Warning: Subscription maintenance failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureOnce subscribes to every tracked calendar that has no subscription, renews those
+// expiring within a day and removes those of calendars no longer tracked
+// A failure on one calendar is logged and does not stop the others
+func (m *SubscriptionManager) EnsureOnce(ctx context.Context) error {
+	users, err := m.Cache.TrackedUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tracked calendars: %v", err)
+	}
+	subs, err := m.Store.ListSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+	if len(users) == 0 && len(subs) == 0 {
+		return nil
+	}
+
+	token, err := m.Config.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get application token: %v", err)
+	}
+	client := InitializeGraphClient(token)
+
+	tracked := map[string]bool{}
+	for _, email := range users {
+		tracked[strings.ToLower(email)] = true
+	}
+	subscribed := map[string]bool{}
+	renewBy := time.Now().Add(subscriptionRenewBefore)
+	for _, sub := range subs {
+		switch {
+		case !tracked[sub.UserEmail] || subscribed[sub.UserEmail]:
+			m.remove(ctx, client, sub)
+		case sub.ExpiresAt.Before(renewBy):
+			if err := m.renew(ctx, client, sub); err != nil {
+				log.Printf("Warning: Failed to renew subscription for %s: %v", sub.UserEmail, err)
+				continue
+			}
+			subscribed[sub.UserEmail] = true
+		default:
+			subscribed[sub.UserEmail] = true
+		}
+	}
+
+	for _, email := range users {
+		if subscribed[strings.ToLower(email)] {
+			continue
+		}
+		if err := m.subscribe(ctx, client, email); err != nil {
+			log.Printf("Warning: Failed to subscribe to calendar of %s: %v", email, err)
+		}
+	}
+	return nil
+}
+
+// subscribe creates a subscription to userEmail's events
+// Graph calls the notification URL with a validationToken before it answers
+func (m *SubscriptionManager) subscribe(ctx context.Context, client *msgraphsdk.GraphServiceClient, userEmail string) error {
+	resource := fmt.Sprintf("users/%s/events", userEmail)
+	changeType := "created,updated,deleted"
+	clientState := models.NewSubscriptionClientState()
+	expiresAt := time.Now().Add(subscriptionLifetime).UTC()
+
+	body := graphmodels.NewSubscription()
+	body.SetResource(&resource)
+	body.SetChangeType(&changeType)
+	body.SetNotificationUrl(&m.NotificationURL)
+	body.SetLifecycleNotificationUrl(&m.NotificationURL)
+	body.SetClientState(&clientState)
+	body.SetExpirationDateTime(&expiresAt)
+
+	created, err := client.Subscriptions().Post(ctx, body, nil)
+	if err != nil {
+		return err
+	}
+	if created.GetId() == nil {
+		return fmt.Errorf("subscription has no id")
+	}
+	if created.GetExpirationDateTime() != nil {
+		expiresAt = *created.GetExpirationDateTime()
+	}
+
+	return m.Store.SaveSubscription(ctx, models.GraphSubscription{
+		ID:          *created.GetId(),
+		UserEmail:   userEmail,
+		Resource:    resource,
+		ClientState: clientState,
+		ExpiresAt:   expiresAt,
+	})
+}
+
+// renew extends a subscription; one Graph no longer has is replaced
+func (m *SubscriptionManager) renew(ctx context.Context, client *msgraphsdk.GraphServiceClient, sub models.GraphSubscription) error {
+	expiresAt := time.Now().Add(subscriptionLifetime).UTC()
+	body := graphmodels.NewSubscription()
+	body.SetExpirationDateTime(&expiresAt)
+
+	renewed, err := client.Subscriptions().BySubscriptionId(sub.ID).Patch(ctx, body, nil)
+	if err != nil {
+		var odataErr *odataerrors.ODataError
+		if !errors.As(err, &odataErr) || odataErr.GetStatusCode() != http.StatusNotFound {
+			return err
+		}
+		if err := m.Store.DeleteSubscription(ctx, sub.ID); err != nil {
+			return err
+		}
+		return m.subscribe(ctx, client, sub.UserEmail)
+	}
+	if renewed.GetExpirationDateTime() != nil {
+		expiresAt = *renewed.GetExpirationDateTime()
+	}
+	return m.Store.UpdateExpiry(ctx, sub.ID, expiresAt)
+}
+
+// ReauthorizeSubscription renews a subscription Graph asked to reauthorize with a
+// reauthorizationRequired lifecycle notification; renewing it reauthorizes it too
+func ReauthorizeSubscription(ctx context.Context, cfg *config.Config, sub models.GraphSubscription) error {
+	token, err := cfg.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get application token: %v", err)
+	}
+	m := &SubscriptionManager{
+		Store:           models.NewSubscriptionStore(cfg.DB),
+		Cache:           models.NewEventCacheStore(cfg.DB),
+		Config:          cfg,
+		NotificationURL: os.Getenv("GRAPH_WEBHOOK_URL"),
+	}
+	return m.renew(ctx, InitializeGraphClient(token), sub)
+}
+
+// remove deletes a subscription at Graph and forgets it
+func (m *SubscriptionManager) remove(ctx context.Context, client *msgraphsdk.GraphServiceClient, sub models.GraphSubscription) {
+	err := client.Subscriptions().BySubscriptionId(sub.ID).Delete(ctx, nil)
+	var odataErr *odataerrors.ODataError
+	if err != nil && (!errors.As(err, &odataErr) || odataErr.GetStatusCode() != http.StatusNotFound) {
+		log.Printf("Warning: Failed to delete subscription %s: %v", sub.ID, err)
+		return
+	}
+	if err := m.Store.DeleteSubscription(ctx, sub.ID); err != nil {
+		log.Printf("Warning: Failed to forget subscription %s: %v", sub.ID, err)
+	}
+}
+
+// ValidClientState reports whether a notification's clientState matches its subscription's secret
+func ValidClientState(expected, received string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
+}
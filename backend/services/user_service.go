@@ -16,15 +16,18 @@ import (
 )
 
 // UserService defines the interface for user operations
+// Every method stops its work once ctx is cancelled or past its deadline
 type UserService interface {
 	// GetAllUsers retrieves all users
-	GetAllUsers() ([]models.MSUser, error)
+	// The flag reports that the directory held more users than were returned
+	GetAllUsers(ctx context.Context) ([]models.MSUser, bool, error)
 
 	// GetUserByID retrieves a user by their ID
-	GetUserByID(userID string) (*models.MSUser, error)
+	GetUserByID(ctx context.Context, userID string) (*models.MSUser, error)
 
 	// SearchUsers searches for users by query string
-	SearchUsers(query string) ([]models.MSUser, error)
+	// The flag reports that more users matched than were returned
+	SearchUsers(ctx context.Context, query string) ([]models.MSUser, bool, error)
 }
 
 // GraphUserService implements UserService using Microsoft Graph SDK
@@ -49,24 +52,35 @@ func NewGraphUserService(accessToken string) (*GraphUserService, error) {
 }
 
 // GetAllUsers retrieves all users from Microsoft Graph
-func (s *GraphUserService) GetAllUsers() ([]models.MSUser, error) {
-	users, err := s.graphClient.Users().Get(context.Background(), nil)
+func (s *GraphUserService) GetAllUsers(ctx context.Context) ([]models.MSUser, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	paging := GraphPagingFromEnv()
+	configuration := &graphusers.UsersRequestBuilderGetRequestConfiguration{
+		QueryParameters: &graphusers.UsersRequestBuilderGetQueryParameters{
+			Top: &paging.PageSize,
+		},
+	}
+
+	users, truncated, err := s.readUsers(ctx, configuration, paging)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users from Graph: %v", err)
+		return nil, false, fmt.Errorf("failed to get users from Graph: %v", err)
 	}
 
 	var msUsers []models.MSUser
-	for _, user := range users.GetValue() {
+	for _, user := range users {
 		msUser := convertGraphUserToMSUser(user)
 		msUsers = append(msUsers, msUser)
 	}
 
-	return msUsers, nil
+	return msUsers, truncated, nil
 }
 
 // GetUserByID retrieves a user by their ID from Microsoft Graph
-func (s *GraphUserService) GetUserByID(userID string) (*models.MSUser, error) {
-	user, err := s.graphClient.Users().ByUserId(userID).Get(context.Background(), nil)
+func (s *GraphUserService) GetUserByID(ctx context.Context, userID string) (*models.MSUser, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
+	user, err := s.graphClient.Users().ByUserId(userID).Get(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user from Graph: %v", err)
 	}
@@ -76,7 +90,9 @@ func (s *GraphUserService) GetUserByID(userID string) (*models.MSUser, error) {
 }
 
 // SearchUsers searches for users using Microsoft Graph search API
-func (s *GraphUserService) SearchUsers(query string) ([]models.MSUser, error) {
+func (s *GraphUserService) SearchUsers(ctx context.Context, query string) ([]models.MSUser, bool, error) {
+	ctx, cancel := graphCallContext(ctx)
+	defer cancel()
 	// Format search query: "displayName:query" - Graph API expects quoted format
 	searchQuery := fmt.Sprintf("\"displayName:%s\"", query)
 
@@ -84,10 +100,12 @@ func (s *GraphUserService) SearchUsers(query string) ([]models.MSUser, error) {
 	headers.Add("ConsistencyLevel", "eventual")
 
 	requestCount := true
+	paging := GraphPagingFromEnv()
 	requestParameters := &graphusers.UsersRequestBuilderGetQueryParameters{
 		Search:  &searchQuery,
 		Orderby: []string{"displayName"},
 		Count:   &requestCount,
+		Top:     &paging.PageSize,
 	}
 
 	configuration := &graphusers.UsersRequestBuilderGetRequestConfiguration{
@@ -95,18 +113,35 @@ func (s *GraphUserService) SearchUsers(query string) ([]models.MSUser, error) {
 		QueryParameters: requestParameters,
 	}
 
-	users, err := s.graphClient.Users().Get(context.Background(), configuration)
+	users, truncated, err := s.readUsers(ctx, configuration, paging)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search users from Graph: %v", err)
+		return nil, false, fmt.Errorf("failed to search users from Graph: %v", err)
 	}
 
 	var msUsers []models.MSUser
-	for _, user := range users.GetValue() {
+	for _, user := range users {
 		msUser := convertGraphUserToMSUser(user)
 		msUsers = append(msUsers, msUser)
 	}
 
-	return msUsers, nil
+	return msUsers, truncated, nil
+}
+
+// readUsers lists users following @odata.nextLink until paging.MaxItems users
+// configuration's headers are sent with every page
+func (s *GraphUserService) readUsers(ctx context.Context, configuration *graphusers.UsersRequestBuilderGetRequestConfiguration, paging GraphPaging) ([]graphmodels.Userable, bool, error) {
+	builder := s.graphClient.Users()
+	first, err := builder.Get(ctx, configuration)
+	if err != nil {
+		return nil, false, err
+	}
+
+	next := func(nextLink string) (graphmodels.UserCollectionResponseable, error) {
+		return builder.WithUrl(nextLink).Get(ctx, &graphusers.UsersRequestBuilderGetRequestConfiguration{
+			Headers: configuration.Headers,
+		})
+	}
+	return CollectGraphPages[graphmodels.Userable](first, next, paging.MaxItems)
 }
 
 // convertGraphUserToMSUser converts a Graph API user to MSUser model
@@ -146,18 +181,20 @@ func NewDatabaseUserService(userStore *models.UserStore) *DatabaseUserService {
 }
 
 // GetAllUsers retrieves all users from the database
-func (s *DatabaseUserService) GetAllUsers() ([]models.MSUser, error) {
-	return s.userStore.GetAllUsers()
+func (s *DatabaseUserService) GetAllUsers(ctx context.Context) ([]models.MSUser, bool, error) {
+	users, err := s.userStore.GetAllUsers(ctx)
+	return users, false, err
 }
 
 // GetUserByID retrieves a user by their ID from the database
-func (s *DatabaseUserService) GetUserByID(userID string) (*models.MSUser, error) {
-	return s.userStore.GetUserByID(userID)
+func (s *DatabaseUserService) GetUserByID(ctx context.Context, userID string) (*models.MSUser, error) {
+	return s.userStore.GetUserByID(ctx, userID)
 }
 
 // SearchUsers searches for users in the database
-func (s *DatabaseUserService) SearchUsers(query string) ([]models.MSUser, error) {
-	return s.userStore.SearchUsers(query)
+func (s *DatabaseUserService) SearchUsers(ctx context.Context, query string) ([]models.MSUser, bool, error) {
+	users, err := s.userStore.SearchUsers(ctx, query)
+	return users, false, err
 }
 
 // CreateUserService creates the appropriate UserService based on GRAPH_MODE
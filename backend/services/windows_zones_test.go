@@ -0,0 +1,75 @@
+package services
+
+import "testing"
+
+func TestWindowsToIANA(t *testing.T) {
+	tests := []struct {
+		windows string
+		want    string
+		wantOK  bool
+	}{
+		{"Pacific Standard Time", "America/Los_Angeles", true},
+		{"india standard time", "Asia/Kolkata", true}, // CLDR maps to the legacy Asia/Calcutta
+		{" Eastern Standard Time ", "America/New_York", true},
+		{"UTC", "UTC", true},
+		{"Mars Standard Time", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.windows, func(t *testing.T) {
+			got, ok := WindowsToIANA(tt.windows)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("WindowsToIANA(%q) = %q, %v, want %q, %v", tt.windows, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIANAToWindows(t *testing.T) {
+	tests := []struct {
+		iana   string
+		want   string
+		wantOK bool
+	}{
+		{"Asia/Kolkata", "India Standard Time", true},
+		{"asia/calcutta", "India Standard Time", true},
+		{"Europe/Berlin", "W. Europe Standard Time", true},
+		{"Etc/UTC", "UTC", true},
+		{"Nowhere/City", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.iana, func(t *testing.T) {
+			got, ok := IANAToWindows(tt.iana)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("IANAToWindows(%q) = %q, %v, want %q, %v", tt.iana, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeTimeZone(t *testing.T) {
+	tests := []struct {
+		name    string
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{name: "windows id", zone: "W. Europe Standard Time", want: "Europe/Berlin"},
+		{name: "legacy iana name", zone: "Asia/Calcutta", want: "Asia/Kolkata"},
+		{name: "case differs", zone: "europe/london", want: "Europe/London"},
+		{name: "iana name without windows id", zone: "America/Argentina/Buenos_Aires", want: "America/Argentina/Buenos_Aires"},
+		{name: "utc", zone: "UTC", want: "UTC"},
+		{name: "empty", zone: "  ", wantErr: true},
+		{name: "unknown", zone: "Not/AZone", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTimeZone(tt.zone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeTimeZone(%q) error = %v, wantErr %v", tt.zone, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeTimeZone(%q) = %q, want %q", tt.zone, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Services whose requests are retried; each keeps its own retry policy and counters
+const (
+	RetryServiceGraph  = "graph"
+	RetryServiceGoogle = "google"
+	RetryServiceCalDAV = "caldav"
+)
+
+// RetryPolicy controls how requests to one service are retried after throttling and transient errors
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt
+	BaseDelay  time.Duration // Backoff before the first retry, doubled for each further one
+	MaxDelay   time.Duration // Longest single backoff
+	MaxWait    time.Duration // Total time one request may spend waiting between attempts
+
+	// RetryWrites also retries POST and PATCH answered with 503 or 504. Graph rejects throttled
+	// writes before applying them; other services may have applied a write that timed out,
+	// so there POST and PATCH are only retried after 429
+	RetryWrites bool
+}
+
+// GraphRetryPolicyFromEnv reads the retry policy of Graph requests
+// - GRAPH_MAX_RETRIES: retries per request (default 3, 0 disables retrying)
+// - GRAPH_RETRY_BASE_DELAY: Go duration of the first backoff (default 500ms)
+// - GRAPH_RETRY_MAX_WAIT: Go duration a request may spend waiting in total (default 30s)
+func GraphRetryPolicyFromEnv() RetryPolicy {
+	policy := RetryPolicyFromEnv(RetryServiceGraph)
+	policy.RetryWrites = true
+	return policy
+}
+
+// RetryPolicyFromEnv reads the retry policy of service from <SERVICE>_MAX_RETRIES,
+// <SERVICE>_RETRY_BASE_DELAY and <SERVICE>_RETRY_MAX_WAIT, with the defaults of Graph
+func RetryPolicyFromEnv(service string) RetryPolicy {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second, MaxWait: 30 * time.Second}
+	prefix := strings.ToUpper(service)
+
+	if value := os.Getenv(prefix + "_MAX_RETRIES"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			log.Printf("Warning: Invalid %s_MAX_RETRIES %q, using %d", prefix, value, policy.MaxRetries)
+		} else {
+			policy.MaxRetries = n
+		}
+	}
+	for _, setting := range []struct {
+		key    string
+		target *time.Duration
+	}{
+		{prefix + "_RETRY_BASE_DELAY", &policy.BaseDelay},
+		{prefix + "_RETRY_MAX_WAIT", &policy.MaxWait},
+	} {
+		if value := os.Getenv(setting.key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				log.Printf("Warning: Invalid %s %q, using %s", setting.key, value, *setting.target)
+			} else {
+				*setting.target = d
+			}
+		}
+	}
+	if policy.MaxDelay > policy.MaxWait {
+		policy.MaxDelay = policy.MaxWait
+	}
+	return policy
+}
+
+// RetryTransport retries requests answered with 429, 503 or 504
+// A Retry-After header sets the wait; otherwise the wait is a jittered exponential backoff.
+// A request stops retrying once it would exceed MaxRetries or wait longer than MaxWait in total.
+// Request bodies without GetBody, as kiota sends them, are buffered so writes can be retried too
+type RetryTransport struct {
+	Base    http.RoundTripper
+	Policy  RetryPolicy
+	Service string // Names the service in logs and selects the counters retries are recorded in
+}
+
+// NewGraphRetryTransport wraps base, or http.DefaultTransport when it is nil, with the Graph policy from the environment
+func NewGraphRetryTransport(base http.RoundTripper) *RetryTransport {
+	return newRetryTransport(base, RetryServiceGraph, GraphRetryPolicyFromEnv())
+}
+
+// NewRetryTransport wraps base, or http.DefaultTransport when it is nil, with service's policy from the environment
+// Retries are counted under service, apart from those of Graph
+func NewRetryTransport(base http.RoundTripper, service string) *RetryTransport {
+	return newRetryTransport(base, service, RetryPolicyFromEnv(service))
+}
+
+func newRetryTransport(base http.RoundTripper, service string, policy RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{Base: base, Policy: policy, Service: service}
+}
+
+// NewGraphHTTPClient returns an http.Client for raw Graph calls that retries throttled requests
+func NewGraphHTTPClient() *http.Client {
+	return &http.Client{Transport: NewGraphRetryTransport(nil)}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	metrics := retryMetricsFor(t.Service)
+	metrics.requests.Add(1)
+	waited := time.Duration(0)
+
+	if t.Policy.MaxRetries > 0 && req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		buffered, err := bufferBody(req)
+		if err != nil {
+			return nil, err
+		}
+		req = buffered
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil || !t.retryable(req.Method, resp.StatusCode) {
+			return resp, err
+		}
+
+		delay, ok := t.nextDelay(resp, attempt, waited)
+		if !ok {
+			if t.Policy.MaxRetries > 0 {
+				metrics.exhausted.Add(1)
+			}
+			return resp, nil
+		}
+
+		// The body of a retried request is read again from GetBody
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			retry := req.Clone(req.Context())
+			retry.Body = body
+			req = retry
+		}
+		resp.Body.Close()
+
+		metrics.record(resp.StatusCode)
+		log.Printf("%s returned %d for %s %s, retrying in %s", t.Service, resp.StatusCode, req.Method, req.URL.Path, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		waited += delay
+	}
+}
+
+// bufferBody returns a copy of req whose body is read into memory and can be replayed with GetBody
+func bufferBody(req *http.Request) (*http.Request, error) {
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := req.Clone(req.Context())
+	buffered.Body = io.NopCloser(bytes.NewReader(data))
+	buffered.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	buffered.ContentLength = int64(len(data))
+	return buffered, nil
+}
+
+// nextDelay returns how long to wait before retrying after resp, or false when the retry budget is spent
+func (t *RetryTransport) nextDelay(resp *http.Response, attempt int, waited time.Duration) (time.Duration, bool) {
+	if attempt >= t.Policy.MaxRetries {
+		return 0, false
+	}
+
+	delay, ok := retryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		backoff := t.Policy.BaseDelay << attempt
+		if backoff <= 0 || backoff > t.Policy.MaxDelay {
+			backoff = t.Policy.MaxDelay
+		}
+		// Jitter spreads out clients that were throttled together
+		delay = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+	if waited+delay > t.Policy.MaxWait {
+		return 0, false
+	}
+	return delay, true
+}
+
+// retryable reports whether the service may answer a retry of the request differently
+// A 429 means the request was not processed; after 503 or 504 a write may already have been applied
+func (t *RetryTransport) retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.Policy.RetryWrites || (method != http.MethodPost && method != http.MethodPatch)
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(at); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// RetryStats counts the requests to one service and their retries since the process started
+type RetryStats struct {
+	Requests        int64            `json:"requests"`        // Requests sent through a retrying client
+	Retries         int64            `json:"retries"`         // Retries of those requests
+	RetriesByStatus map[string]int64 `json:"retriesByStatus"` // Retries keyed by the status that caused them
+	Exhausted       int64            `json:"exhausted"`       // Requests that still failed when the retry budget ran out
+}
+
+// retryMetricsByService holds the counters of each service, created on first use
+var retryMetricsByService sync.Map
+
+func retryMetricsFor(service string) *retryMetrics {
+	if metrics, ok := retryMetricsByService.Load(service); ok {
+		return metrics.(*retryMetrics)
+	}
+	metrics, _ := retryMetricsByService.LoadOrStore(service, &retryMetrics{byStatus: map[int]int64{}})
+	return metrics.(*retryMetrics)
+}
+
+type retryMetrics struct {
+	requests  atomic.Int64
+	retries   atomic.Int64
+	exhausted atomic.Int64
+
+	mu       sync.Mutex
+	byStatus map[int]int64
+}
+
+func (m *retryMetrics) record(status int) {
+	m.retries.Add(1)
+	m.mu.Lock()
+	m.byStatus[status]++
+	m.mu.Unlock()
+}
+
+// GraphRetryMetrics returns a snapshot of the retry counters of Graph requests
+func GraphRetryMetrics() RetryStats {
+	return RetryMetrics(RetryServiceGraph)
+}
+
+// RetryMetrics returns a snapshot of the retry counters of service
+func RetryMetrics(service string) RetryStats {
+	metrics := retryMetricsFor(service)
+	stats := RetryStats{
+		Requests:        metrics.requests.Load(),
+		Retries:         metrics.retries.Load(),
+		Exhausted:       metrics.exhausted.Load(),
+		RetriesByStatus: map[string]int64{},
+	}
+	metrics.mu.Lock()
+	for status, count := range metrics.byStatus {
+		stats.RetriesByStatus[strconv.Itoa(status)] = count
+	}
+	metrics.mu.Unlock()
+	return stats
+}
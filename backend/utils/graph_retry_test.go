@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "3", 3 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-1", 0, false},
+		{"past date", "Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.value)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("retryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNextDelay(t *testing.T) {
+	transport := &RetryTransport{Policy: RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, MaxWait: 5 * time.Second}}
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		waited     time.Duration
+		min, max   time.Duration
+		ok         bool
+	}{
+		{"first backoff", "", 0, 0, 50 * time.Millisecond, 100 * time.Millisecond, true},
+		{"doubled backoff", "", 2, 0, 200 * time.Millisecond, 400 * time.Millisecond, true},
+		{"retry-after wins", "2", 0, 0, 2 * time.Second, 2 * time.Second, true},
+		{"retries spent", "", 3, 0, 0, 0, false},
+		{"wait budget spent", "2", 1, 4 * time.Second, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			got, ok := transport.nextDelay(resp, tt.attempt, tt.waited)
+			if ok != tt.ok || got < tt.min || got > tt.max {
+				t.Errorf("nextDelay() = %s, %v, want %s..%s, %v", got, ok, tt.min, tt.max, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryTransportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  string
+		method   string
+		body     string
+		statuses []int
+		want     int
+		calls    int
+	}{
+		{"success", RetryServiceGraph, http.MethodGet, "", []int{200}, 200, 1},
+		{"throttled get", RetryServiceGraph, http.MethodGet, "", []int{429, 503, 200}, 200, 3},
+		{"throttled post", RetryServiceGraph, http.MethodPost, `{"subject":"Sync"}`, []int{429, 200}, 200, 2},
+		{"throttled patch", RetryServiceGraph, http.MethodPatch, `{"subject":"Moved"}`, []int{504, 200}, 200, 2},
+		{"not retryable", RetryServiceGraph, http.MethodPost, `{}`, []int{400, 200}, 400, 1},
+		{"exhausted", RetryServiceGraph, http.MethodGet, "", []int{429, 429, 429}, 429, 3},
+		{"provider throttled get", RetryServiceGoogle, http.MethodGet, "", []int{503, 200}, 200, 2},
+		{"provider throttled post", RetryServiceGoogle, http.MethodPost, `{"summary":"Sync"}`, []int{429, 200}, 200, 2},
+		{"provider patch timed out", RetryServiceCalDAV, http.MethodPatch, `{}`, []int{504, 200}, 504, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.body {
+					t.Errorf("attempt %d sent body %q, want %q", calls+1, body, tt.body)
+				}
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			transport := &RetryTransport{
+				Base:    http.DefaultTransport,
+				Policy:  RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxWait: time.Second, RetryWrites: tt.service == RetryServiceGraph},
+				Service: tt.service,
+			}
+			before := map[string]int64{}
+			for _, service := range []string{RetryServiceGraph, RetryServiceGoogle, RetryServiceCalDAV} {
+				before[service] = RetryMetrics(service).Retries
+			}
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body != "" {
+				// Like kiota, set a body without GetBody
+				req.Body = io.NopCloser(strings.NewReader(tt.body))
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want || calls != tt.calls {
+				t.Errorf("RoundTrip() = %d after %d calls, want %d after %d", resp.StatusCode, calls, tt.want, tt.calls)
+			}
+			// Retries are counted under the transport's own service only
+			for service, count := range before {
+				want := count
+				if service == tt.service {
+					want += int64(tt.calls - 1)
+				}
+				if got := RetryMetrics(service).Retries; got != want {
+					t.Errorf("%s retries = %d, want %d", service, got, want)
+				}
+			}
+		})
+	}
+}